@@ -212,6 +212,9 @@ func Open(info *Info, opts DialOpts) (Connection, error) {
 	}
 	ctx := context.Background()
 	dialCtx := ctx
+	if opts.Context != nil {
+		dialCtx = opts.Context
+	}
 	if opts.Timeout > 0 {
 		ctx1, cancel := utils.ContextWithTimeout(dialCtx, opts.Clock, opts.Timeout)
 		defer cancel()
@@ -621,6 +624,18 @@ type dialOpts struct {
 	// certPool holds a cert pool containing the CACert
 	// if there is one.
 	certPool *x509.CertPool
+	// clientCert holds the client certificate to present
+	// to the server, if there is one.
+	clientCert *tls.Certificate
+}
+
+// dialAddressInterval returns the time to wait before dialing the next
+// address, jittered if configured.
+func (opts dialOpts) dialAddressInterval() time.Duration {
+	if opts.Jitter <= 0 || opts.DialAddressInterval <= 0 {
+		return opts.DialAddressInterval
+	}
+	return jitterDelay(opts.DialAddressInterval, opts.Jitter, opts.JitterRand())
 }
 
 // dialAPI establishes a websocket connection to the RPC
@@ -644,6 +659,13 @@ func dialAPI(ctx context.Context, info *Info, opts0 DialOpts) (*dialResult, erro
 		}
 		opts.certPool = certPool
 	}
+	if info.ClientCert != "" {
+		clientCert, err := tls.X509KeyPair([]byte(info.ClientCert), []byte(info.ClientKey))
+		if err != nil {
+			return nil, errors.Annotate(err, "client certificate parsing failed")
+		}
+		opts.clientCert = &clientCert
+	}
 	// Set opts.DialWebsocket and opts.Clock here rather than in open because
 	// some tests call dialAPI directly.
 	if opts.DialWebsocket == nil {
@@ -658,6 +680,9 @@ func dialAPI(ctx context.Context, info *Info, opts0 DialOpts) (*dialResult, erro
 	if opts.DNSCache == nil {
 		opts.DNSCache = nopDNSCache{}
 	}
+	if opts.JitterRand == nil {
+		opts.JitterRand = rand.Float64
+	}
 	path, err := apiPath(info.ModelTag.Id(), "/api")
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -894,7 +919,7 @@ func verifyCAMulti(ctx context.Context, addrs []string, opts *dialOpts) error {
 		}
 
 		select {
-		case <-opts.Clock.After(dOpts.DialAddressInterval):
+		case <-opts.Clock.After(opts.dialAddressInterval()):
 		case <-try.Dead():
 		}
 	}
@@ -982,8 +1007,9 @@ func dialWebsocketMulti(ctx context.Context, addrs []string, path string, opts d
 		}
 		return other
 	}
-	// Dial all addresses at reasonable intervals.
-	try := parallel.NewTry(0, combine)
+	// Dial all addresses at reasonable intervals, with at most
+	// MaxParallelDials attempts in progress at once.
+	try := parallel.NewTry(opts.MaxParallelDials, combine)
 	defer try.Kill()
 	// Make a context that's cancelled when the try
 	// completes so that (for example) a slow DNS
@@ -1017,7 +1043,7 @@ func dialWebsocketMulti(ctx context.Context, addrs []string, path string, opts d
 			return nil, errors.Trace(err)
 		}
 		select {
-		case <-opts.Clock.After(opts.DialAddressInterval):
+		case <-opts.Clock.After(opts.dialAddressInterval()):
 		case <-try.Dead():
 		}
 	}
@@ -1061,23 +1087,37 @@ var oneAttempt = retry.LimitCount(1, retry.Regular{
 	Min: 1,
 })
 
+// openAttempt returns the strategy for retrying the connection to a
+// single address.
+func (opts dialOpts) openAttempt() retry.Strategy {
+	if opts.RetryDelay <= 0 {
+		// Zero retry delay implies exactly one try.
+		return oneAttempt
+	}
+	var openAttempt retry.Strategy = retry.Regular{
+		Total: opts.Timeout,
+		Delay: opts.RetryDelay,
+		Min:   int(opts.Timeout / opts.RetryDelay),
+	}
+	if opts.Jitter > 0 {
+		openAttempt = jitteredStrategy{
+			Strategy: openAttempt,
+			jitter:   opts.Jitter,
+			rand:     opts.JitterRand,
+		}
+	}
+	if opts.MaxAttempts > 0 {
+		openAttempt = retry.LimitCount(opts.MaxAttempts, openAttempt)
+	}
+	return openAttempt
+}
+
 // startDialWebsocket starts websocket connection to a single address
 // on the given try instance.
 func startDialWebsocket(ctx context.Context, try *parallel.Try, ipAddr, addr, path string, opts dialOpts) error {
-	var openAttempt retry.Strategy
-	if opts.RetryDelay > 0 {
-		openAttempt = retry.Regular{
-			Total: opts.Timeout,
-			Delay: opts.RetryDelay,
-			Min:   int(opts.Timeout / opts.RetryDelay),
-		}
-	} else {
-		// Zero retry delay implies exactly one try.
-		openAttempt = oneAttempt
-	}
 	d := dialer{
 		ctx:         ctx,
-		openAttempt: openAttempt,
+		openAttempt: opts.openAttempt(),
 		serverName:  opts.sniHostName,
 		ipAddr:      ipAddr,
 		urlStr:      "wss://" + addr + path,
@@ -1087,6 +1127,45 @@ func startDialWebsocket(ctx context.Context, try *parallel.Try, ipAddr, addr, pa
 	return try.Start(d.dial)
 }
 
+// jitteredStrategy wraps a retry strategy, randomly varying each of its
+// delays by up to the jitter fraction of the delay.
+type jitteredStrategy struct {
+	retry.Strategy
+	jitter float64
+	rand   func() float64
+}
+
+// NewTimer is part of the retry.Strategy interface.
+func (s jitteredStrategy) NewTimer(now time.Time) retry.Timer {
+	return jitteredTimer{
+		Timer:    s.Strategy.NewTimer(now),
+		strategy: s,
+	}
+}
+
+type jitteredTimer struct {
+	retry.Timer
+	strategy jitteredStrategy
+}
+
+// NextSleep is part of the retry.Timer interface.
+func (t jitteredTimer) NextSleep(now time.Time) (time.Duration, bool) {
+	delay, ok := t.Timer.NextSleep(now)
+	if !ok || delay <= 0 {
+		return delay, ok
+	}
+	return jitterDelay(delay, t.strategy.jitter, t.strategy.rand()), true
+}
+
+// jitterDelay returns the delay varied by up to the jitter fraction of it,
+// where r is a random value in [0, 1).
+func jitterDelay(delay time.Duration, jitter, r float64) time.Duration {
+	if jitter > 1 {
+		jitter = 1
+	}
+	return time.Duration(float64(delay) * (1 + jitter*(2*r-1)))
+}
+
 type dialer struct {
 	ctx         context.Context
 	openAttempt retry.Strategy
@@ -1143,6 +1222,9 @@ func (d dialer) dial(_ <-chan struct{}) (io.Closer, error) {
 func (d dialer) dial1() (jsoncodec.JSONConn, *tls.Config, error) {
 	tlsConfig := NewTLSConfig(d.opts.certPool)
 	tlsConfig.InsecureSkipVerify = d.opts.InsecureSkipVerify
+	if d.opts.clientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*d.opts.clientCert}
+	}
 	if d.opts.certPool == nil {
 		tlsConfig.ServerName = d.serverName
 	}
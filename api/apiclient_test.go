@@ -571,6 +571,85 @@ func (s *apiclientSuite) TestFailImmediatelyOnCertErrorAndNumericHostname(c *gc.
 	})
 }
 
+func (s *apiclientSuite) TestOpenWithClientCert(c *gc.C) {
+	clientCert, err := tls.X509KeyPair([]byte(jtesting.ServerCert), []byte(jtesting.ServerKey))
+	c.Assert(err, jc.ErrorIsNil)
+	s.testOpenDialError(c, dialTest{
+		apiInfo: &api.Info{
+			Addrs:      []string{"0.1.2.3:1234"},
+			CACert:     jtesting.CACert,
+			ClientCert: jtesting.ServerCert,
+			ClientKey:  jtesting.ServerKey,
+			SkipLogin:  true,
+		},
+		expectOpenError: `unable to connect to API: nope`,
+		expectDials: []dialAttempt{{
+			check: func(info dialInfo) {
+				c.Assert(info.tlsConfig, gc.NotNil)
+				c.Check(info.tlsConfig.RootCAs.Subjects(), gc.HasLen, 1)
+				c.Check(info.tlsConfig.ServerName, gc.Equals, "juju-apiserver")
+				c.Check(info.tlsConfig.Certificates, jc.DeepEquals, []tls.Certificate{clientCert})
+			},
+			returnError: errors.New("nope"),
+		}},
+		allowMoreDials: true,
+	})
+}
+
+func (s *apiclientSuite) TestOpenWithClientCertMissingKey(c *gc.C) {
+	_, err := api.Open(&api.Info{
+		Addrs:      []string{"0.1.2.3:1234"},
+		ClientCert: jtesting.ServerCert,
+		SkipLogin:  true,
+	}, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, `validating info for opening an API connection: specifying only one of ClientCert and ClientKey not valid`)
+}
+
+func (s *apiclientSuite) TestOpenLimitsParallelDials(c *gc.C) {
+	const maxParallelDials = 3
+	var (
+		mu                   sync.Mutex
+		dials, active, maxed int
+	)
+	fakeDialer := func(ctx context.Context, urlStr string, tlsConfig *tls.Config, ipAddr string) (jsoncodec.JSONConn, error) {
+		mu.Lock()
+		dials++
+		active++
+		if active > maxed {
+			maxed = active
+		}
+		mu.Unlock()
+
+		// Give the other attempts a chance to start.
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil, errors.New("nope")
+	}
+	var addrs []string
+	for i := 0; i < 10; i++ {
+		addrs = append(addrs, fmt.Sprintf("place%d.example:1234", i))
+	}
+	_, err := api.Open(&api.Info{
+		Addrs:     addrs,
+		SkipLogin: true,
+		CACert:    jtesting.CACert,
+	}, api.DialOpts{
+		DialWebsocket:    fakeDialer,
+		IPAddrResolver:   seqResolver(addrs...),
+		Clock:            &fakeClock{},
+		MaxParallelDials: maxParallelDials,
+	})
+	c.Assert(err, gc.ErrorMatches, `unable to connect to API: nope`)
+
+	mu.Lock()
+	defer mu.Unlock()
+	c.Check(dials, gc.Equals, len(addrs))
+	c.Check(maxed <= maxParallelDials, jc.IsTrue, gc.Commentf("%d concurrent dials", maxed))
+}
+
 type dialTest struct {
 	apiInfo *api.Info
 	// expectDials holds an entry for each dial
@@ -972,6 +1051,44 @@ func (s *apiclientSuite) TestOpenDialTimeoutAffectsDial(c *gc.C) {
 	}
 }
 
+func (s *apiclientSuite) TestOpenContextCancelAbortsDial(c *gc.C) {
+	sync := make(chan struct{})
+	fakeDialer := func(ctx context.Context, urlStr string, tlsConfig *tls.Config, ipAddr string) (jsoncodec.JSONConn, error) {
+		close(sync)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := api.Open(&api.Info{
+			Addrs:     []string{"127.0.0.1:1234"},
+			CACert:    jtesting.CACert,
+			ModelTag:  names.NewModelTag("beef1beef1-0000-0000-000011112222"),
+			SkipLogin: true,
+		}, api.DialOpts{
+			Clock:         testclock.NewClock(time.Now()),
+			Context:       ctx,
+			DialWebsocket: fakeDialer,
+		})
+		done <- err
+	}()
+	select {
+	case <-sync:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("didn't enter dial")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		c.Assert(err, gc.ErrorMatches, `unable to connect to API: context canceled`)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for api.Open to be cancelled")
+	}
+}
+
 func (s *apiclientSuite) TestOpenDialTimeoutDoesNotAffectLogin(c *gc.C) {
 	unblock := make(chan chan struct{})
 	srv := apiservertesting.NewAPIServer(func(modelUUID string) interface{} {
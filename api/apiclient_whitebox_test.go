@@ -13,6 +13,7 @@ import (
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/retry.v1"
 
 	jtesting "github.com/juju/juju/testing"
 )
@@ -79,3 +80,71 @@ func (s *apiclientWhiteboxSuite) TestDialWebsocketMultiClosed(c *gc.C) {
 	_, _, err = DialAPI(info, opts)
 	c.Check(err, gc.ErrorMatches, fmt.Sprintf("unable to connect to API: dial tcp %s:.*", regexp.QuoteMeta(addr)))
 }
+
+// fixedDelayStrategy is a retry strategy which always waits for the same
+// delay.
+type fixedDelayStrategy time.Duration
+
+func (s fixedDelayStrategy) NewTimer(now time.Time) retry.Timer {
+	return s
+}
+
+func (s fixedDelayStrategy) NextSleep(now time.Time) (time.Duration, bool) {
+	return time.Duration(s), true
+}
+
+func (s *apiclientWhiteboxSuite) TestJitteredStrategy(c *gc.C) {
+	for _, r := range []float64{0, 0.25, 0.5, 0.75, 0.999} {
+		strategy := jitteredStrategy{
+			Strategy: fixedDelayStrategy(time.Second),
+			jitter:   0.2,
+			rand:     func() float64 { return r },
+		}
+		delay, ok := strategy.NewTimer(time.Now()).NextSleep(time.Now())
+		c.Assert(ok, jc.IsTrue)
+		c.Check(delay >= 800*time.Millisecond, jc.IsTrue, gc.Commentf("delay %v for %v", delay, r))
+		c.Check(delay <= 1200*time.Millisecond, jc.IsTrue, gc.Commentf("delay %v for %v", delay, r))
+	}
+}
+
+func (s *apiclientWhiteboxSuite) TestJitterDelay(c *gc.C) {
+	c.Assert(jitterDelay(time.Second, 0.5, 0), gc.Equals, 500*time.Millisecond)
+	c.Assert(jitterDelay(time.Second, 0.5, 0.5), gc.Equals, time.Second)
+	c.Assert(jitterDelay(time.Second, 0.5, 0.75), gc.Equals, 1250*time.Millisecond)
+	// The jitter can't make the delay negative.
+	c.Assert(jitterDelay(time.Second, 2, 0), gc.Equals, time.Duration(0))
+}
+
+func (s *apiclientWhiteboxSuite) TestDialAddressIntervalJitter(c *gc.C) {
+	opts := dialOpts{DialOpts: DialOpts{DialAddressInterval: time.Second}}
+	c.Assert(opts.dialAddressInterval(), gc.Equals, time.Second)
+
+	opts.Jitter = 0.1
+	opts.JitterRand = func() float64 { return 0 }
+	c.Assert(opts.dialAddressInterval(), gc.Equals, 900*time.Millisecond)
+}
+
+func (s *apiclientWhiteboxSuite) TestOpenAttemptMaxAttempts(c *gc.C) {
+	attempts := func(opts DialOpts) int {
+		n := 0
+		for a := retry.Start(dialOpts{DialOpts: opts}.openAttempt(), nil); a.Next(); {
+			if n++; n > 10 {
+				break
+			}
+		}
+		return n
+	}
+	opts := DialOpts{
+		Timeout:    time.Minute,
+		RetryDelay: time.Millisecond,
+	}
+	// Without a limit, attempts continue until the timeout.
+	c.Assert(attempts(opts), gc.Equals, 11)
+
+	opts.MaxAttempts = 3
+	c.Assert(attempts(opts), gc.Equals, 3)
+
+	// Without a retry delay, there's only ever one attempt.
+	opts.RetryDelay = 0
+	c.Assert(attempts(opts), gc.Equals, 1)
+}
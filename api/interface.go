@@ -82,6 +82,13 @@ type Info struct {
 	// only by the machine agent.
 	Nonce string `yaml:",omitempty"`
 
+	// ClientCert and ClientKey optionally hold a PEM encoded client
+	// certificate and private key that are presented to the controller
+	// during the TLS handshake, for controllers that require mutual TLS
+	// authentication.
+	ClientCert string `yaml:",omitempty"`
+	ClientKey  string `yaml:",omitempty"`
+
 	// Proxier describes a proxier to use to for establing an API connection
 	// A nil proxier means that it will not be used.
 	Proxier proxy.Proxier
@@ -114,6 +121,10 @@ func (info *Info) Validate() error {
 		}
 	}
 
+	if (info.ClientCert == "") != (info.ClientKey == "") {
+		return errors.NotValidf("specifying only one of ClientCert and ClientKey")
+	}
+
 	if info.SkipLogin {
 		if info.Tag != nil {
 			return errors.NotValidf("specifying Tag and SkipLogin")
@@ -145,11 +156,37 @@ type DialOpts struct {
 	// zero, there is no timeout.
 	Timeout time.Duration
 
+	// Context, if non-nil, is the parent of the context used for the
+	// entire api.Open, so that cancelling it (for example when the user
+	// interrupts a command) promptly aborts an in-flight dial or login.
+	Context context.Context
+
 	// RetryDelay is the amount of time to wait between
 	// unsuccessful connection attempts. If this is
 	// zero, only one attempt will be made.
 	RetryDelay time.Duration
 
+	// MaxAttempts is the maximum number of connection attempts made
+	// to each address when RetryDelay is non-zero. If this is zero,
+	// attempts are made until Timeout expires.
+	MaxAttempts int
+
+	// Jitter is the fraction (between 0 and 1) of the retry delay and the
+	// dial address interval by which each delay is randomly lengthened or
+	// shortened, so that clients reconnecting at the same time spread out
+	// their attempts. If this is zero, the delays are fixed.
+	Jitter float64
+
+	// JitterRand returns the random values in [0, 1) used to jitter the
+	// delays. If it is nil, math/rand.Float64 will be used.
+	JitterRand func() float64
+
+	// MaxParallelDials is the maximum number of addresses that
+	// may be dialed concurrently. Remaining addresses are dialed
+	// as earlier attempts complete. If this is zero, there is
+	// no limit.
+	MaxParallelDials int
+
 	// BakeryClient is the httpbakery Client, which
 	// is used to do the macaroon-based authorization.
 	// This and the *http.Client inside it are copied
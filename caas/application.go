@@ -4,8 +4,13 @@
 package caas
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/juju/version/v2"
 
+	"github.com/juju/juju/caas/specs"
 	"github.com/juju/juju/core/constraints"
 	"github.com/juju/juju/core/devices"
 	"github.com/juju/juju/core/resources"
@@ -16,24 +21,70 @@ import (
 //go:generate go run github.com/golang/mock/mockgen -package mocks -destination mocks/application_mock.go github.com/juju/juju/caas Application
 
 // Application is for interacting with the CAAS substrate.
+//
+// The context passed to methods which call the substrate can be used to
+// cancel them or to apply a deadline.
 type Application interface {
-	Ensure(config ApplicationConfig) error
-	Exists() (DeploymentState, error)
-	Delete() error
+	Ensure(ctx context.Context, config ApplicationConfig) error
+	Exists(ctx context.Context) (DeploymentState, error)
+	// Delete removes the application from the substrate. Its storage is
+	// left in the substrate unless opts.DestroyStorage is true.
+	Delete(ctx context.Context, opts DeleteOptions) error
+
+	// ForceDelete deletes the application like Delete, then deletes any
+	// of its units which remain without waiting for them to terminate
+	// gracefully.
+	ForceDelete(ctx context.Context) error
+
 	Watch() (watcher.NotifyWatcher, error)
 	WatchReplicas() (watcher.NotifyWatcher, error)
 
+	// WatchDeletion returns a watcher which notifies when the application
+	// starts being removed from the substrate.
+	WatchDeletion() (watcher.NotifyWatcher, error)
+
+	// WatchUnits returns a watcher which notifies of the provider ids of
+	// the application's units as they're added and removed. The initial
+	// event holds the units which already exist.
+	WatchUnits(ctx context.Context) (watcher.StringsWatcher, error)
+
 	// Scale scales the Application's unit to the value specificied. Scale must
 	// be >= 0. Application units will be removed or added to meet the scale
 	// defined.
 	Scale(int) error
 
-	State() (ApplicationState, error)
-	Units() ([]Unit, error)
+	State(ctx context.Context) (ApplicationState, error)
+	Units(ctx context.Context) ([]Unit, error)
+
+	// UnitLogs streams the logs of a container of the unit with the given
+	// provider id. The caller must close the stream.
+	UnitLogs(ctx context.Context, unitID, containerName string, opts LogOptions) (io.ReadCloser, error)
 
 	ServiceInterface
 }
 
+// DeleteOptions describes how an application is removed from the
+// substrate.
+type DeleteOptions struct {
+	// DestroyStorage destroys the storage claimed by the application's
+	// workload, other than by a statefulset, whose claims are left to the
+	// statefulset's retention semantics. Otherwise the storage is released
+	// and left in the substrate.
+	DestroyStorage bool
+}
+
+// LogOptions describes the logs fetched for a unit's container.
+type LogOptions struct {
+	// TailLines, when non-zero, limits the logs to that many lines from
+	// the end.
+	TailLines int64
+	// Since, when non-zero, limits the logs to those written after it.
+	Since time.Time
+	// Previous fetches the logs of the previous instance of the container,
+	// e.g. one which crashed and was restarted.
+	Previous bool
+}
+
 // ServicePort represents service ports mapping from service to units.
 type ServicePort struct {
 	Name       string `json:"name"`
@@ -46,20 +97,35 @@ type ServicePort struct {
 type ServiceParam struct {
 	Type  string        `json:"type"`
 	Ports []ServicePort `json:"ports"`
+	// LoadBalancerSourceRanges restricts the client CIDRs allowed to access
+	// a LoadBalancer service. It's ignored for other service types.
+	LoadBalancerSourceRanges []string `json:"load-balancer-source-ranges,omitempty"`
+	// ExternalIPs are additional IP addresses, routed to the cluster nodes
+	// by the network, for which the service accepts traffic.
+	ExternalIPs []string `json:"external-ips,omitempty"`
+	// Labels and Annotations are added to the service, e.g. to configure
+	// a cloud load balancer. Keys managed by Juju are ignored.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // ServiceInterface provides the API to get/set service.
 type ServiceInterface interface {
 	// UpdateService updates the default service with specific service type and port mappings.
-	UpdateService(ServiceParam) error
+	UpdateService(ctx context.Context, param ServiceParam) error
 
-	UpdatePorts(ports []ServicePort, updateContainerPorts bool) error
+	UpdatePorts(ctx context.Context, ports []ServicePort, updateContainerPorts bool) error
 }
 
 // ApplicationState represents the application state.
 type ApplicationState struct {
 	DesiredReplicas int
 	Replicas        []string
+	// ReadyReplicas is the number of Replicas reporting as ready.
+	ReadyReplicas int
+	// Filesystems holds the provisioned filesystems mounted by the
+	// Replicas. Filesystems which are not yet bound are omitted.
+	Filesystems []FilesystemInfo
 }
 
 // ApplicationConfig is the config passed to the application units.
@@ -78,6 +144,11 @@ type ApplicationConfig struct {
 	// CharmBaseImage is the docker image used by the charm.
 	CharmBaseImage resources.DockerImageDetails
 
+	// CharmImagePullPolicy is the pull policy ("Always", "IfNotPresent" or
+	// "Never") for the charm and init container images. Defaults to
+	// "IfNotPresent" when empty.
+	CharmImagePullPolicy string
+
 	// Containers is the list of containers that make up the container (excluding uniter and init containers).
 	Containers map[string]ContainerConfig
 
@@ -100,8 +171,264 @@ type ApplicationConfig struct {
 	// Filesystems is a set of parameters for filesystems that should be created.
 	Filesystems []storage.KubernetesFilesystemParams
 
+	// FilesystemMountPathStrategy determines where filesystems without an
+	// attachment path are mounted: "indexed" (the default) includes the
+	// application name and filesystem index in the path, while "name" uses
+	// the storage name only.
+	FilesystemMountPathStrategy string
+
 	// Devices is a set of parameters for Devices that is required.
 	Devices []devices.KubernetesDeviceParams
+
+	// ProbeTiming overrides the timing of the agent's liveness, readiness
+	// and startup probes. When nil, the provider defaults are used.
+	ProbeTiming *ProbeTiming
+
+	// AgentProbePort is the port on which the agent in the charm container
+	// serves its liveness, readiness and startup probes. When zero, the
+	// provider default is used. It needs to be set only when the default
+	// conflicts with a port used by a workload container.
+	AgentProbePort int
+
+	// TerminationGracePeriod is how long the application's pods are given
+	// to shut down after being sent SIGTERM, before they're killed. When
+	// nil, the Kubernetes default of 30 seconds is used.
+	TerminationGracePeriod *time.Duration
+
+	// ImagePullSecrets are the names of existing secrets used to pull the
+	// charm and workload images from private registries. Secrets for images
+	// with credentials are created by the provider and don't need to be
+	// listed here.
+	ImagePullSecrets []string
+
+	// Tolerations allow the application's pods to be scheduled onto nodes
+	// with matching taints. Node affinity is derived from the tags and zones
+	// in Constraints.
+	Tolerations []Toleration
+
+	// TopologySpreadConstraints spread the application's pods across
+	// topology domains, such as availability zones.
+	TopologySpreadConstraints []TopologySpreadConstraint
+
+	// DNS configures name resolution in the application's pods. When nil,
+	// the Kubernetes default "ClusterFirst" policy is used.
+	DNS *DNSConfig
+
+	// HostAliases are added to the /etc/hosts file of the application's
+	// pods.
+	HostAliases []HostAlias
+
+	// PriorityClassName is the name of the priority class of the
+	// application's pods, which determines the order in which pods are
+	// scheduled and evicted. When empty, the cluster default is used.
+	PriorityClassName string
+
+	// HeadlessService configures the service used for peer discovery by
+	// stateful applications. When nil, the service is headless and
+	// publishes the addresses of units which aren't ready.
+	HeadlessService *HeadlessServiceConfig
+
+	// ServiceLabels and ServiceAnnotations are added to the application's
+	// services, e.g. to configure a cloud load balancer. Keys managed by
+	// Juju are ignored.
+	ServiceLabels      map[string]string
+	ServiceAnnotations map[string]string
+
+	// DisruptionBudget limits how many of the application's units can be
+	// voluntarily disrupted (e.g. by a node drain) at once. When nil, no
+	// budget is enforced.
+	DisruptionBudget *DisruptionBudget
+
+	// UpdateStrategy controls how the application's units are replaced
+	// when the application is updated. When nil, the Kubernetes default
+	// rolling update is used.
+	UpdateStrategy *UpdateStrategy
+
+	// SecurityContext sets the user and groups the application pod and its
+	// charm and init containers run as. When nil, the charm container runs
+	// as root.
+	SecurityContext *SecurityContext
+
+	// ServiceAccount configures a service account for the application pod,
+	// so that the charm can use the Kubernetes API. When nil, the pod has
+	// no service account token mounted.
+	ServiceAccount *ServiceAccountConfig
+
+	// CharmVolume configures the scratch volume shared by the charm and
+	// init containers. When nil, the volume is backed by the node's disk.
+	CharmVolume *CharmVolume
+
+	// ApplyAttempts is the maximum number of times the application's
+	// resources are applied when the Kubernetes API reports a conflict or
+	// server timeout. Defaults to 5 when zero.
+	ApplyAttempts int
+
+	// DryRun validates the application's resources with the substrate,
+	// including any admission checks, without persisting them.
+	DryRun bool
+}
+
+// ServiceAccountConfig describes the service account created for the
+// application pod.
+type ServiceAccountConfig struct {
+	// Rules are the permissions granted to the service account within the
+	// application's namespace. When empty, the service account is created
+	// without a role.
+	Rules []specs.PolicyRule
+}
+
+// CharmVolume describes the storage backing the charm's scratch volume.
+type CharmVolume struct {
+	// Medium is either empty, for the node's default storage, or
+	// "Memory" for a tmpfs volume.
+	Medium string
+	// SizeLimit is the maximum size of the volume (e.g. "256Mi"). Memory
+	// backed volumes count against the containers' memory limits.
+	SizeLimit string
+}
+
+// SecurityContext describes the identity the application pod runs as. Any
+// field left nil keeps the provider default.
+type SecurityContext struct {
+	// RunAsUser is the UID the charm container runs as. A non-zero UID
+	// requires the container to run as a non-root user.
+	RunAsUser *int64
+	// RunAsGroup is the primary GID of the charm container.
+	RunAsGroup *int64
+	// FSGroup is the supplementary group owning the pod's volumes. It
+	// defaults to the charm's group when running as a non-root user.
+	FSGroup *int64
+	// ReadOnlyRootFilesystem hardens the charm and init containers: their
+	// root filesystems are mounted read-only, privilege escalation is
+	// disallowed and all Linux capabilities are dropped. They can still
+	// write to the charm's data dirs and /tmp, which are mounted from the
+	// charm volume. Workload containers are unaffected.
+	ReadOnlyRootFilesystem bool
+}
+
+// HeadlessServiceConfig describes how the peer discovery service of a
+// stateful application is set up.
+type HeadlessServiceConfig struct {
+	// AllocateClusterIP requests a cluster IP for the service rather than
+	// it being headless.
+	AllocateClusterIP bool
+	// ReadyAddressesOnly restricts the service endpoints to units which
+	// are ready.
+	ReadyAddressesOnly bool
+}
+
+// Toleration describes a node taint the application's pods tolerate.
+type Toleration struct {
+	// Key is the taint key to match. An empty key with the Exists operator
+	// matches all taints.
+	Key string
+	// Operator is either "Exists" or "Equal". Defaults to "Equal".
+	Operator string
+	// Value is the taint value to match when the operator is "Equal".
+	Value string
+	// Effect is the taint effect to match ("NoSchedule", "PreferNoSchedule"
+	// or "NoExecute"). An empty effect matches all effects.
+	Effect string
+}
+
+// TopologySpreadConstraint describes how the application's pods are spread
+// across the domains of a topology, such as zones or nodes.
+type TopologySpreadConstraint struct {
+	// TopologyKey is the node label whose values are the domains
+	// (e.g. "topology.kubernetes.io/zone").
+	TopologyKey string
+	// MaxSkew is the maximum difference in the number of pods between any
+	// two domains. It must be at least 1.
+	MaxSkew int32
+	// WhenUnsatisfiable is either "DoNotSchedule" or "ScheduleAnyway".
+	// Defaults to "DoNotSchedule".
+	WhenUnsatisfiable string
+}
+
+// DNSConfig describes how the application's pods resolve names.
+type DNSConfig struct {
+	// Policy is "ClusterFirst", "ClusterFirstWithHostNet", "Default" or
+	// "None". Defaults to "ClusterFirst".
+	Policy string
+	// Nameservers, Searches and Options configure the pods' resolver.
+	// They're only used with the "None" policy, which requires at least
+	// one nameserver.
+	Nameservers []string
+	Searches    []string
+	Options     []DNSOption
+}
+
+// DNSOption is a resolver option, such as "ndots". Value may be empty for
+// options which don't take one.
+type DNSOption struct {
+	Name  string
+	Value string
+}
+
+// HostAlias maps an IP address to hostnames in a pod's /etc/hosts file.
+type HostAlias struct {
+	IP        string
+	Hostnames []string
+}
+
+// UpdateStrategy describes how the application's units are replaced when
+// the application is updated.
+type UpdateStrategy struct {
+	// Type is "RollingUpdate", "Recreate" (stateless applications only) or
+	// "OnDelete" (stateful and daemon applications only).
+	Type string
+	// MaxUnavailable is the number (e.g. "1") or percentage (e.g. "25%")
+	// of units which can be unavailable during a rolling update of a
+	// stateless or daemon application.
+	MaxUnavailable string
+	// MaxSurge is the number or percentage of units which can be created
+	// above the desired number during a rolling update of a stateless
+	// application.
+	MaxSurge string
+}
+
+// DisruptionBudget describes the number of units that must remain available
+// during voluntary disruptions. Exactly one of MinAvailable and
+// MaxUnavailable must be set, either as an absolute number of units (e.g.
+// "2") or as a percentage of the desired units (e.g. "50%").
+type DisruptionBudget struct {
+	MinAvailable   string
+	MaxUnavailable string
+}
+
+// ProbeTiming describes how often and how patiently the agent probes are
+// run. Any field left at its zero value uses the provider default.
+type ProbeTiming struct {
+	// InitialDelay is how long after the container starts before the
+	// probes are first run.
+	InitialDelay time.Duration
+
+	// Period is how often the probes are run.
+	Period time.Duration
+
+	// Timeout is how long a single probe may take before it is
+	// considered failed.
+	Timeout time.Duration
+
+	// SuccessThreshold is the number of consecutive successes required
+	// for a probe to be considered successful after having failed.
+	SuccessThreshold int32
+
+	// FailureThreshold is the number of consecutive failures after which
+	// the liveness and readiness probes are considered failed.
+	FailureThreshold int32
+
+	// StartupFailureThreshold is the number of consecutive failures after
+	// which the startup probe is considered failed. It is normally larger
+	// than FailureThreshold so slow starting charms aren't restarted while
+	// still initialising.
+	StartupFailureThreshold int32
+
+	// ImagePullAllowance extends the startup probe's window by the given
+	// duration, so that units on clusters with slow registries aren't
+	// restarted while the pod's workload images are still being pulled.
+	// The liveness and readiness probes are unaffected.
+	ImagePullAllowance time.Duration
 }
 
 // ContainerConfig describes a container that is deployed alonside the uniter/charm container.
@@ -112,8 +439,146 @@ type ContainerConfig struct {
 	// Image used to create the container.
 	Image resources.DockerImageDetails
 
+	// ImagePullPolicy is the pull policy ("Always", "IfNotPresent" or
+	// "Never") for the container image. Defaults to "IfNotPresent" when
+	// empty.
+	ImagePullPolicy string
+
 	// Mounts to storage that are to be provided within this container.
 	Mounts []MountConfig
+
+	// Resources are the compute resource requests and limits declared by the
+	// charm for this container.
+	Resources ContainerResources
+
+	// Probes are the optional health checks run against this container.
+	// When none are given, only the charm container is probed.
+	Probes ContainerProbes
+
+	// Env are the environment variables set in the container. Juju's own
+	// variables take precedence over any of the same name.
+	Env []EnvVar
+
+	// EnvFrom are the config maps and secrets whose keys are all set as
+	// environment variables in the container. Variables in Env, including
+	// Juju's own, take precedence over any of the same name.
+	EnvFrom []EnvFromSource
+
+	// Command, when set, replaces the Pebble entrypoint run in the
+	// container, and Args replace its arguments. The Pebble binary,
+	// socket and its PEBBLE_SOCKET variable remain available, so a custom
+	// entrypoint can still run Pebble for the charm to manage.
+	Command []string
+	Args    []string
+
+	// WorkingDir is the container's working directory. Defaults to the
+	// image's.
+	WorkingDir string
+
+	// Lifecycle are the optional hooks run when the container starts and
+	// before it's stopped.
+	Lifecycle ContainerLifecycle
+}
+
+// ContainerLifecycle describes the hooks run in a workload container. Any
+// hook left nil isn't run.
+type ContainerLifecycle struct {
+	// PostStart is run immediately after the container is created.
+	PostStart *LifecycleHandler
+	// PreStop is run before the container is terminated, such as to
+	// drain connections. The container is stopped once it completes or
+	// the termination grace period expires.
+	PreStop *LifecycleHandler
+}
+
+// LifecycleHandler describes the action taken by a lifecycle hook, in the
+// same way as for a probe. Exactly one of HTTPGet and Exec must be set.
+type LifecycleHandler struct {
+	HTTPGet *HTTPGetProbe
+	Exec    *ExecProbe
+}
+
+// EnvVar is an environment variable set in a container. Its value is either
+// given literally or read from a key of a config map or secret.
+type EnvVar struct {
+	Name  string
+	Value string
+	// ValueFrom, when set, is used instead of Value.
+	ValueFrom *EnvVarSource
+}
+
+// EnvVarSource references a single key of a config map or secret. Exactly
+// one of ConfigMapName and SecretName must be set.
+type EnvVarSource struct {
+	ConfigMapName string
+	SecretName    string
+	Key           string
+}
+
+// EnvFromSource references a config map or secret whose keys are all set
+// as environment variables. Exactly one of ConfigMapName and SecretName
+// must be set.
+type EnvFromSource struct {
+	ConfigMapName string
+	SecretName    string
+	// Prefix is prepended to the name of each variable.
+	Prefix string
+}
+
+// ContainerProbes describes the health checks run against a workload
+// container. Any probe left nil isn't run.
+type ContainerProbes struct {
+	// Liveness restarts the container when it fails.
+	Liveness *ContainerProbe
+	// Readiness marks the unit as not ready while it fails.
+	Readiness *ContainerProbe
+	// Startup holds off the other probes until it succeeds.
+	Startup *ContainerProbe
+}
+
+// ContainerProbe describes a single health check. Exactly one of HTTPGet,
+// TCPSocket and Exec must be set. Any timing left at its zero value uses
+// the Kubernetes default.
+type ContainerProbe struct {
+	HTTPGet   *HTTPGetProbe
+	TCPSocket *TCPSocketProbe
+	Exec      *ExecProbe
+
+	InitialDelay     time.Duration
+	Period           time.Duration
+	Timeout          time.Duration
+	SuccessThreshold int32
+	FailureThreshold int32
+}
+
+// HTTPGetProbe checks the container by sending it an HTTP GET request. Any
+// status code from 200 to 399 indicates success.
+type HTTPGetProbe struct {
+	Path string
+	Port int
+	// Scheme is either "HTTP" or "HTTPS". Defaults to "HTTP".
+	Scheme string
+}
+
+// TCPSocketProbe checks the container by opening a TCP connection to it.
+type TCPSocketProbe struct {
+	Port int
+}
+
+// ExecProbe checks the container by running a command in it. An exit
+// status of 0 indicates success.
+type ExecProbe struct {
+	Command []string
+}
+
+// ContainerResources describes the compute resources a charm declares for a
+// container, keyed by resource name (e.g. "cpu", "memory") with values in the
+// Kubernetes quantity format (e.g. "500m", "1Gi").
+// Operator constraints (cpu-power or mem) apply to the charm container only,
+// so the charm declared requests and limits are kept alongside them.
+type ContainerResources struct {
+	Requests map[string]string
+	Limits   map[string]string
 }
 
 // MountConfig describes a storage that should be mounted to a container.
@@ -5,6 +5,8 @@ package caas
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/juju/names/v4"
@@ -361,6 +363,41 @@ type Unit struct {
 	Stateful       bool
 	Status         status.StatusInfo
 	FilesystemInfo []FilesystemInfo
+
+	// RestartCount is the total number of times the unit's
+	// containers have been restarted.
+	RestartCount int
+
+	// QoSClass is the quality of service class ("Guaranteed", "Burstable"
+	// or "BestEffort") the substrate assigned to the unit, if reported.
+	QoSClass string
+}
+
+// UnitsError is returned with the units of an application when the details
+// of some of them couldn't be computed. Those units are still returned,
+// with an unknown status or without filesystem info.
+type UnitsError struct {
+	// Errors holds the error for each affected unit, keyed by unit Id.
+	Errors map[string]error
+}
+
+func (e *UnitsError) Error() string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("%s: %v", id, e.Errors[id])
+	}
+	return fmt.Sprintf("computing details of %d unit(s): %s", len(ids), strings.Join(msgs, "; "))
+}
+
+// IsUnitsError returns true if err is a UnitsError.
+func IsUnitsError(err error) bool {
+	_, ok := errors.Cause(err).(*UnitsError)
+	return ok
 }
 
 // Operator represents information about the status of an "operator pod".
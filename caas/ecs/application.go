@@ -6,6 +6,7 @@ package ecs
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
@@ -82,17 +83,24 @@ func (a *app) resourceName() string {
 	return fmt.Sprintf("%s-%s", a.modelName, a.name)
 }
 
-// Delete deletes the specified application.
-func (a *app) Delete() error {
+// Delete deletes the specified application. ECS applications claim no
+// storage, so there is none to destroy.
+func (a *app) Delete(ctx context.Context, opts caas.DeleteOptions) error {
 	if err := a.deleteService(); err != nil {
 		return errors.Trace(err)
 	}
-	if err := a.deleteTaskDefinitions(); err != nil {
+	if err := a.deleteTaskDefinitions(ctx); err != nil {
 		return errors.Trace(err)
 	}
 	return nil
 }
 
+// ForceDelete deletes the specified application. The ECS service is
+// always deleted forcibly, so this is the same as Delete.
+func (a *app) ForceDelete(ctx context.Context) error {
+	return a.Delete(ctx, caas.DeleteOptions{})
+}
+
 func (a *app) deleteService() error {
 	_, err := a.client.DeleteService(&ecs.DeleteServiceInput{
 		Cluster: aws.String(a.clusterName),
@@ -109,8 +117,8 @@ func (a *app) deleteService() error {
 
 const deleteTaskDefinitionTimeout = 30 * time.Second
 
-func (a *app) deleteTaskDefinitions() error {
-	ctx, cancel := context.WithTimeout(context.Background(), deleteTaskDefinitionTimeout)
+func (a *app) deleteTaskDefinitions(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, deleteTaskDefinitionTimeout)
 	defer cancel()
 
 	result, err := a.client.ListTaskDefinitionsWithContext(ctx,
@@ -455,7 +463,10 @@ func (a *app) applicationTaskDefinition(config caas.ApplicationConfig) (*ecs.Reg
 
 // Ensure creates or updates an application pod with the given application
 // name, agent path, and application config.
-func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
+func (a *app) Ensure(ctx context.Context, config caas.ApplicationConfig) (err error) {
+	if config.DryRun {
+		return errors.NotSupportedf("dry run for ecs application %q", a.name)
+	}
 	result, err := a.registerTaskDefinition(config)
 	if err != nil {
 		return errors.Trace(err)
@@ -470,12 +481,12 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 
 // Exists indicates if the application for the specified
 // application exists, and whether the application is terminating.
-func (a *app) Exists() (caas.DeploymentState, error) {
+func (a *app) Exists(ctx context.Context) (caas.DeploymentState, error) {
 	// TODO(ecs)
 	return caas.DeploymentState{}, nil
 }
 
-func (a *app) State() (caas.ApplicationState, error) {
+func (a *app) State(ctx context.Context) (caas.ApplicationState, error) {
 	// TODO(ecs)
 	return caas.ApplicationState{}, nil
 }
@@ -507,10 +518,14 @@ func computeStatus(ctx context.Context, t *ecs.Task) (statusMessage string, juju
 	return statusMessage, jujuStatus, since
 }
 
-// Units of the application fetched from kubernetes by matching pod labels.
-func (a *app) Units() (units []caas.Unit, err error) {
-	ctx := context.Background()
+// UnitLogs streams the logs of a container of the unit.
+func (a *app) UnitLogs(ctx context.Context, unitID, containerName string, opts caas.LogOptions) (io.ReadCloser, error) {
+	// TODO(ecs)
+	return nil, errors.NotSupportedf("unit logs for ecs application %q", a.name)
+}
 
+// Units of the application fetched from kubernetes by matching pod labels.
+func (a *app) Units(ctx context.Context) (units []caas.Unit, err error) {
 	result, err := a.client.ListTasks(&ecs.ListTasksInput{
 		Cluster:     aws.String(a.clusterName),
 		ServiceName: aws.String(a.resourceName()),
@@ -575,13 +590,13 @@ func (a *app) Units() (units []caas.Unit, err error) {
 }
 
 // UpdatePorts updates port mappings on the specified service.
-func (a *app) UpdatePorts(ports []caas.ServicePort, updateContainerPorts bool) error {
+func (a *app) UpdatePorts(ctx context.Context, ports []caas.ServicePort, updateContainerPorts bool) error {
 	// TODO(ecs)
 	return nil
 }
 
 // UpdateService updates the default service with specific service type and port mappings.
-func (a *app) UpdateService(param caas.ServiceParam) error {
+func (a *app) UpdateService(ctx context.Context, param caas.ServiceParam) error {
 	// TODO(ecs)
 	return nil
 }
@@ -632,6 +647,17 @@ func (a *app) Watch() (watcher.NotifyWatcher, error) {
 	return newNotifyWatcher(a.name, a.clock, hasNewEvents)
 }
 
+// WatchDeletion returns a watcher which notifies when the application starts
+// being removed.
+func (a *app) WatchDeletion() (watcher.NotifyWatcher, error) {
+	return nil, errors.NotSupportedf("watching application deletion on ECS")
+}
+
+// WatchUnits is not supported on ECS.
+func (a *app) WatchUnits(ctx context.Context) (watcher.StringsWatcher, error) {
+	return nil, errors.NotSupportedf("watching units on ECS")
+}
+
 // WatchReplicas returns a watcher for watching the number of units changes.
 func (a *app) WatchReplicas() (watcher.NotifyWatcher, error) {
 	// TODO(ecs)
@@ -4,6 +4,8 @@
 package ecs_test
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/golang/mock/gomock"
@@ -283,6 +285,7 @@ func (s *applicationSuite) assertEnsure(c *gc.C, app caas.Application, assertCal
 	)
 
 	c.Assert(app.Ensure(
+		context.Background(),
 		caas.ApplicationConfig{
 			AgentImagePath: "operator/image-path",
 			CharmBaseImage: coreresources.DockerImageDetails{
@@ -383,5 +386,5 @@ func (s *applicationSuite) TestDelete(c *gc.C) {
 		}).Return(nil, nil),
 	)
 
-	c.Assert(app.Delete(), jc.ErrorIsNil)
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{}), jc.ErrorIsNil)
 }
@@ -18,7 +18,10 @@ func (k *kubernetesClient) Application(name string, deploymentType caas.Deployme
 		deploymentType,
 		k.client(),
 		k.newWatcher,
+		k.newStringsWatcher,
+		k.storageClasses,
 		k.clock,
 		k.randomPrefix,
+		0,
 	)
 }
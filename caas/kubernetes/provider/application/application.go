@@ -5,10 +5,15 @@ package application
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/clock"
 	"github.com/juju/collections/set"
@@ -18,23 +23,29 @@ import (
 	"github.com/kr/pretty"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/juju/juju/caas"
+	k8spod "github.com/juju/juju/caas/kubernetes/pod"
 	"github.com/juju/juju/caas/kubernetes/provider/constants"
 	"github.com/juju/juju/caas/kubernetes/provider/resources"
 	"github.com/juju/juju/caas/kubernetes/provider/storage"
 	k8sutils "github.com/juju/juju/caas/kubernetes/provider/utils"
 	k8swatcher "github.com/juju/juju/caas/kubernetes/provider/watcher"
 	"github.com/juju/juju/core/annotations"
+	"github.com/juju/juju/core/constraints"
 	"github.com/juju/juju/core/paths"
 	"github.com/juju/juju/core/status"
 	"github.com/juju/juju/core/watcher"
@@ -45,11 +56,22 @@ var logger = loggo.GetLogger("juju.kubernetes.provider.application")
 
 const (
 	unitContainerName            = "charm"
+	initContainerName            = "charm-init"
 	charmVolumeName              = "charm-data"
 	agentProbeInitialDelay int32 = 30
 	agentProbePeriod       int32 = 10
+	agentProbeTimeout      int32 = 3
 	agentProbeSuccess      int32 = 1
 	agentProbeFailure      int32 = 2
+	// agentProbeStartupFailure gives the agent up to 5 minutes (after the
+	// initial delay) to start before the startup probe fails.
+	agentProbeStartupFailure int32 = 30
+
+	// placeholderPortName is the name of the port exposed by the default
+	// service until the charm's own ports are set. It's reserved so that
+	// it can't be confused with a port of the charm.
+	placeholderPortName       = "juju-placeholder"
+	placeholderPort     int32 = 65535
 )
 
 type app struct {
@@ -63,10 +85,21 @@ type app struct {
 	newWatcher     k8swatcher.NewK8sWatcherFunc
 	clock          clock.Clock
 
+	// newStringsWatcher creates the watcher of the application's units.
+	newStringsWatcher k8swatcher.NewK8sStringsWatcherFunc
+
+	// storageClasses, if set, lists the storage classes from a cache
+	// shared by the applications.
+	storageClasses StorageClassLister
+
 	// randomPrefix generates an annotation for stateful sets.
 	randomPrefix k8sutils.RandomPrefixFunc
 
 	newApplier func() resources.Applier
+
+	// resyncPeriod is how often the watchers are notified even when
+	// nothing has changed. Zero disables the periodic notifications.
+	resyncPeriod time.Duration
 }
 
 // NewApplication returns an application.
@@ -79,8 +112,11 @@ func NewApplication(
 	deploymentType caas.DeploymentType,
 	client kubernetes.Interface,
 	newWatcher k8swatcher.NewK8sWatcherFunc,
+	newStringsWatcher k8swatcher.NewK8sStringsWatcherFunc,
+	storageClasses StorageClassLister,
 	clock clock.Clock,
 	randomPrefix k8sutils.RandomPrefixFunc,
+	resyncPeriod time.Duration,
 ) caas.Application {
 	return newApplication(
 		name,
@@ -91,9 +127,12 @@ func NewApplication(
 		deploymentType,
 		client,
 		newWatcher,
+		newStringsWatcher,
+		storageClasses,
 		clock,
 		randomPrefix,
 		resources.NewApplier,
+		resyncPeriod,
 	)
 }
 
@@ -106,33 +145,46 @@ func newApplication(
 	deploymentType caas.DeploymentType,
 	client kubernetes.Interface,
 	newWatcher k8swatcher.NewK8sWatcherFunc,
+	newStringsWatcher k8swatcher.NewK8sStringsWatcherFunc,
+	storageClasses StorageClassLister,
 	clock clock.Clock,
 	randomPrefix k8sutils.RandomPrefixFunc,
 	newApplier func() resources.Applier,
+	resyncPeriod time.Duration,
 ) caas.Application {
 	return &app{
-		name:           name,
-		namespace:      namespace,
-		modelUUID:      modelUUID,
-		modelName:      modelName,
-		legacyLabels:   legacyLabels,
-		deploymentType: deploymentType,
-		client:         client,
-		newWatcher:     newWatcher,
-		clock:          clock,
-		randomPrefix:   randomPrefix,
-		newApplier:     newApplier,
+		name:              name,
+		namespace:         namespace,
+		modelUUID:         modelUUID,
+		modelName:         modelName,
+		legacyLabels:      legacyLabels,
+		deploymentType:    deploymentType,
+		client:            client,
+		newWatcher:        newWatcher,
+		newStringsWatcher: newStringsWatcher,
+		storageClasses:    storageClasses,
+		clock:             clock,
+		randomPrefix:      randomPrefix,
+		newApplier:        newApplier,
+		resyncPeriod:      resyncPeriod,
 	}
 }
 
 // Ensure creates or updates an application pod with the given application
 // name, agent path, and application config.
-func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
+func (a *app) Ensure(ctx context.Context, config caas.ApplicationConfig) (err error) {
 	// TODO: add support `numUnits`, `Constraints` and `Devices`.
 	// TODO: storage handling for deployment/daemonset enhancement.
+	recordEvent := a.recordEvent
+	if config.DryRun {
+		// Nothing is persisted in dry-run mode, events included.
+		ctx = resources.WithDryRun(ctx)
+		recordEvent = func(context.Context, string, string, string, ...interface{}) {}
+	}
 	defer func() {
 		if err != nil {
 			logger.Errorf("Ensure %s", err)
+			recordEvent(ctx, corev1.EventTypeWarning, eventReasonEnsureFailed, "ensuring application: %v", err)
 		}
 	}()
 	logger.Debugf("creating/updating %s application", a.name)
@@ -157,15 +209,40 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 	}
 	applier.Apply(&secret)
 
-	if err := a.configureDefaultService(a.annotations(config)); err != nil {
+	pullSecrets, err := a.imagePullSecretResources(config)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, pullSecret := range pullSecrets {
+		applier.Apply(pullSecret)
+	}
+	if err := a.ensureServiceAccount(applier, config.ServiceAccount); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := a.configureDefaultService(ctx, a.annotations(config), config.ServiceLabels, config.ServiceAnnotations); err != nil {
 		return errors.Annotatef(err, "ensuring the default service %q", a.name)
 	}
+	recordEvent(ctx, corev1.EventTypeNormal, eventReasonServiceConfigured, "configured service %q", a.name)
 
 	// Set up the parameters for creating charm storage (if required).
 	podSpec, err := a.applicationPodSpec(config)
 	if err != nil {
 		return errors.Annotate(err, "generating application podspec")
 	}
+	if podSpec.PriorityClassName != "" {
+		// The pods are rejected until the priority class is created, but
+		// it may be created after the application, so only warn.
+		exists, err := a.priorityClassExists(ctx, podSpec.PriorityClassName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !exists {
+			logger.Warningf("priority class %q of application %q not found", podSpec.PriorityClassName, a.name)
+			recordEvent(ctx, corev1.EventTypeWarning, eventReasonPriorityClassNotFound,
+				"priority class %q not found", podSpec.PriorityClassName)
+		}
+	}
 
 	var handleVolume handleVolumeFunc = func(v corev1.Volume, mountPath string, readOnly bool) (*corev1.VolumeMount, error) {
 		if err := storage.PushUniqueVolume(podSpec, v, false); err != nil {
@@ -177,11 +254,33 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 			MountPath: mountPath,
 		}, nil
 	}
+	// storageMountPaths records, for each container, the storage
+	// mounted at each path so that clashing mounts are rejected up front.
+	storageMountPaths := make(map[string]map[string]string)
+	addVolumeMount := func(i int, storageName string, m corev1.VolumeMount) error {
+		name := podSpec.Containers[i].Name
+		paths, ok := storageMountPaths[name]
+		if !ok {
+			paths = make(map[string]string)
+			storageMountPaths[name] = paths
+		}
+		if existing, ok := paths[m.MountPath]; ok {
+			return errors.NotValidf(
+				"storage %q and %q both mounted at %q in container %q",
+				existing, storageName, m.MountPath, name,
+			)
+		}
+		paths[m.MountPath] = storageName
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, m)
+		return nil
+	}
 	var handleVolumeMount handleVolumeMountFunc = func(storageName string, m corev1.VolumeMount) error {
 		for i := range podSpec.Containers {
 			name := podSpec.Containers[i].Name
 			if name == unitContainerName {
-				podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, m)
+				if err := addVolumeMount(i, storageName, m); err != nil {
+					return errors.Trace(err)
+				}
 				continue
 			}
 			for _, mount := range config.Containers[name].Mounts {
@@ -190,7 +289,9 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 					// TODO(embedded): volumeMountCopy.MountPath was defined in `caas.ApplicationConfig.Filesystems[*].Attachment.Path`.
 					// Consolidate `caas.ApplicationConfig.Filesystems[*].Attachment.Path` and `caas.ApplicationConfig.Containers[*].Mounts[*].Path`!!!
 					volumeMountCopy.MountPath = mount.Path
-					podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, volumeMountCopy)
+					if err := addVolumeMount(i, storageName, volumeMountCopy); err != nil {
+						return errors.Trace(err)
+					}
 				}
 			}
 		}
@@ -213,31 +314,42 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 		}
 		return handleVolume(vol, mountPath, readOnly)
 	}
-	storageClasses, err := resources.ListStorageClass(context.Background(), a.client, metav1.ListOptions{})
+	storageClasses, err := a.listStorageClasses(ctx)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	// storageClassApplied records whether a storage class is applied, so
+	// the storage classes are listed afresh next time.
+	storageClassApplied := false
 	var handleStorageClass = func(sc storagev1.StorageClass) error {
 		applier.Apply(&resources.StorageClass{StorageClass: sc})
+		storageClassApplied = true
 		return nil
 	}
 	var configureStorage = func(storageUniqueID string, handlePVC handlePVCFunc) error {
 		err := a.configureStorage(
+			ctx,
 			storageUniqueID,
 			config.Filesystems,
+			storage.MountPathStrategy(config.FilesystemMountPathStrategy),
 			storageClasses,
 			handleVolume, handleVolumeMount, handlePVC, handleStorageClass,
 		)
 		return errors.Trace(err)
 	}
 
+	var customHandler DeploymentHandler
 	switch a.deploymentType {
 	case caas.DeploymentStateful:
-		if err := a.configureHeadlessService(a.name, a.annotations(config)); err != nil {
+		if err := a.configureHeadlessService(
+			ctx, a.name, a.annotations(config), config.HeadlessService,
+			config.ServiceLabels, config.ServiceAnnotations,
+		); err != nil {
 			return errors.Annotatef(err, "creating or updating headless service for %q %q", a.deploymentType, a.name)
 		}
+		recordEvent(ctx, corev1.EventTypeNormal, eventReasonServiceConfigured, "configured service %q", headlessServiceName(a.name))
 		exists := true
-		ss, getErr := a.getStatefulSet()
+		ss, getErr := a.getStatefulSet(ctx)
 		if errors.IsNotFound(getErr) {
 			exists = false
 		} else if getErr != nil {
@@ -253,6 +365,10 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 		if !exists {
 			numPods = int32Ptr(1)
 		}
+		updateStrategy, err := statefulSetUpdateStrategy(config.UpdateStrategy)
+		if err != nil {
+			return errors.Trace(err)
+		}
 		statefulset := resources.StatefulSet{
 			StatefulSet: appsv1.StatefulSet{
 				ObjectMeta: metav1.ObjectMeta{
@@ -275,6 +391,7 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 						Spec: *podSpec,
 					},
 					PodManagementPolicy: appsv1.ParallelPodManagement,
+					UpdateStrategy:      updateStrategy,
 				},
 			},
 		}
@@ -295,10 +412,18 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 			return errors.Trace(err)
 		}
 
+		if exists {
+			if fields := statefulSetImmutableFieldChanges(ss.Spec, statefulset.Spec); len(fields) > 0 {
+				return a.immutableFieldsError("statefulset", fields)
+			}
+		}
 		applier.Apply(&statefulset)
+		if err := a.ensureDisruptionBudget(applier, config.DisruptionBudget); err != nil {
+			return errors.Trace(err)
+		}
 	case caas.DeploymentStateless:
 		exists := true
-		d, getErr := a.getDeployment()
+		d, getErr := a.getDeployment(ctx)
 		if errors.IsNotFound(getErr) {
 			exists = false
 		} else if getErr != nil {
@@ -318,6 +443,10 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 		if err = configureStorage(storageUniqueID, handlePVCForStatelessResource); err != nil {
 			return errors.Trace(err)
 		}
+		strategy, err := deploymentStrategy(config.UpdateStrategy)
+		if err != nil {
+			return errors.Trace(err)
+		}
 		deployment := resources.Deployment{
 			Deployment: appsv1.Deployment{
 				ObjectMeta: metav1.ObjectMeta{
@@ -339,14 +468,28 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 						},
 						Spec: *podSpec,
 					},
+					Strategy: strategy,
 				},
 			},
 		}
 
+		if exists && labelSelectorChanged(d.Spec.Selector, deployment.Spec.Selector) {
+			return a.immutableFieldsError("deployment", []string{"selector"})
+		}
 		applier.Apply(&deployment)
+		if err := a.ensureDisruptionBudget(applier, config.DisruptionBudget); err != nil {
+			return errors.Trace(err)
+		}
 	case caas.DeploymentDaemon:
+		exists := true
+		ds, getErr := a.getDaemonSet(ctx)
+		if errors.IsNotFound(getErr) {
+			exists = false
+		} else if getErr != nil {
+			return errors.Trace(getErr)
+		}
 		storageUniqueID, err := a.getStorageUniqPrefix(func() (annotationGetter, error) {
-			return a.getDaemonSet()
+			return ds, getErr
 		})
 		if err != nil {
 			return errors.Trace(err)
@@ -355,6 +498,10 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 		if err = configureStorage(storageUniqueID, handlePVCForStatelessResource); err != nil {
 			return errors.Trace(err)
 		}
+		updateStrategy, err := daemonSetUpdateStrategy(config.UpdateStrategy)
+		if err != nil {
+			return errors.Trace(err)
+		}
 		daemonset := resources.DaemonSet{
 			DaemonSet: appsv1.DaemonSet{
 				ObjectMeta: metav1.ObjectMeta{
@@ -375,23 +522,55 @@ func (a *app) Ensure(config caas.ApplicationConfig) (err error) {
 						},
 						Spec: *podSpec,
 					},
+					UpdateStrategy: updateStrategy,
 				},
 			},
 		}
+		if exists && labelSelectorChanged(ds.Spec.Selector, daemonset.Spec.Selector) {
+			return a.immutableFieldsError("daemonset", []string{"selector"})
+		}
 		applier.Apply(&daemonset)
 	default:
-		return errors.NotSupportedf("unknown deployment type")
+		if customHandler, err = deploymentHandler(a.deploymentType); err != nil {
+			return errors.Trace(err)
+		}
+		if len(config.Filesystems) > 0 {
+			return errors.NotSupportedf("storage for deployment type %q", a.deploymentType)
+		}
 	}
 
-	return applier.Run(context.Background(), a.client, false)
+	err = a.runWithRetry(ctx, applier, config.ApplyAttempts)
+	if storageClassApplied && a.storageClasses != nil {
+		// Whether or not the run succeeded, the cached storage
+		// classes may now be stale.
+		a.storageClasses.Invalidate()
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if customHandler != nil {
+		// The workload is managed by the handler, so it isn't owned
+		// by Juju's resources.
+		workload := a.workload()
+		workload.Annotations = a.annotations(config)
+		workload.PodSpec = podSpec
+		return errors.Trace(customHandler.Ensure(ctx, workload, config))
+	}
+	recordEvent(ctx, corev1.EventTypeNormal, eventReasonSecretApplied, "applied secret %q", secret.Name)
+	recordEvent(ctx, corev1.EventTypeNormal, eventReasonWorkloadApplied, "applied %s %q", strings.ToLower(a.workloadKind()), a.name)
+	if config.DryRun {
+		// The workload wasn't created, so there's no owner to reference.
+		return nil
+	}
+	return errors.Trace(a.ensureOwnerReferences(ctx, config))
 }
 
 // Exists indicates if the application for the specified
 // application exists, and whether the application is terminating.
-func (a *app) Exists() (caas.DeploymentState, error) {
+func (a *app) Exists(ctx context.Context) (caas.DeploymentState, error) {
 	checks := []struct {
 		label            string
-		check            func() (bool, bool, error)
+		check            func(context.Context) (bool, bool, error)
 		forceTerminating bool
 	}{
 		{},
@@ -409,12 +588,19 @@ func (a *app) Exists() (caas.DeploymentState, error) {
 		checks[0].label = "daemonset"
 		checks[0].check = a.daemonSetExists
 	default:
-		return caas.DeploymentState{}, errors.NotSupportedf("unknown deployment type")
+		handler, err := deploymentHandler(a.deploymentType)
+		if err != nil {
+			return caas.DeploymentState{}, errors.Trace(err)
+		}
+		checks[0].label = string(a.deploymentType)
+		checks[0].check = func(ctx context.Context) (bool, bool, error) {
+			return handler.Exists(ctx, a.workload())
+		}
 	}
 
 	state := caas.DeploymentState{}
 	for _, c := range checks {
-		exists, terminating, err := c.check()
+		exists, terminating, err := c.check(ctx)
 		if err != nil {
 			return caas.DeploymentState{}, errors.Annotatef(err, "%s resource check", c.label)
 		}
@@ -432,70 +618,419 @@ func (a *app) Exists() (caas.DeploymentState, error) {
 	return state, nil
 }
 
+// ensureDisruptionBudget applies the pod disruption budget for the
+// application, or removes any existing one if no budget is configured.
+func (a *app) ensureDisruptionBudget(applier resources.Applier, budget *caas.DisruptionBudget) error {
+	pdb := resources.NewPodDisruptionBudget(a.name, a.namespace, &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: a.labels(),
+		},
+	})
+	if budget == nil {
+		applier.Delete(pdb)
+		return nil
+	}
+	if (budget.MinAvailable == "") == (budget.MaxUnavailable == "") {
+		return errors.NewNotValid(nil, "disruption budget requires exactly one of min available or max unavailable")
+	}
+	pdb.Spec = policyv1beta1.PodDisruptionBudgetSpec{
+		Selector: &metav1.LabelSelector{
+			MatchLabels: a.selectorLabels(),
+		},
+	}
+	if budget.MinAvailable != "" {
+		minAvailable := intstr.Parse(budget.MinAvailable)
+		pdb.Spec.MinAvailable = &minAvailable
+	} else {
+		maxUnavailable := intstr.Parse(budget.MaxUnavailable)
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+	applier.Apply(pdb)
+	return nil
+}
+
+// statefulSetUpdateStrategy returns the update strategy for a stateful
+// application. The zero value leaves the Kubernetes default in place.
+func statefulSetUpdateStrategy(strategy *caas.UpdateStrategy) (appsv1.StatefulSetUpdateStrategy, error) {
+	if strategy == nil {
+		return appsv1.StatefulSetUpdateStrategy{}, nil
+	}
+	if strategy.MaxUnavailable != "" || strategy.MaxSurge != "" {
+		return appsv1.StatefulSetUpdateStrategy{}, errors.NotSupportedf("max unavailable or max surge for stateful applications")
+	}
+	switch strategy.Type {
+	case string(appsv1.RollingUpdateStatefulSetStrategyType):
+		return appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}, nil
+	case string(appsv1.OnDeleteStatefulSetStrategyType):
+		return appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}, nil
+	}
+	return appsv1.StatefulSetUpdateStrategy{}, errors.NotValidf("update strategy %q for stateful applications", strategy.Type)
+}
+
+// deploymentStrategy returns the update strategy for a stateless
+// application. The zero value leaves the Kubernetes default in place.
+func deploymentStrategy(strategy *caas.UpdateStrategy) (appsv1.DeploymentStrategy, error) {
+	if strategy == nil {
+		return appsv1.DeploymentStrategy{}, nil
+	}
+	switch strategy.Type {
+	case string(appsv1.RollingUpdateDeploymentStrategyType):
+		rollingUpdate := &appsv1.RollingUpdateDeployment{}
+		if strategy.MaxUnavailable != "" {
+			maxUnavailable := intstr.Parse(strategy.MaxUnavailable)
+			rollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+		if strategy.MaxSurge != "" {
+			maxSurge := intstr.Parse(strategy.MaxSurge)
+			rollingUpdate.MaxSurge = &maxSurge
+		}
+		return appsv1.DeploymentStrategy{
+			Type:          appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: rollingUpdate,
+		}, nil
+	case string(appsv1.RecreateDeploymentStrategyType):
+		if strategy.MaxUnavailable != "" || strategy.MaxSurge != "" {
+			return appsv1.DeploymentStrategy{}, errors.NotValidf("max unavailable or max surge with the recreate update strategy")
+		}
+		return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}, nil
+	}
+	return appsv1.DeploymentStrategy{}, errors.NotValidf("update strategy %q for stateless applications", strategy.Type)
+}
+
+// daemonSetUpdateStrategy returns the update strategy for a daemon
+// application. The zero value leaves the Kubernetes default in place.
+func daemonSetUpdateStrategy(strategy *caas.UpdateStrategy) (appsv1.DaemonSetUpdateStrategy, error) {
+	if strategy == nil {
+		return appsv1.DaemonSetUpdateStrategy{}, nil
+	}
+	if strategy.MaxSurge != "" {
+		return appsv1.DaemonSetUpdateStrategy{}, errors.NotSupportedf("max surge for daemon applications")
+	}
+	switch strategy.Type {
+	case string(appsv1.RollingUpdateDaemonSetStrategyType):
+		rollingUpdate := &appsv1.RollingUpdateDaemonSet{}
+		if strategy.MaxUnavailable != "" {
+			maxUnavailable := intstr.Parse(strategy.MaxUnavailable)
+			rollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+		return appsv1.DaemonSetUpdateStrategy{
+			Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+			RollingUpdate: rollingUpdate,
+		}, nil
+	case string(appsv1.OnDeleteDaemonSetStrategyType):
+		if strategy.MaxUnavailable != "" {
+			return appsv1.DaemonSetUpdateStrategy{}, errors.NotValidf("max unavailable with the on delete update strategy")
+		}
+		return appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}, nil
+	}
+	return appsv1.DaemonSetUpdateStrategy{}, errors.NotValidf("update strategy %q for daemon applications", strategy.Type)
+}
+
+// ensureOwnerReferences makes the application's workload resource the owner
+// of the secrets and services created alongside it, so that deleting the
+// workload also removes them. Persistent volume claims are deliberately not
+// owned by the workload, so storage outlives it.
+func (a *app) ensureOwnerReferences(ctx context.Context, config caas.ApplicationConfig) error {
+	var (
+		owner metav1.Object
+		kind  string
+	)
+	switch a.deploymentType {
+	case caas.DeploymentStateful:
+		ss, err := a.getStatefulSet(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		owner, kind = ss, "StatefulSet"
+	case caas.DeploymentStateless:
+		d, err := a.getDeployment(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		owner, kind = d, "Deployment"
+	case caas.DeploymentDaemon:
+		ds, err := a.getDaemonSet(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		owner, kind = ds, "DaemonSet"
+	default:
+		return errors.NotSupportedf("unknown deployment type")
+	}
+	if owner.GetUID() == "" {
+		// An owner can only be referenced once the API server has
+		// assigned it a UID.
+		return nil
+	}
+	ownerRef := metav1.OwnerReference{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       kind,
+		Name:       owner.GetName(),
+		UID:        owner.GetUID(),
+	}
+
+	applier := a.newApplier()
+	secretNames := []string{a.secretName()}
+	for name := range a.privateImages(config) {
+		secretNames = append(secretNames, name)
+	}
+	sort.Strings(secretNames)
+	for _, name := range secretNames {
+		secret := resources.NewSecret(name, a.namespace, nil)
+		if err := secret.Get(ctx, a.client); err != nil {
+			return errors.Trace(err)
+		}
+		if addOwnerReference(&secret.ObjectMeta, ownerRef) {
+			applier.Apply(secret)
+		}
+	}
+	serviceNames := []string{a.name}
+	if a.deploymentType == caas.DeploymentStateful {
+		serviceNames = append(serviceNames, headlessServiceName(a.name))
+	}
+	for _, name := range serviceNames {
+		svc := resources.NewService(name, a.namespace, nil)
+		if err := svc.Get(ctx, a.client); err != nil {
+			return errors.Trace(err)
+		}
+		if addOwnerReference(&svc.ObjectMeta, ownerRef) {
+			applier.Apply(svc)
+		}
+	}
+	return applier.Run(ctx, a.client, false)
+}
+
+// addOwnerReference adds the owner reference to the object if it isn't
+// already there, and reports whether the object was changed.
+func addOwnerReference(obj *metav1.ObjectMeta, ownerRef metav1.OwnerReference) bool {
+	for _, ref := range obj.OwnerReferences {
+		if ref.UID == ownerRef.UID {
+			return false
+		}
+	}
+	obj.OwnerReferences = append(obj.OwnerReferences, ownerRef)
+	return true
+}
+
+// maxServiceNameLength is the maximum length of a service name, which must
+// be a valid DNS label.
+const maxServiceNameLength = 63
+
+// headlessServiceName returns the name of the application's headless
+// service. Names which would be too long have the application name truncated
+// and a hash of the full name added, so that they remain distinct.
 func headlessServiceName(appName string) string {
-	return fmt.Sprintf("%s-endpoints", appName)
+	name := fmt.Sprintf("%s-endpoints", appName)
+	if len(name) <= maxServiceNameLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := fmt.Sprintf("-%s-endpoints", hex.EncodeToString(sum[:])[:8])
+	prefix := strings.TrimRight(appName[:maxServiceNameLength-len(suffix)], "-")
+	return prefix + suffix
 }
 
-func (a *app) configureHeadlessService(name string, annotation annotations.Annotation) error {
+func (a *app) configureHeadlessService(
+	ctx context.Context,
+	name string, annotation annotations.Annotation, config *caas.HeadlessServiceConfig,
+	extraLabels, extraAnnotations map[string]string,
+) error {
+	clusterIP := "None"
+	publishNotReadyAddresses := true
+	if config != nil {
+		if config.AllocateClusterIP {
+			clusterIP = ""
+		}
+		publishNotReadyAddresses = !config.ReadyAddressesOnly
+	}
+	existing := resources.NewService(headlessServiceName(name), a.namespace, nil)
+	if err := existing.Get(ctx, a.client); err == nil {
+		// The cluster IP of a service can't be changed between being
+		// allocated and "None" once the service has been created.
+		if (existing.Spec.ClusterIP == "None") != (clusterIP == "None") {
+			return a.immutableFieldsError("headless service", []string{"clusterIP"})
+		}
+	} else if !errors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
 	svc := resources.NewService(headlessServiceName(name), a.namespace, &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels: a.labels(),
-			Annotations: annotation.
-				Add("service.alpha.kubernetes.io/tolerate-unready-endpoints", "true"),
+			Labels: k8sutils.LabelsMerge(
+				a.serviceLabels(extraLabels), a.labels(),
+			),
+			Annotations: annotations.New(a.serviceAnnotations(extraAnnotations)).
+				Merge(annotation).
+				Add("service.alpha.kubernetes.io/tolerate-unready-endpoints", strconv.FormatBool(publishNotReadyAddresses)),
 		},
 		Spec: corev1.ServiceSpec{
 			Selector:                 a.selectorLabels(),
 			Type:                     corev1.ServiceTypeClusterIP,
-			ClusterIP:                "None",
-			PublishNotReadyAddresses: true,
+			ClusterIP:                clusterIP,
+			PublishNotReadyAddresses: publishNotReadyAddresses,
 		},
 	})
-	return svc.Apply(context.Background(), a.client)
+	return svc.Apply(ctx, a.client)
 }
 
 // configureDefaultService configures the default service for the application.
-// It's only configured once when the application was deployed in the first time.
-func (a *app) configureDefaultService(annotation annotations.Annotation) (err error) {
+// It's only configured once when the application was deployed in the first time,
+// after which only any new extra labels and annotations are added.
+func (a *app) configureDefaultService(
+	ctx context.Context, annotation annotations.Annotation, extraLabels, extraAnnotations map[string]string,
+) (err error) {
 	svc := resources.NewService(a.name, a.namespace, &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:      a.labels(),
-			Annotations: annotation,
+			Labels:      k8sutils.LabelsMerge(a.serviceLabels(extraLabels), a.labels()),
+			Annotations: annotations.New(a.serviceAnnotations(extraAnnotations)).Merge(annotation),
 		},
 		Spec: corev1.ServiceSpec{
 			Selector: a.selectorLabels(),
 			Type:     corev1.ServiceTypeClusterIP,
 			Ports: []corev1.ServicePort{{
-				Name: "placeholder",
-				Port: 65535,
+				Name: placeholderPortName,
+				Port: placeholderPort,
 			}},
 		},
 	})
-	if err = svc.Get(context.Background(), a.client); errors.IsNotFound(err) {
-		return svc.Apply(context.Background(), a.client)
+	if err = svc.Get(ctx, a.client); errors.IsNotFound(err) {
+		return svc.Apply(ctx, a.client)
+	} else if err != nil {
+		return errors.Trace(err)
 	}
-	return errors.Trace(err)
+	if a.mergeServiceMetadata(&svc.ObjectMeta, extraLabels, extraAnnotations) {
+		return errors.Trace(svc.Apply(ctx, a.client))
+	}
+	return nil
+}
+
+// serviceLabels returns the extra labels requested for the application's
+// services, without any which would override Juju's own labels.
+func (a *app) serviceLabels(extra map[string]string) labels.Set {
+	managed := a.labels()
+	out := labels.Set{}
+	for k, v := range extra {
+		if _, ok := managed[k]; ok || isJujuKey(k) {
+			logger.Warningf("ignoring service label %q for %q managed by juju", k, a.name)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// serviceAnnotations returns the extra annotations requested for the
+// application's services, without any in Juju's domains.
+func (a *app) serviceAnnotations(extra map[string]string) map[string]string {
+	out := make(map[string]string)
+	for k, v := range extra {
+		if isJujuKey(k) {
+			logger.Warningf("ignoring service annotation %q for %q managed by juju", k, a.name)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// mergeServiceMetadata adds the extra labels and annotations to the service
+// metadata, and reports whether it was changed.
+func (a *app) mergeServiceMetadata(meta *metav1.ObjectMeta, extraLabels, extraAnnotations map[string]string) bool {
+	changed := false
+	for k, v := range a.serviceLabels(extraLabels) {
+		if meta.Labels[k] == v {
+			continue
+		}
+		if meta.Labels == nil {
+			meta.Labels = make(map[string]string)
+		}
+		meta.Labels[k] = v
+		changed = true
+	}
+	for k, v := range a.serviceAnnotations(extraAnnotations) {
+		if meta.Annotations[k] == v {
+			continue
+		}
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		meta.Annotations[k] = v
+		changed = true
+	}
+	return changed
+}
+
+// isJujuKey reports whether the label or annotation key is in one of
+// Juju's domains, or is a legacy Juju key.
+func isJujuKey(key string) bool {
+	if strings.HasPrefix(key, "juju-") {
+		return true
+	}
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	domain := key[:i]
+	for _, d := range []string{constants.Domain, constants.LegacyDomain} {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
 }
 
 // UpdateService updates the default service with specific service type and port mappings.
-func (a *app) UpdateService(param caas.ServiceParam) error {
+func (a *app) UpdateService(ctx context.Context, param caas.ServiceParam) error {
 	// This method will be used for juju [un]expose.
 	// TODO(embedded): it might be changed later when we have proper modelling for the juju expose for the embedded charms.
-	svc, err := a.getService()
+	svc, err := a.getService(ctx)
 	if err != nil {
 		return errors.Annotatef(err, "getting existing service %q", a.name)
 	}
 	svc.Service.Spec.Type = corev1.ServiceType(param.Type)
-	svc.Service.Spec.Ports = make([]corev1.ServicePort, len(param.Ports))
-	for i, p := range param.Ports {
-		svc.Service.Spec.Ports[i] = convertServicePort(p)
+	if svc.Service.Spec.Ports, err = convertServicePorts(param.Ports); err != nil {
+		return errors.Trace(err)
+	}
+	svc.Service.Spec.LoadBalancerSourceRanges = nil
+	if svc.Service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, cidr := range param.LoadBalancerSourceRanges {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return errors.NotValidf("load balancer source range %q", cidr)
+			}
+		}
+		svc.Service.Spec.LoadBalancerSourceRanges = param.LoadBalancerSourceRanges
+	}
+	for _, ip := range param.ExternalIPs {
+		if net.ParseIP(ip) == nil {
+			return errors.NotValidf("external IP %q", ip)
+		}
+	}
+	svc.Service.Spec.ExternalIPs = param.ExternalIPs
+	a.mergeServiceMetadata(&svc.ObjectMeta, param.Labels, param.Annotations)
+	if len(svc.Service.Spec.Ports) == 0 {
+		// A service must expose at least one port, so the placeholder port
+		// is only ever replaced by real ones.
+		return errors.NotValidf("no ports for service %q", a.name)
 	}
 
 	applier := a.newApplier()
 	applier.Apply(svc)
-	if err := a.updateContainerPorts(applier, svc.Service.Spec.Ports); err != nil {
+	if err := a.updateContainerPorts(ctx, applier, svc.Service.Spec.Ports); err != nil {
 		return errors.Trace(err)
 	}
-	return applier.Run(context.Background(), a.client, false)
+	return applier.Run(ctx, a.client, false)
+}
+
+// convertServicePorts converts the charm's ports to service ports, which
+// replace all the existing ports of the service, including the placeholder.
+func convertServicePorts(ports []caas.ServicePort) ([]corev1.ServicePort, error) {
+	out := make([]corev1.ServicePort, len(ports))
+	for i, p := range ports {
+		if p.Name == placeholderPortName {
+			return nil, errors.NotValidf("reserved port name %q", p.Name)
+		}
+		out[i] = convertServicePort(p)
+	}
+	return out, nil
 }
 
 func convertServicePort(p caas.ServicePort) corev1.ServicePort {
@@ -507,33 +1042,32 @@ func convertServicePort(p caas.ServicePort) corev1.ServicePort {
 	}
 }
 
-func (a *app) getService() (*resources.Service, error) {
+func (a *app) getService(ctx context.Context) (*resources.Service, error) {
 	svc := resources.NewService(a.name, a.namespace, nil)
-	if err := svc.Get(context.Background(), a.client); err != nil {
+	if err := svc.Get(ctx, a.client); err != nil {
 		return nil, errors.Trace(err)
 	}
 	return svc, nil
 }
 
 // UpdatePorts updates port mappings on the specified service.
-func (a *app) UpdatePorts(ports []caas.ServicePort, updateContainerPorts bool) error {
-	svc, err := a.getService()
+func (a *app) UpdatePorts(ctx context.Context, ports []caas.ServicePort, updateContainerPorts bool) error {
+	svc, err := a.getService(ctx)
 	if err != nil {
 		return errors.Annotatef(err, "getting existing service %q", a.name)
 	}
-	svc.Service.Spec.Ports = make([]corev1.ServicePort, len(ports))
-	for i, port := range ports {
-		svc.Service.Spec.Ports[i] = convertServicePort(port)
+	if svc.Service.Spec.Ports, err = convertServicePorts(ports); err != nil {
+		return errors.Trace(err)
 	}
 	applier := a.newApplier()
 	applier.Apply(svc)
 
 	if updateContainerPorts {
-		if err := a.updateContainerPorts(applier, svc.Service.Spec.Ports); err != nil {
+		if err := a.updateContainerPorts(ctx, applier, svc.Service.Spec.Ports); err != nil {
 			return errors.Trace(err)
 		}
 	}
-	err = applier.Run(context.Background(), a.client, false)
+	err = applier.Run(ctx, a.client, false)
 	return errors.Trace(err)
 }
 
@@ -545,7 +1079,7 @@ func convertContainerPort(p corev1.ServicePort) corev1.ContainerPort {
 	}
 }
 
-func (a *app) updateContainerPorts(applier resources.Applier, ports []corev1.ServicePort) error {
+func (a *app) updateContainerPorts(ctx context.Context, applier resources.Applier, ports []corev1.ServicePort) error {
 	updatePodSpec := func(spec *corev1.PodSpec, containerPorts []corev1.ContainerPort) {
 		for i, c := range spec.Containers {
 			ps := containerPorts
@@ -563,7 +1097,7 @@ func (a *app) updateContainerPorts(applier resources.Applier, ports []corev1.Ser
 	switch a.deploymentType {
 	case caas.DeploymentStateful:
 		ss := resources.NewStatefulSet(a.name, a.namespace, nil)
-		if err := ss.Get(context.Background(), a.client); err != nil {
+		if err := ss.Get(ctx, a.client); err != nil {
 			return errors.Trace(err)
 		}
 
@@ -571,7 +1105,7 @@ func (a *app) updateContainerPorts(applier resources.Applier, ports []corev1.Ser
 		applier.Apply(ss)
 	case caas.DeploymentStateless:
 		d := resources.NewDeployment(a.name, a.namespace, nil)
-		if err := d.Get(context.Background(), a.client); err != nil {
+		if err := d.Get(ctx, a.client); err != nil {
 			return errors.Trace(err)
 		}
 
@@ -579,7 +1113,7 @@ func (a *app) updateContainerPorts(applier resources.Applier, ports []corev1.Ser
 		applier.Apply(d)
 	case caas.DeploymentDaemon:
 		d := resources.NewDaemonSet(a.name, a.namespace, nil)
-		if err := d.Get(context.Background(), a.client); err != nil {
+		if err := d.Get(ctx, a.client); err != nil {
 			return errors.Trace(err)
 		}
 
@@ -591,33 +1125,95 @@ func (a *app) updateContainerPorts(applier resources.Applier, ports []corev1.Ser
 	return nil
 }
 
-func (a *app) getStatefulSet() (*resources.StatefulSet, error) {
+func (a *app) getStatefulSet(ctx context.Context) (*resources.StatefulSet, error) {
 	ss := resources.NewStatefulSet(a.name, a.namespace, nil)
-	if err := ss.Get(context.Background(), a.client); err != nil {
+	if err := ss.Get(ctx, a.client); err != nil {
 		return nil, err
 	}
 	return ss, nil
 }
 
-func (a *app) getDeployment() (*resources.Deployment, error) {
+func (a *app) getDeployment(ctx context.Context) (*resources.Deployment, error) {
 	ss := resources.NewDeployment(a.name, a.namespace, nil)
-	if err := ss.Get(context.Background(), a.client); err != nil {
+	if err := ss.Get(ctx, a.client); err != nil {
 		return nil, err
 	}
 	return ss, nil
 }
 
-func (a *app) getDaemonSet() (*resources.DaemonSet, error) {
+func (a *app) getDaemonSet(ctx context.Context) (*resources.DaemonSet, error) {
 	ss := resources.NewDaemonSet(a.name, a.namespace, nil)
-	if err := ss.Get(context.Background(), a.client); err != nil {
+	if err := ss.Get(ctx, a.client); err != nil {
 		return nil, err
 	}
 	return ss, nil
 }
 
-func (a *app) statefulSetExists() (exists bool, terminating bool, err error) {
+// immutableFieldsError returns an error explaining that the application's
+// workload resource can't be updated in place.
+func (a *app) immutableFieldsError(kind string, fields []string) error {
+	return errors.NewNotSupported(nil, fmt.Sprintf(
+		"cannot update immutable field(s) %s of %s %q: "+
+			"the application needs to be removed and deployed again for this change to take effect",
+		strings.Join(fields, ", "), kind, a.name,
+	))
+}
+
+// statefulSetImmutableFieldChanges returns the names of the immutable fields
+// which differ between the live and desired statefulset specs.
+func statefulSetImmutableFieldChanges(live, desired appsv1.StatefulSetSpec) []string {
+	var fields []string
+	if labelSelectorChanged(live.Selector, desired.Selector) {
+		fields = append(fields, "selector")
+	}
+	if live.ServiceName != desired.ServiceName {
+		fields = append(fields, "serviceName")
+	}
+	if volumeClaimTemplatesChanged(live.VolumeClaimTemplates, desired.VolumeClaimTemplates) {
+		fields = append(fields, "volumeClaimTemplates")
+	}
+	return fields
+}
+
+func labelSelectorChanged(live, desired *metav1.LabelSelector) bool {
+	if live == nil || desired == nil {
+		return live != desired
+	}
+	return !reflect.DeepEqual(live.MatchLabels, desired.MatchLabels) ||
+		!reflect.DeepEqual(live.MatchExpressions, desired.MatchExpressions)
+}
+
+// volumeClaimTemplatesChanged compares the parts of the volume claim templates
+// set by Juju, ignoring any fields defaulted by the API server.
+func volumeClaimTemplatesChanged(live, desired []corev1.PersistentVolumeClaim) bool {
+	if len(live) != len(desired) {
+		return true
+	}
+	liveByName := make(map[string]corev1.PersistentVolumeClaimSpec, len(live))
+	for _, pvc := range live {
+		liveByName[pvc.Name] = pvc.Spec
+	}
+	for _, pvc := range desired {
+		liveSpec, ok := liveByName[pvc.Name]
+		if !ok {
+			return true
+		}
+		if !reflect.DeepEqual(liveSpec.StorageClassName, pvc.Spec.StorageClassName) ||
+			!reflect.DeepEqual(liveSpec.AccessModes, pvc.Spec.AccessModes) {
+			return true
+		}
+		liveSize := liveSpec.Resources.Requests[corev1.ResourceStorage]
+		desiredSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if liveSize.Cmp(desiredSize) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *app) statefulSetExists(ctx context.Context) (exists bool, terminating bool, err error) {
 	ss := resources.NewStatefulSet(a.name, a.namespace, nil)
-	err = ss.Get(context.Background(), a.client)
+	err = ss.Get(ctx, a.client)
 	if errors.IsNotFound(err) {
 		return false, false, nil
 	} else if err != nil {
@@ -626,9 +1222,9 @@ func (a *app) statefulSetExists() (exists bool, terminating bool, err error) {
 	return true, ss.DeletionTimestamp != nil, nil
 }
 
-func (a *app) deploymentExists() (exists bool, terminating bool, err error) {
+func (a *app) deploymentExists(ctx context.Context) (exists bool, terminating bool, err error) {
 	ss := resources.NewDeployment(a.name, a.namespace, nil)
-	err = ss.Get(context.Background(), a.client)
+	err = ss.Get(ctx, a.client)
 	if errors.IsNotFound(err) {
 		return false, false, nil
 	} else if err != nil {
@@ -637,9 +1233,9 @@ func (a *app) deploymentExists() (exists bool, terminating bool, err error) {
 	return true, ss.DeletionTimestamp != nil, nil
 }
 
-func (a *app) daemonSetExists() (exists bool, terminating bool, err error) {
+func (a *app) daemonSetExists(ctx context.Context) (exists bool, terminating bool, err error) {
 	ss := resources.NewDaemonSet(a.name, a.namespace, nil)
-	err = ss.Get(context.Background(), a.client)
+	err = ss.Get(ctx, a.client)
 	if errors.IsNotFound(err) {
 		return false, false, nil
 	} else if err != nil {
@@ -648,9 +1244,9 @@ func (a *app) daemonSetExists() (exists bool, terminating bool, err error) {
 	return true, ss.DeletionTimestamp != nil, nil
 }
 
-func (a *app) secretExists() (exists bool, terminating bool, err error) {
+func (a *app) secretExists(ctx context.Context) (exists bool, terminating bool, err error) {
 	ss := resources.NewSecret(a.secretName(), a.namespace, nil)
-	err = ss.Get(context.Background(), a.client)
+	err = ss.Get(ctx, a.client)
 	if errors.IsNotFound(err) {
 		return false, false, nil
 	} else if err != nil {
@@ -659,9 +1255,9 @@ func (a *app) secretExists() (exists bool, terminating bool, err error) {
 	return true, ss.DeletionTimestamp != nil, nil
 }
 
-func (a *app) serviceExists() (exists bool, terminating bool, err error) {
+func (a *app) serviceExists(ctx context.Context) (exists bool, terminating bool, err error) {
 	ss := resources.NewService(a.name, a.namespace, nil)
-	err = ss.Get(context.Background(), a.client)
+	err = ss.Get(ctx, a.client)
 	if errors.IsNotFound(err) {
 		return false, false, nil
 	} else if err != nil {
@@ -671,50 +1267,127 @@ func (a *app) serviceExists() (exists bool, terminating bool, err error) {
 }
 
 // Delete deletes the specified application.
-func (a *app) Delete() error {
+func (a *app) Delete(ctx context.Context, opts caas.DeleteOptions) error {
 	logger.Debugf("deleting %s application", a.name)
 	applier := a.newApplier()
 	switch a.deploymentType {
 	case caas.DeploymentStateful:
 		applier.Delete(resources.NewStatefulSet(a.name, a.namespace, nil))
+		applier.Delete(resources.NewPodDisruptionBudget(a.name, a.namespace, nil))
 		applier.Delete(resources.NewService(headlessServiceName(a.name), a.namespace, nil))
 	case caas.DeploymentStateless:
 		applier.Delete(resources.NewDeployment(a.name, a.namespace, nil))
+		applier.Delete(resources.NewPodDisruptionBudget(a.name, a.namespace, nil))
+		if opts.DestroyStorage {
+			if err := a.deleteClaims(ctx, applier); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	case caas.DeploymentDaemon:
 		applier.Delete(resources.NewDaemonSet(a.name, a.namespace, nil))
+		if opts.DestroyStorage {
+			if err := a.deleteClaims(ctx, applier); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	default:
-		return errors.NotSupportedf("unknown deployment type")
+		handler, err := deploymentHandler(a.deploymentType)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := handler.Delete(ctx, a.workload()); err != nil {
+			return errors.Annotatef(err, "deleting %s workload", a.deploymentType)
+		}
 	}
 	applier.Delete(resources.NewService(a.name, a.namespace, nil))
 	applier.Delete(resources.NewSecret(a.secretName(), a.namespace, nil))
-	return applier.Run(context.Background(), a.client, false)
+	a.deleteServiceAccount(applier)
+	pullSecrets, err := a.listImagePullSecrets(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, name := range pullSecrets {
+		applier.Delete(resources.NewSecret(name, a.namespace, nil))
+	}
+	return applier.Run(ctx, a.client, false)
+}
+
+// deleteClaims deletes the persistent volume claims created for the
+// storage of a deployment or daemonset. The claims of a statefulset are
+// created from its volume claim templates and left to the statefulset's
+// retention semantics.
+func (a *app) deleteClaims(ctx context.Context, applier resources.Applier) error {
+	storageLabel := constants.LabelJujuStorageName
+	if a.legacyLabels {
+		storageLabel = constants.LegacyLabelStorageName
+	}
+	requirement, err := labels.NewRequirement(storageLabel, selection.Exists, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	pvcs, err := resources.ListPersistentVolumeClaims(ctx, a.client, a.namespace, metav1.ListOptions{
+		LabelSelector: labels.NewSelector().Add(*requirement).String(),
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, pvc := range pvcs {
+		// The application's claims are named after the storage, followed
+		// by the storage prefix of the workload.
+		prefix := a.volumeName(pvc.Labels[storageLabel]) + "-"
+		uniqID := strings.TrimPrefix(pvc.Name, prefix)
+		if !strings.HasPrefix(pvc.Name, prefix) || uniqID == "" || strings.Contains(uniqID, "-") {
+			continue
+		}
+		logger.Debugf("deleting persistent volume claim %q of %s", pvc.Name, a.name)
+		applier.Delete(resources.NewPersistentVolumeClaim(pvc.Name, a.namespace, nil))
+	}
+	return nil
 }
 
 // Watch returns a watcher which notifies when there
 // are changes to the application of the specified application.
+// If a resync period is configured, the watcher is also notified
+// periodically as a backstop against missed events.
 func (a *app) Watch() (watcher.NotifyWatcher, error) {
-	factory := informers.NewSharedInformerFactoryWithOptions(a.client, 0,
+	informer, err := a.workloadInformer()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return a.newWatcher(informer, a.name, a.clock)
+}
+
+// WatchDeletion returns a watcher which notifies when the application's
+// workload starts terminating or is removed.
+func (a *app) WatchDeletion() (watcher.NotifyWatcher, error) {
+	informer, err := a.workloadInformer()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return a.newWatcher(&deletionInformer{informer}, a.name, a.clock)
+}
+
+func (a *app) workloadInformer() (cache.SharedIndexInformer, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(a.client, a.resyncPeriod,
 		informers.WithNamespace(a.namespace),
 		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
 			o.FieldSelector = a.fieldSelector()
 		}),
 	)
-	var informer cache.SharedIndexInformer
 	switch a.deploymentType {
 	case caas.DeploymentStateful:
-		informer = factory.Apps().V1().StatefulSets().Informer()
+		return factory.Apps().V1().StatefulSets().Informer(), nil
 	case caas.DeploymentStateless:
-		informer = factory.Apps().V1().Deployments().Informer()
+		return factory.Apps().V1().Deployments().Informer(), nil
 	case caas.DeploymentDaemon:
-		informer = factory.Apps().V1().DaemonSets().Informer()
+		return factory.Apps().V1().DaemonSets().Informer(), nil
 	default:
 		return nil, errors.NotSupportedf("unknown deployment type")
 	}
-	return a.newWatcher(informer, a.name, a.clock)
 }
 
 func (a *app) WatchReplicas() (watcher.NotifyWatcher, error) {
-	factory := informers.NewSharedInformerFactoryWithOptions(a.client, 0,
+	factory := informers.NewSharedInformerFactoryWithOptions(a.client, a.resyncPeriod,
 		informers.WithNamespace(a.namespace),
 		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
 			o.LabelSelector = a.labelSelector()
@@ -723,22 +1396,100 @@ func (a *app) WatchReplicas() (watcher.NotifyWatcher, error) {
 	return a.newWatcher(factory.Core().V1().Pods().Informer(), a.name, a.clock)
 }
 
-func (a *app) State() (caas.ApplicationState, error) {
-	state := caas.ApplicationState{}
-	switch a.deploymentType {
-	case caas.DeploymentStateful:
-		ss := resources.NewStatefulSet(a.name, a.namespace, nil)
-		err := ss.Get(context.Background(), a.client)
-		if err != nil {
-			return caas.ApplicationState{}, errors.Trace(err)
-		}
-		if ss.Spec.Replicas == nil {
-			return caas.ApplicationState{}, errors.Errorf("missing replicas")
-		}
+// WatchUnits returns a watcher which notifies of the names of the
+// application's pods as they're added and removed.
+func (a *app) WatchUnits(ctx context.Context) (watcher.StringsWatcher, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(a.client, a.resyncPeriod,
+		informers.WithNamespace(a.namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = a.labelSelector()
+		}),
+	)
+	podInformer := factory.Core().V1().Pods()
+	informer := &runningInformer{
+		SharedIndexInformer: podInformer.Informer(),
+		stop:                make(chan struct{}),
+	}
+	// The initial units are read from the informer's own store once it
+	// has synced, so no pod can be missed between listing and watching.
+	go informer.SharedIndexInformer.Run(informer.stop)
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		close(informer.stop)
+		return nil, errors.Annotate(ctx.Err(), "waiting for pod cache to sync")
+	}
+	pods, err := podInformer.Lister().Pods(a.namespace).List(labels.Everything())
+	if err != nil {
+		close(informer.stop)
+		return nil, errors.Trace(err)
+	}
+	// known holds the pods which have been notified, so that the informer
+	// adding the existing pods doesn't notify them again.
+	known := set.NewStrings()
+	for _, pod := range pods {
+		known.Add(pod.Name)
+	}
+
+	filterEvent := func(evt k8swatcher.WatchEvent, obj interface{}) (string, bool) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			return "", false
+		}
+		switch evt {
+		case k8swatcher.WatchEventAdd:
+			if known.Contains(pod.Name) {
+				return "", false
+			}
+			known.Add(pod.Name)
+			return pod.Name, true
+		case k8swatcher.WatchEventDelete:
+			if !known.Contains(pod.Name) {
+				return "", false
+			}
+			known.Remove(pod.Name)
+			return pod.Name, true
+		}
+		return "", false
+	}
+	w, err := a.newStringsWatcher(informer, a.name, a.clock, known.SortedValues(), filterEvent)
+	if err != nil {
+		close(informer.stop)
+		return nil, errors.Trace(err)
+	}
+	return w, nil
+}
+
+// runningInformer is an informer which has already been started. Running
+// it again waits for the stop channel and then stops the informer.
+type runningInformer struct {
+	cache.SharedIndexInformer
+	stop chan struct{}
+}
+
+// Run is part of the cache.SharedIndexInformer interface.
+func (i *runningInformer) Run(stopCh <-chan struct{}) {
+	<-stopCh
+	close(i.stop)
+}
+
+func (a *app) State(ctx context.Context) (caas.ApplicationState, error) {
+	state := caas.ApplicationState{}
+	switch a.deploymentType {
+	case caas.DeploymentStateful:
+		ss := resources.NewStatefulSet(a.name, a.namespace, nil)
+		err := ss.Get(ctx, a.client)
+		if err != nil {
+			return caas.ApplicationState{}, errors.Trace(err)
+		}
+		if ss.Spec.Replicas == nil {
+			return caas.ApplicationState{}, errors.Errorf("missing replicas")
+		}
 		state.DesiredReplicas = int(*ss.Spec.Replicas)
 	case caas.DeploymentStateless:
 		d := resources.NewDeployment(a.name, a.namespace, nil)
-		err := d.Get(context.Background(), a.client)
+		err := d.Get(ctx, a.client)
 		if err != nil {
 			return caas.ApplicationState{}, errors.Trace(err)
 		}
@@ -748,17 +1499,26 @@ func (a *app) State() (caas.ApplicationState, error) {
 		state.DesiredReplicas = int(*d.Spec.Replicas)
 	case caas.DeploymentDaemon:
 		d := resources.NewDaemonSet(a.name, a.namespace, nil)
-		err := d.Get(context.Background(), a.client)
+		err := d.Get(ctx, a.client)
 		if err != nil {
 			return caas.ApplicationState{}, errors.Trace(err)
 		}
 		state.DesiredReplicas = int(d.Status.DesiredNumberScheduled)
 	default:
-		return caas.ApplicationState{}, errors.NotSupportedf("unknown deployment type")
+		handler, err := deploymentHandler(a.deploymentType)
+		if err != nil {
+			return caas.ApplicationState{}, errors.Trace(err)
+		}
+		replicas, err := handler.DesiredReplicas(ctx, a.workload())
+		if err != nil {
+			return caas.ApplicationState{}, errors.Trace(err)
+		}
+		state.DesiredReplicas = replicas
 	}
+	now := a.clock.Now()
 	next := ""
 	for {
-		res, err := a.client.CoreV1().Pods(a.namespace).List(context.Background(), metav1.ListOptions{
+		res, err := a.client.CoreV1().Pods(a.namespace).List(ctx, metav1.ListOptions{
 			LabelSelector: a.labelSelector(),
 			Continue:      next,
 		})
@@ -767,6 +1527,14 @@ func (a *app) State() (caas.ApplicationState, error) {
 		}
 		for _, pod := range res.Items {
 			state.Replicas = append(state.Replicas, pod.Name)
+			if k8spod.IsPodRunning(&pod) {
+				state.ReadyReplicas++
+			}
+			fsInfos, err := a.podFilesystemInfo(ctx, &pod, now)
+			if err != nil {
+				return caas.ApplicationState{}, errors.Trace(err)
+			}
+			state.Filesystems = append(state.Filesystems, fsInfos...)
 		}
 		if res.RemainingItemCount == nil || *res.RemainingItemCount == 0 {
 			break
@@ -778,10 +1546,14 @@ func (a *app) State() (caas.ApplicationState, error) {
 }
 
 // Units of the application fetched from kubernetes by matching pod labels.
-func (a *app) Units() ([]caas.Unit, error) {
-	ctx := context.Background()
+//
+// A pod whose status or filesystems can't be computed doesn't stop the
+// others being listed: its unit is returned with an unknown status or
+// without filesystem info, along with a *caas.UnitsError.
+func (a *app) Units(ctx context.Context) ([]caas.Unit, error) {
 	now := a.clock.Now()
 	var units []caas.Unit
+	unitErrors := make(map[string]error)
 	pods, err := resources.ListPods(ctx, a.client, a.namespace, metav1.ListOptions{
 		LabelSelector: a.labelSelector(),
 	})
@@ -798,7 +1570,12 @@ func (a *app) Units() ([]caas.Unit, error) {
 		terminated := p.DeletionTimestamp != nil
 		statusMessage, unitStatus, since, err := p.ComputeStatus(ctx, a.client, now)
 		if err != nil {
-			return nil, errors.Trace(err)
+			unitErrors[p.Name] = errors.Annotate(err, "computing status")
+			statusMessage, unitStatus, since = "", status.Unknown, now
+		}
+		var restartCount int
+		for _, cs := range p.Status.ContainerStatuses {
+			restartCount += int(cs.RestartCount)
 		}
 		unitInfo := caas.Unit{
 			Id:       p.Name,
@@ -811,46 +1588,69 @@ func (a *app) Units() ([]caas.Unit, error) {
 				Message: statusMessage,
 				Since:   &since,
 			},
+			RestartCount: restartCount,
+			QoSClass:     string(p.Status.QOSClass),
 		}
 
-		volumesByName := make(map[string]corev1.Volume)
-		for _, pv := range p.Spec.Volumes {
-			volumesByName[pv.Name] = pv
-		}
-
-		// Gather info about how filesystems are attached/mounted to the pod.
-		// The mount name represents the filesystem tag name used by Juju.
-		for _, volMount := range p.Spec.Containers[0].VolumeMounts {
-			if volMount.Name == charmVolumeName {
-				continue
-			}
-			vol, ok := volumesByName[volMount.Name]
-			if !ok {
-				logger.Warningf("volume for volume mount %q not found", volMount.Name)
-				continue
-			}
-			fsInfo, err := storage.FilesystemInfo(ctx, a.client, a.namespace, vol, volMount, now)
-			if err != nil {
-				return nil, errors.Annotatef(err, "finding filesystem info for %v", volMount.Name)
-			}
-			if fsInfo == nil {
-				continue
-			}
-			if fsInfo.StorageName == "" {
-				if valid := constants.LegacyPVNameRegexp.MatchString(volMount.Name); valid {
-					fsInfo.StorageName = constants.LegacyPVNameRegexp.ReplaceAllString(volMount.Name, "$storageName")
-				} else if valid := constants.PVNameRegexp.MatchString(volMount.Name); valid {
-					fsInfo.StorageName = constants.PVNameRegexp.ReplaceAllString(volMount.Name, "$storageName")
-				}
+		fsInfos, err := a.podFilesystemInfo(ctx, &p.Pod, now)
+		if err != nil {
+			if _, ok := unitErrors[p.Name]; !ok {
+				unitErrors[p.Name] = errors.Annotate(err, "getting filesystem info")
 			}
-			logger.Debugf("filesystem info for %v: %+v", volMount.Name, *fsInfo)
-			unitInfo.FilesystemInfo = append(unitInfo.FilesystemInfo, *fsInfo)
 		}
+		unitInfo.FilesystemInfo = fsInfos
 		units = append(units, unitInfo)
 	}
+	if len(unitErrors) > 0 {
+		return units, &caas.UnitsError{Errors: unitErrors}
+	}
 	return units, nil
 }
 
+// podFilesystemInfo returns information about the Juju managed filesystems
+// mounted into the specified pod. Filesystems whose persistent volume claim
+// is still pending, or whose persistent volume doesn't exist yet, are skipped.
+func (a *app) podFilesystemInfo(ctx context.Context, p *corev1.Pod, now time.Time) ([]caas.FilesystemInfo, error) {
+	if len(p.Spec.Containers) == 0 {
+		return nil, nil
+	}
+	var result []caas.FilesystemInfo
+	volumesByName := make(map[string]corev1.Volume)
+	for _, pv := range p.Spec.Volumes {
+		volumesByName[pv.Name] = pv
+	}
+
+	// Gather info about how filesystems are attached/mounted to the pod.
+	// The mount name represents the filesystem tag name used by Juju.
+	for _, volMount := range p.Spec.Containers[0].VolumeMounts {
+		if volMount.Name == charmVolumeName {
+			continue
+		}
+		vol, ok := volumesByName[volMount.Name]
+		if !ok {
+			logger.Warningf("volume for volume mount %q not found", volMount.Name)
+			continue
+		}
+		fsInfo, err := storage.FilesystemInfo(ctx, a.client, a.namespace, vol, volMount, now)
+		if err != nil {
+			return nil, errors.Annotatef(err, "finding filesystem info for %v", volMount.Name)
+		}
+		if fsInfo == nil {
+			continue
+		}
+		if fsInfo.StorageName == "" {
+			if valid := constants.LegacyPVNameRegexp.MatchString(volMount.Name); valid {
+				fsInfo.StorageName = constants.LegacyPVNameRegexp.ReplaceAllString(volMount.Name, "$storageName")
+			} else if valid := constants.PVNameRegexp.MatchString(volMount.Name); valid {
+				fsInfo.StorageName = constants.PVNameRegexp.ReplaceAllString(volMount.Name, "$storageName")
+			}
+		}
+		logger.Debugf("filesystem info for %v: %+v", volMount.Name, *fsInfo)
+		result = append(result, *fsInfo)
+	}
+	return result, nil
+}
+
 // applicationPodSpec returns a PodSpec for the application pod
 // of the specified application.
 func (a *app) applicationPodSpec(config caas.ApplicationConfig) (*corev1.PodSpec, error) {
@@ -861,6 +1661,17 @@ func (a *app) applicationPodSpec(config caas.ApplicationConfig) (*corev1.PodSpec
 	containerNames := []string(nil)
 	containers := []caas.ContainerConfig(nil)
 	for _, v := range config.Containers {
+		if v.Name == unitContainerName || v.Name == initContainerName {
+			return nil, errors.NewNotValid(nil, fmt.Sprintf("container name %q is reserved", v.Name))
+		}
+		if errs := validation.IsDNS1123Label(v.Name); len(errs) != 0 {
+			return nil, errors.NotValidf("container name %q", v.Name)
+		}
+		for _, name := range containerNames {
+			if name == v.Name {
+				return nil, errors.NotValidf("duplicate container name %q", v.Name)
+			}
+		}
 		containerNames = append(containerNames, v.Name)
 		containers = append(containers, v)
 	}
@@ -890,9 +1701,37 @@ func (a *app) applicationPodSpec(config caas.ApplicationConfig) (*corev1.PodSpec
 		resourceRequests[corev1.ResourceMemory] = *resource.NewQuantity(int64(bytes), resource.BinarySI)
 	}
 
+	charmPullPolicy, err := imagePullPolicy(config.CharmImagePullPolicy)
+	if err != nil {
+		return nil, errors.Annotate(err, "charm image")
+	}
+	probeTiming := agentProbeTimingFromConfig(config.ProbeTiming)
+	probePort, err := agentProbePort(config.AgentProbePort)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	charmVolumeSource, err := charmEmptyDir(config.CharmVolume)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	gracePeriod, err := terminationGracePeriodSeconds(config.TerminationGracePeriod)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	podSecurityContext, charmSecurityContext, err := securityContexts(config.SecurityContext)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The init container populates the charm and data dirs, so it runs as
+	// the charm's user when configured so that they remain accessible.
+	var initSecurityContext *corev1.SecurityContext
+	if config.SecurityContext != nil {
+		initSecurityContext = charmSecurityContext.DeepCopy()
+	}
+
 	containerSpecs := []corev1.Container{{
 		Name:            unitContainerName,
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		ImagePullPolicy: charmPullPolicy,
 		Image:           config.CharmBaseImage.RegistryPath,
 		WorkingDir:      jujuDataDir,
 		Command:         []string{"/charm/bin/containeragent"},
@@ -909,49 +1748,13 @@ func (a *app) applicationPodSpec(config caas.ApplicationConfig) (*corev1.PodSpec
 			},
 			{
 				Name:  constants.EnvAgentHTTPProbePort,
-				Value: constants.AgentHTTPProbePort,
+				Value: probePort,
 			},
 		},
-		SecurityContext: &corev1.SecurityContext{
-			RunAsUser:  int64Ptr(0),
-			RunAsGroup: int64Ptr(0),
-		},
-		LivenessProbe: &corev1.Probe{
-			Handler: corev1.Handler{
-				HTTPGet: &corev1.HTTPGetAction{
-					Path: constants.AgentHTTPPathLiveness,
-					Port: intstr.Parse(constants.AgentHTTPProbePort),
-				},
-			},
-			InitialDelaySeconds: agentProbeInitialDelay,
-			PeriodSeconds:       agentProbePeriod,
-			SuccessThreshold:    agentProbeSuccess,
-			FailureThreshold:    agentProbeFailure,
-		},
-		ReadinessProbe: &corev1.Probe{
-			Handler: corev1.Handler{
-				HTTPGet: &corev1.HTTPGetAction{
-					Path: constants.AgentHTTPPathReadiness,
-					Port: intstr.Parse(constants.AgentHTTPProbePort),
-				},
-			},
-			InitialDelaySeconds: agentProbeInitialDelay,
-			PeriodSeconds:       agentProbePeriod,
-			SuccessThreshold:    agentProbeSuccess,
-			FailureThreshold:    agentProbeFailure,
-		},
-		StartupProbe: &corev1.Probe{
-			Handler: corev1.Handler{
-				HTTPGet: &corev1.HTTPGetAction{
-					Path: constants.AgentHTTPPathStartup,
-					Port: intstr.Parse(constants.AgentHTTPProbePort),
-				},
-			},
-			InitialDelaySeconds: agentProbeInitialDelay,
-			PeriodSeconds:       agentProbePeriod,
-			SuccessThreshold:    agentProbeSuccess,
-			FailureThreshold:    agentProbeFailure,
-		},
+		SecurityContext: charmSecurityContext,
+		LivenessProbe:   agentProbe(constants.AgentHTTPPathLiveness, probePort, probeTiming, probeTiming.failure),
+		ReadinessProbe:  agentProbe(constants.AgentHTTPPathReadiness, probePort, probeTiming, probeTiming.failure),
+		StartupProbe:    agentProbe(constants.AgentHTTPPathStartup, probePort, probeTiming, probeTiming.startupFailure),
 		VolumeMounts: []corev1.VolumeMount{
 			{
 				Name:      charmVolumeName,
@@ -974,25 +1777,64 @@ func (a *app) applicationPodSpec(config caas.ApplicationConfig) (*corev1.PodSpec
 			Requests: resourceRequests,
 		},
 	}}
+	if config.SecurityContext != nil && config.SecurityContext.ReadOnlyRootFilesystem {
+		// Give the charm somewhere writable for temporary files.
+		containerSpecs[0].VolumeMounts = append(containerSpecs[0].VolumeMounts, corev1.VolumeMount{
+			Name:      charmVolumeName,
+			MountPath: "/tmp",
+			SubPath:   "charm/tmp",
+		})
+	}
 
 	for _, v := range containers {
+		containerResources, err := containerResourceRequirements(v)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pullPolicy, err := imagePullPolicy(v.ImagePullPolicy)
+		if err != nil {
+			return nil, errors.Annotatef(err, "container %q", v.Name)
+		}
+		livenessProbe, err := containerProbe(v.Probes.Liveness)
+		if err != nil {
+			return nil, errors.Annotatef(err, "container %q liveness", v.Name)
+		}
+		readinessProbe, err := containerProbe(v.Probes.Readiness)
+		if err != nil {
+			return nil, errors.Annotatef(err, "container %q readiness", v.Name)
+		}
+		startupProbe, err := containerProbe(v.Probes.Startup)
+		if err != nil {
+			return nil, errors.Annotatef(err, "container %q startup", v.Name)
+		}
+		lifecycle, err := containerLifecycle(v.Lifecycle)
+		if err != nil {
+			return nil, errors.Annotatef(err, "container %q", v.Name)
+		}
+		command, args := workloadEntrypoint(v)
+		env, err := containerEnv(v.Name, v.Env, []corev1.EnvVar{{
+			Name:  "JUJU_CONTAINER_NAME",
+			Value: v.Name,
+		}, {
+			Name:  "PEBBLE_SOCKET",
+			Value: "/charm/container/pebble.socket",
+		}})
+		if err != nil {
+			return nil, errors.Annotatef(err, "container %q", v.Name)
+		}
+		envFrom, err := containerEnvFrom(v.EnvFrom)
+		if err != nil {
+			return nil, errors.Annotatef(err, "container %q", v.Name)
+		}
 		container := corev1.Container{
 			Name:            v.Name,
-			ImagePullPolicy: corev1.PullIfNotPresent,
+			ImagePullPolicy: pullPolicy,
 			Image:           v.Image.RegistryPath,
-			Command:         []string{"/charm/bin/pebble"},
-			Args: []string{
-				"run",
-				"--create-dirs",
-				"--hold",
-			},
-			Env: []corev1.EnvVar{{
-				Name:  "JUJU_CONTAINER_NAME",
-				Value: v.Name,
-			}, {
-				Name:  "PEBBLE_SOCKET",
-				Value: "/charm/container/pebble.socket",
-			}},
+			Command:         command,
+			Args:            args,
+			WorkingDir:      v.WorkingDir,
+			Env:             env,
+			EnvFrom:         envFrom,
 			// Run Pebble as root (because it's a service manager).
 			SecurityContext: &corev1.SecurityContext{
 				RunAsUser:  int64Ptr(0),
@@ -1011,6 +1853,11 @@ func (a *app) applicationPodSpec(config caas.ApplicationConfig) (*corev1.PodSpec
 					SubPath:   fmt.Sprintf("charm/containers/%s", v.Name),
 				},
 			},
+			LivenessProbe:  livenessProbe,
+			ReadinessProbe: readinessProbe,
+			StartupProbe:   startupProbe,
+			Lifecycle:      lifecycle,
+			Resources:      containerResources,
 		}
 		containerSpecs = append(containerSpecs, container)
 	}
@@ -1030,22 +1877,68 @@ func (a *app) applicationPodSpec(config caas.ApplicationConfig) (*corev1.PodSpec
 		case arch.S390X:
 			cpuArch = "s390x"
 		default:
-			return nil, errors.NotSupportedf("architecture %q", cpuArch)
+			return nil, errors.NewNotSupported(nil, fmt.Sprintf(
+				"architecture %q not supported, expected one of amd64, arm64, ppc64le, s390x",
+				*config.Constraints.Arch,
+			))
 		}
 		nodeSelector = map[string]string{"kubernetes.io/arch": cpuArch}
 	}
+	affinity, err := nodeAffinity(config.Constraints)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tolerations, err := podTolerations(config.Tolerations)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	spreadConstraints, err := a.topologySpreadConstraints(config.TopologySpreadConstraints)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dnsPolicy, dnsConfig, err := podDNS(config.DNS)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	hostAliases, err := podHostAliases(config.HostAliases)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if config.PriorityClassName != "" {
+		if errs := validation.IsDNS1123Subdomain(config.PriorityClassName); len(errs) > 0 {
+			return nil, errors.NotValidf("priority class name %q", config.PriorityClassName)
+		}
+	}
 
 	automountToken := false
+	var serviceAccountName string
+	if config.ServiceAccount != nil {
+		automountToken = true
+		serviceAccountName = a.name
+	}
 	return &corev1.PodSpec{
-		AutomountServiceAccountToken: &automountToken,
-		NodeSelector:                 nodeSelector,
+		AutomountServiceAccountToken:  &automountToken,
+		ServiceAccountName:            serviceAccountName,
+		TerminationGracePeriodSeconds: gracePeriod,
+		NodeSelector:                  nodeSelector,
+		Affinity:                      affinity,
+		Tolerations:                   tolerations,
+		TopologySpreadConstraints:     spreadConstraints,
+		DNSPolicy:                     dnsPolicy,
+		DNSConfig:                     dnsConfig,
+		HostAliases:                   hostAliases,
+		PriorityClassName:             config.PriorityClassName,
+		SecurityContext:               podSecurityContext,
+		ImagePullSecrets:              a.imagePullSecrets(config),
 		InitContainers: []corev1.Container{{
-			Name:            "charm-init",
-			ImagePullPolicy: corev1.PullIfNotPresent,
+			Name:            initContainerName,
+			ImagePullPolicy: charmPullPolicy,
 			Image:           config.AgentImagePath,
 			WorkingDir:      jujuDataDir,
 			Command:         []string{"/opt/containeragent"},
 			Args:            []string{"init", "--data-dir", jujuDataDir, "--bin-dir", "/charm/bin"},
+			SecurityContext: initSecurityContext,
 			Env: []corev1.EnvVar{
 				{
 					Name:  "JUJU_CONTAINER_NAMES",
@@ -1101,13 +1994,635 @@ func (a *app) applicationPodSpec(config caas.ApplicationConfig) (*corev1.PodSpec
 			{
 				Name: charmVolumeName,
 				VolumeSource: corev1.VolumeSource{
-					EmptyDir: &corev1.EmptyDirVolumeSource{},
+					EmptyDir: charmVolumeSource,
 				},
 			},
 		},
 	}, nil
 }
 
+// charmEmptyDir returns the source of the charm volume, which is backed by
+// the node's disk unless configured otherwise.
+func charmEmptyDir(config *caas.CharmVolume) (*corev1.EmptyDirVolumeSource, error) {
+	source := &corev1.EmptyDirVolumeSource{}
+	if config == nil {
+		return source, nil
+	}
+	switch medium := corev1.StorageMedium(config.Medium); medium {
+	case corev1.StorageMediumDefault, corev1.StorageMediumMemory:
+		source.Medium = medium
+	default:
+		return nil, errors.NotValidf("charm volume medium %q", config.Medium)
+	}
+	if config.SizeLimit != "" {
+		q, err := resource.ParseQuantity(config.SizeLimit)
+		if err != nil {
+			return nil, errors.NotValidf("charm volume size limit %q", config.SizeLimit)
+		}
+		source.SizeLimit = &q
+	}
+	return source, nil
+}
+
+// terminationGracePeriodSeconds returns the pod's termination grace period,
+// rounded up to whole seconds so that the pod gets at least the period
+// asked for.
+func terminationGracePeriodSeconds(period *time.Duration) (*int64, error) {
+	if period == nil {
+		return nil, nil
+	}
+	if *period < 0 {
+		return nil, errors.NotValidf("negative termination grace period %v", *period)
+	}
+	seconds := int64((*period + time.Second - 1) / time.Second)
+	return &seconds, nil
+}
+
+// securityContexts returns the security contexts of the application pod and
+// its charm container. The charm container runs as root unless configured
+// otherwise; the pod security context is only set when configured. When the
+// charm runs as a non-root user without an explicit fsGroup, the pod's
+// volumes are owned by the charm's group (or its UID if the group is root).
+func securityContexts(config *caas.SecurityContext) (*corev1.PodSecurityContext, *corev1.SecurityContext, error) {
+	charmContext := &corev1.SecurityContext{
+		RunAsUser:  int64Ptr(0),
+		RunAsGroup: int64Ptr(0),
+	}
+	if config == nil {
+		return nil, charmContext, nil
+	}
+	for _, id := range []*int64{config.RunAsUser, config.RunAsGroup, config.FSGroup} {
+		if id != nil && *id < 0 {
+			return nil, nil, errors.NotValidf("security context ID %d", *id)
+		}
+	}
+
+	podContext := &corev1.PodSecurityContext{
+		RunAsUser:  config.RunAsUser,
+		RunAsGroup: config.RunAsGroup,
+		FSGroup:    config.FSGroup,
+	}
+	if config.RunAsUser != nil {
+		charmContext.RunAsUser = int64Ptr(*config.RunAsUser)
+	}
+	if config.RunAsGroup != nil {
+		charmContext.RunAsGroup = int64Ptr(*config.RunAsGroup)
+	}
+	if config.ReadOnlyRootFilesystem {
+		charmContext.ReadOnlyRootFilesystem = boolPtr(true)
+		charmContext.AllowPrivilegeEscalation = boolPtr(false)
+		charmContext.Capabilities = &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		}
+	}
+	if *charmContext.RunAsUser != 0 {
+		// Only the charm is checked to run as non-root, as Pebble in
+		// the workload containers still runs as root.
+		charmContext.RunAsNonRoot = boolPtr(true)
+		if podContext.FSGroup == nil {
+			// Make the pod's volumes writable by the non-root charm.
+			fsGroup := *charmContext.RunAsGroup
+			if fsGroup == 0 {
+				fsGroup = *charmContext.RunAsUser
+			}
+			podContext.FSGroup = int64Ptr(fsGroup)
+		}
+	}
+	return podContext, charmContext, nil
+}
+
+// nodeAffinity returns the node affinity for the application pods derived
+// from the tags and zones constraints. Tags are of the form "key=value" and
+// require a node label to have one of the "|" separated values, or, if the
+// key is prefixed with "^", to have none of them. The affinity is applied in
+// addition to any architecture node selector.
+func nodeAffinity(cons constraints.Value) (*corev1.Affinity, error) {
+	var term corev1.NodeSelectorTerm
+	if cons.Tags != nil {
+		affinityTags := make(map[string][]string)
+		antiAffinityTags := make(map[string][]string)
+		for _, labelPair := range *cons.Tags {
+			parts := strings.Split(labelPair, "=")
+			key := strings.TrimSpace(parts[0])
+			if len(parts) != 2 || key == "" || key == "^" {
+				return nil, errors.NotValidf("node affinity tag %q", labelPair)
+			}
+			var values []string
+			for _, v := range strings.Split(parts[1], "|") {
+				values = append(values, strings.TrimSpace(v))
+			}
+			if strings.HasPrefix(key, "^") {
+				antiAffinityTags[key[1:]] = values
+			} else {
+				affinityTags[key] = values
+			}
+		}
+		addRequirements := func(tags map[string][]string, op corev1.NodeSelectorOperator) {
+			// Sort for stable ordering.
+			var keys []string
+			for k := range tags {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+					Key:      k,
+					Operator: op,
+					Values:   tags[k],
+				})
+			}
+		}
+		addRequirements(affinityTags, corev1.NodeSelectorOpIn)
+		addRequirements(antiAffinityTags, corev1.NodeSelectorOpNotIn)
+	}
+	if cons.HasZones() {
+		term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      corev1.LabelZoneFailureDomain,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   *cons.Zones,
+		})
+	}
+	if len(term.MatchExpressions) == 0 {
+		return nil, nil
+	}
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{term},
+			},
+		},
+	}, nil
+}
+
+// podTolerations returns the k8s tolerations for the specified tolerations.
+func podTolerations(in []caas.Toleration) ([]corev1.Toleration, error) {
+	var out []corev1.Toleration
+	for _, t := range in {
+		toleration := corev1.Toleration{
+			Key:      t.Key,
+			Operator: corev1.TolerationOperator(t.Operator),
+			Value:    t.Value,
+			Effect:   corev1.TaintEffect(t.Effect),
+		}
+		switch toleration.Operator {
+		case "", corev1.TolerationOpEqual, corev1.TolerationOpExists:
+		default:
+			return nil, errors.NotValidf("toleration operator %q", t.Operator)
+		}
+		switch toleration.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return nil, errors.NotValidf("toleration effect %q", t.Effect)
+		}
+		out = append(out, toleration)
+	}
+	return out, nil
+}
+
+// topologySpreadConstraints returns the constraints spreading the
+// application's pods across the domains of each topology.
+func (a *app) topologySpreadConstraints(in []caas.TopologySpreadConstraint) ([]corev1.TopologySpreadConstraint, error) {
+	var out []corev1.TopologySpreadConstraint
+	for _, c := range in {
+		if c.TopologyKey == "" {
+			return nil, errors.NotValidf("empty topology spread constraint key")
+		}
+		if c.MaxSkew < 1 {
+			return nil, errors.NotValidf("topology spread constraint max skew %d for %q", c.MaxSkew, c.TopologyKey)
+		}
+		whenUnsatisfiable := corev1.UnsatisfiableConstraintAction(c.WhenUnsatisfiable)
+		switch whenUnsatisfiable {
+		case "":
+			whenUnsatisfiable = corev1.DoNotSchedule
+		case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+		default:
+			return nil, errors.NotValidf("topology spread constraint action %q", c.WhenUnsatisfiable)
+		}
+		out = append(out, corev1.TopologySpreadConstraint{
+			MaxSkew:           c.MaxSkew,
+			TopologyKey:       c.TopologyKey,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: a.selectorLabels(),
+			},
+		})
+	}
+	return out, nil
+}
+
+// priorityClassExists reports whether the named priority class exists in
+// the cluster.
+func (a *app) priorityClassExists(ctx context.Context, name string) (bool, error) {
+	_, err := a.client.SchedulingV1().PriorityClasses().Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Annotatef(err, "getting priority class %q", name)
+	}
+	return true, nil
+}
+
+// podDNS returns the DNS policy and config of the application's pods. Both
+// are left unset without any configuration, so the pods get the Kubernetes
+// default "ClusterFirst" policy.
+func podDNS(config *caas.DNSConfig) (corev1.DNSPolicy, *corev1.PodDNSConfig, error) {
+	if config == nil {
+		return "", nil, nil
+	}
+	policy := corev1.DNSPolicy(config.Policy)
+	switch policy {
+	case "":
+		policy = corev1.DNSClusterFirst
+	case corev1.DNSClusterFirst, corev1.DNSClusterFirstWithHostNet, corev1.DNSDefault, corev1.DNSNone:
+	default:
+		return "", nil, errors.NotValidf("DNS policy %q", config.Policy)
+	}
+	resolverConfigured := len(config.Nameservers) > 0 || len(config.Searches) > 0 || len(config.Options) > 0
+	if policy != corev1.DNSNone {
+		if resolverConfigured {
+			return "", nil, errors.NotValidf("DNS resolver config with %q policy", policy)
+		}
+		return policy, nil, nil
+	}
+	if len(config.Nameservers) == 0 {
+		return "", nil, errors.NotValidf("%q DNS policy without nameservers", corev1.DNSNone)
+	}
+	dnsConfig := &corev1.PodDNSConfig{
+		Nameservers: config.Nameservers,
+		Searches:    config.Searches,
+	}
+	for _, opt := range config.Options {
+		if opt.Name == "" {
+			return "", nil, errors.NotValidf("DNS option with empty name")
+		}
+		option := corev1.PodDNSConfigOption{Name: opt.Name}
+		if opt.Value != "" {
+			option.Value = strPtr(opt.Value)
+		}
+		dnsConfig.Options = append(dnsConfig.Options, option)
+	}
+	return policy, dnsConfig, nil
+}
+
+// podHostAliases returns the /etc/hosts entries for the application's pods.
+func podHostAliases(in []caas.HostAlias) ([]corev1.HostAlias, error) {
+	var out []corev1.HostAlias
+	for _, alias := range in {
+		if net.ParseIP(alias.IP) == nil {
+			return nil, errors.NotValidf("host alias IP %q", alias.IP)
+		}
+		if len(alias.Hostnames) == 0 {
+			return nil, errors.NotValidf("host alias for %q without hostnames", alias.IP)
+		}
+		for _, hostname := range alias.Hostnames {
+			if errs := validation.IsDNS1123Subdomain(hostname); len(errs) != 0 {
+				return nil, errors.NotValidf("host alias hostname %q", hostname)
+			}
+		}
+		out = append(out, corev1.HostAlias{
+			IP:        alias.IP,
+			Hostnames: alias.Hostnames,
+		})
+	}
+	return out, nil
+}
+
+// imagePullPolicy returns the k8s pull policy for the specified policy,
+// defaulting to IfNotPresent.
+func imagePullPolicy(policy string) (corev1.PullPolicy, error) {
+	switch p := corev1.PullPolicy(policy); p {
+	case "":
+		return corev1.PullIfNotPresent, nil
+	case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return p, nil
+	default:
+		return "", errors.NotValidf("image pull policy %q", policy)
+	}
+}
+
+// agentProbeTiming holds the probe settings, in the units used by
+// corev1.Probe, applied to the agent's liveness, readiness and startup probes.
+type agentProbeTiming struct {
+	initialDelay   int32
+	period         int32
+	timeout        int32
+	success        int32
+	failure        int32
+	startupFailure int32
+}
+
+// agentProbeTimingFromConfig returns the probe settings for the agent,
+// using the default for any value not specified in the config.
+func agentProbeTimingFromConfig(config *caas.ProbeTiming) agentProbeTiming {
+	timing := agentProbeTiming{
+		initialDelay:   agentProbeInitialDelay,
+		period:         agentProbePeriod,
+		timeout:        agentProbeTimeout,
+		success:        agentProbeSuccess,
+		failure:        agentProbeFailure,
+		startupFailure: agentProbeStartupFailure,
+	}
+	if config == nil {
+		return timing
+	}
+	if config.InitialDelay > 0 {
+		timing.initialDelay = int32(config.InitialDelay.Seconds())
+	}
+	if config.Period > 0 {
+		timing.period = int32(config.Period.Seconds())
+	}
+	if config.Timeout > 0 {
+		timing.timeout = int32(config.Timeout.Seconds())
+	}
+	if config.SuccessThreshold > 0 {
+		timing.success = config.SuccessThreshold
+	}
+	if config.FailureThreshold > 0 {
+		timing.failure = config.FailureThreshold
+	}
+	if config.StartupFailureThreshold > 0 {
+		timing.startupFailure = config.StartupFailureThreshold
+	}
+	if config.ImagePullAllowance > 0 && timing.period > 0 {
+		// Allow for enough extra probe periods to cover the allowance.
+		period := time.Duration(timing.period) * time.Second
+		timing.startupFailure += int32((config.ImagePullAllowance + period - 1) / period)
+	}
+	return timing
+}
+
+// agentProbePort returns the port the agent serves its probes on, using
+// the default if the port isn't specified in the config.
+func agentProbePort(port int) (string, error) {
+	if port == 0 {
+		return constants.AgentHTTPProbePort, nil
+	}
+	if port < 0 || port > 65535 {
+		return "", errors.NotValidf("agent probe port %d", port)
+	}
+	return strconv.Itoa(port), nil
+}
+
+// agentProbe returns an HTTP probe against the agent's probe port.
+func agentProbe(path, port string, timing agentProbeTiming, failureThreshold int32) *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: path,
+				Port: intstr.Parse(port),
+			},
+		},
+		InitialDelaySeconds: timing.initialDelay,
+		PeriodSeconds:       timing.period,
+		TimeoutSeconds:      timing.timeout,
+		SuccessThreshold:    timing.success,
+		FailureThreshold:    failureThreshold,
+	}
+}
+
+// containerProbe returns the k8s probe for a workload container probe, or
+// nil if the probe isn't set.
+func containerProbe(probe *caas.ContainerProbe) (*corev1.Probe, error) {
+	if probe == nil {
+		return nil, nil
+	}
+	var handler corev1.Handler
+	handlers := 0
+	if probe.HTTPGet != nil {
+		handlers++
+		action, err := httpGetAction(probe.HTTPGet)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		handler.HTTPGet = action
+	}
+	if probe.TCPSocket != nil {
+		handlers++
+		handler.TCPSocket = &corev1.TCPSocketAction{
+			Port: intstr.FromInt(probe.TCPSocket.Port),
+		}
+	}
+	if probe.Exec != nil {
+		handlers++
+		if len(probe.Exec.Command) == 0 {
+			return nil, errors.NotValidf("empty probe command")
+		}
+		handler.Exec = &corev1.ExecAction{
+			Command: probe.Exec.Command,
+		}
+	}
+	if handlers != 1 {
+		return nil, errors.NewNotValid(nil, "probe requires exactly one of http get, tcp socket or exec")
+	}
+	return &corev1.Probe{
+		Handler:             handler,
+		InitialDelaySeconds: int32(probe.InitialDelay / time.Second),
+		PeriodSeconds:       int32(probe.Period / time.Second),
+		TimeoutSeconds:      int32(probe.Timeout / time.Second),
+		SuccessThreshold:    probe.SuccessThreshold,
+		FailureThreshold:    probe.FailureThreshold,
+	}, nil
+}
+
+// httpGetAction returns the k8s action for an HTTP GET request made by a
+// probe or lifecycle hook.
+func httpGetAction(get *caas.HTTPGetProbe) (*corev1.HTTPGetAction, error) {
+	scheme := corev1.URISchemeHTTP
+	switch strings.ToUpper(get.Scheme) {
+	case "", string(corev1.URISchemeHTTP):
+	case string(corev1.URISchemeHTTPS):
+		scheme = corev1.URISchemeHTTPS
+	default:
+		return nil, errors.NotValidf("http get scheme %q", get.Scheme)
+	}
+	return &corev1.HTTPGetAction{
+		Path:   get.Path,
+		Port:   intstr.FromInt(get.Port),
+		Scheme: scheme,
+	}, nil
+}
+
+// containerLifecycle returns the k8s lifecycle for a workload container's
+// hooks, or nil if it has none.
+func containerLifecycle(lifecycle caas.ContainerLifecycle) (*corev1.Lifecycle, error) {
+	if lifecycle.PostStart == nil && lifecycle.PreStop == nil {
+		return nil, nil
+	}
+	postStart, err := lifecycleHandler(lifecycle.PostStart)
+	if err != nil {
+		return nil, errors.Annotate(err, "post start")
+	}
+	preStop, err := lifecycleHandler(lifecycle.PreStop)
+	if err != nil {
+		return nil, errors.Annotate(err, "pre stop")
+	}
+	return &corev1.Lifecycle{
+		PostStart: postStart,
+		PreStop:   preStop,
+	}, nil
+}
+
+// lifecycleHandler returns the k8s handler for a lifecycle hook, or nil
+// if there's no hook.
+func lifecycleHandler(hook *caas.LifecycleHandler) (*corev1.Handler, error) {
+	if hook == nil {
+		return nil, nil
+	}
+	var handler corev1.Handler
+	handlers := 0
+	if hook.HTTPGet != nil {
+		handlers++
+		action, err := httpGetAction(hook.HTTPGet)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		handler.HTTPGet = action
+	}
+	if hook.Exec != nil {
+		handlers++
+		if len(hook.Exec.Command) == 0 {
+			return nil, errors.NotValidf("empty hook command")
+		}
+		handler.Exec = &corev1.ExecAction{
+			Command: hook.Exec.Command,
+		}
+	}
+	if handlers != 1 {
+		return nil, errors.NewNotValid(nil, "lifecycle hook requires exactly one of http get or exec")
+	}
+	return &handler, nil
+}
+
+// workloadEntrypoint returns the command and arguments run in a workload
+// container. Pebble is run unless the charm overrides the command; args
+// given without a command are passed to Pebble.
+func workloadEntrypoint(container caas.ContainerConfig) ([]string, []string) {
+	if len(container.Command) > 0 {
+		return container.Command, container.Args
+	}
+	if len(container.Args) > 0 {
+		return []string{"/charm/bin/pebble"}, container.Args
+	}
+	return []string{"/charm/bin/pebble"}, []string{"run", "--create-dirs", "--hold"}
+}
+
+// containerEnv returns the environment for a workload container, made up
+// of the Juju variables followed by those declared by the charm. A charm
+// variable with the same name as a Juju one is dropped.
+func containerEnv(containerName string, vars []caas.EnvVar, jujuVars []corev1.EnvVar) ([]corev1.EnvVar, error) {
+	env := append([]corev1.EnvVar(nil), jujuVars...)
+	reserved := set.NewStrings()
+	for _, v := range jujuVars {
+		reserved.Add(v.Name)
+	}
+	for _, v := range vars {
+		if v.Name == "" {
+			return nil, errors.NotValidf("environment variable with empty name")
+		}
+		if reserved.Contains(v.Name) {
+			logger.Warningf("ignoring environment variable %q for container %q set by juju", v.Name, containerName)
+			continue
+		}
+		envVar := corev1.EnvVar{
+			Name:  v.Name,
+			Value: v.Value,
+		}
+		if v.ValueFrom != nil {
+			source, err := envVarSource(*v.ValueFrom)
+			if err != nil {
+				return nil, errors.Annotatef(err, "environment variable %q", v.Name)
+			}
+			envVar.Value = ""
+			envVar.ValueFrom = source
+		}
+		env = append(env, envVar)
+	}
+	return env, nil
+}
+
+func envVarSource(source caas.EnvVarSource) (*corev1.EnvVarSource, error) {
+	if source.Key == "" {
+		return nil, errors.NotValidf("source with empty key")
+	}
+	switch {
+	case source.ConfigMapName != "" && source.SecretName == "":
+		return &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: source.ConfigMapName},
+				Key:                  source.Key,
+			},
+		}, nil
+	case source.SecretName != "" && source.ConfigMapName == "":
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: source.SecretName},
+				Key:                  source.Key,
+			},
+		}, nil
+	}
+	return nil, errors.NewNotValid(nil, "source requires exactly one of config map or secret")
+}
+
+// containerEnvFrom returns the config maps and secrets used to populate
+// the environment of a workload container.
+func containerEnvFrom(sources []caas.EnvFromSource) ([]corev1.EnvFromSource, error) {
+	var envFrom []corev1.EnvFromSource
+	for _, source := range sources {
+		switch {
+		case source.ConfigMapName != "" && source.SecretName == "":
+			envFrom = append(envFrom, corev1.EnvFromSource{
+				Prefix: source.Prefix,
+				ConfigMapRef: &corev1.ConfigMapEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: source.ConfigMapName},
+				},
+			})
+		case source.SecretName != "" && source.ConfigMapName == "":
+			envFrom = append(envFrom, corev1.EnvFromSource{
+				Prefix: source.Prefix,
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: source.SecretName},
+				},
+			})
+		default:
+			return nil, errors.NewNotValid(nil, "environment source requires exactly one of config map or secret")
+		}
+	}
+	return envFrom, nil
+}
+
+// containerResourceRequirements returns the resource requirements for a
+// workload container from the requests and limits declared by the charm.
+// Operator constraints are only applied to the charm container, so they
+// don't replace the values declared for workload containers.
+func containerResourceRequirements(container caas.ContainerConfig) (corev1.ResourceRequirements, error) {
+	toResourceList := func(in map[string]string, kind string) (corev1.ResourceList, error) {
+		var out corev1.ResourceList
+		for name, value := range in {
+			q, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, errors.NotValidf("%s %s %q for container %q", name, kind, value, container.Name)
+			}
+			if out == nil {
+				out = corev1.ResourceList{}
+			}
+			out[corev1.ResourceName(name)] = q
+		}
+		return out, nil
+	}
+	requests, err := toResourceList(container.Resources.Requests, "request")
+	if err != nil {
+		return corev1.ResourceRequirements{}, errors.Trace(err)
+	}
+	limits, err := toResourceList(container.Resources.Limits, "limit")
+	if err != nil {
+		return corev1.ResourceRequirements{}, errors.Trace(err)
+	}
+	return corev1.ResourceRequirements{
+		Requests: requests,
+		Limits:   limits,
+	}, nil
+}
+
 func (a *app) annotations(config caas.ApplicationConfig) annotations.Annotation {
 	return k8sutils.ResourceTagsToAnnotations(config.ResourceTags, a.legacyLabels).
 		Merge(k8sutils.AnnotationsForVersion(config.AgentVersion.String(), a.legacyLabels))
@@ -1143,11 +2658,19 @@ type annotationGetter interface {
 	GetAnnotations() map[string]string
 }
 
+// getStorageUniqPrefix returns the prefix recorded on the existing
+// workload, or a new random prefix if the workload doesn't exist or has
+// none. Workloads created by older versions of Juju record the prefix
+// under the legacy annotation key, which is used so that their existing
+// volume claims continue to bind.
 func (a *app) getStorageUniqPrefix(getMeta func() (annotationGetter, error)) (string, error) {
 	if r, err := getMeta(); err == nil {
 		// TODO: remove this function with existing one once we consolidated the annotation keys.
-		if uniqID := r.GetAnnotations()[k8sutils.AnnotationKeyApplicationUUID(false)]; len(uniqID) > 0 {
-			return uniqID, nil
+		annotations := r.GetAnnotations()
+		for _, legacy := range []bool{false, true} {
+			if uniqID := annotations[k8sutils.AnnotationKeyApplicationUUID(legacy)]; len(uniqID) > 0 {
+				return uniqID, nil
+			}
 		}
 	} else if !errors.IsNotFound(err) {
 		return "", errors.Trace(err)
@@ -1165,8 +2688,10 @@ func (a *app) volumeName(storageName string) string {
 }
 
 func (a *app) configureStorage(
+	ctx context.Context,
 	storageUniqueID string,
 	filesystems []jujustorage.KubernetesFilesystemParams,
+	mountPathStrategy storage.MountPathStrategy,
 	storageClasses []resources.StorageClass,
 	handleVolume handleVolumeFunc,
 	handleVolumeMount handleVolumeMountFunc,
@@ -1184,6 +2709,9 @@ func (a *app) configureStorage(
 			return errors.NotValidf("duplicated storage name %q for %q", fs.StorageName, a.name)
 		}
 		fsNames.Add(fs.StorageName)
+		if fs.Size == 0 {
+			return errors.NotValidf("zero size for storage %q of %q", fs.StorageName, a.name)
+		}
 
 		logger.Debugf("%s has filesystem %s: %s", a.name, fs.StorageName, pretty.Sprint(fs))
 
@@ -1195,13 +2723,16 @@ func (a *app) configureStorage(
 		name := a.volumeName(fs.StorageName)
 		pvcNameGetter := func(volName string) string { return fmt.Sprintf("%s-%s", volName, storageUniqueID) }
 
-		vol, pvc, sc, err := a.filesystemToVolumeInfo(name, fs, storageClassMap, pvcNameGetter)
+		vol, pvc, sc, err := a.filesystemToVolumeInfo(ctx, name, fs, storageClassMap, pvcNameGetter)
 		if err != nil {
 			return errors.Trace(err)
 		}
 
 		var volumeMount *corev1.VolumeMount
-		mountPath := storage.GetMountPathForFilesystem(index, a.name, fs)
+		mountPath, err := storage.MountPathForFilesystem(mountPathStrategy, index, a.name, fs)
+		if err != nil {
+			return errors.Trace(err)
+		}
 		if vol != nil && handleVolume != nil {
 			logger.Debugf("using volume for %s filesystem %s: %s", a.name, fs.StorageName, pretty.Sprint(*vol))
 			volumeMount, err = handleVolume(*vol, mountPath, readOnly)
@@ -1233,7 +2764,7 @@ func (a *app) configureStorage(
 	return nil
 }
 
-func (a *app) filesystemToVolumeInfo(name string,
+func (a *app) filesystemToVolumeInfo(ctx context.Context, name string,
 	fs jujustorage.KubernetesFilesystemParams,
 	storageClasses map[string]resources.StorageClass,
 	pvcNameGetter func(volName string) string,
@@ -1261,15 +2792,24 @@ func (a *app) filesystemToVolumeInfo(name string,
 	}
 
 	var newStorageClass *storagev1.StorageClass
+	var provisioner string
 	qualifiedStorageClassName := constants.QualifiedStorageClassName(a.namespace, params.StorageConfig.StorageClass)
-	if _, ok := storageClasses[params.StorageConfig.StorageClass]; ok {
-		// Do nothing
-	} else if _, ok := storageClasses[qualifiedStorageClassName]; ok {
+	if sc, ok := storageClasses[params.StorageConfig.StorageClass]; ok {
+		provisioner = sc.Provisioner
+	} else if sc, ok := storageClasses[qualifiedStorageClassName]; ok {
 		params.StorageConfig.StorageClass = qualifiedStorageClassName
+		provisioner = sc.Provisioner
 	} else {
 		sp := storage.StorageProvisioner(a.namespace, a.modelName, *params)
 		newStorageClass = storage.StorageClassSpec(sp, a.legacyLabels)
 		params.StorageConfig.StorageClass = newStorageClass.Name
+		provisioner = newStorageClass.Provisioner
+	}
+	if err := a.ensureProvisionerUnchanged(ctx, fs.StorageName, params.Name, provisioner, storageClasses); err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	if err := storage.ValidateStorageMode(provisioner, params.AccessMode); err != nil {
+		return nil, nil, nil, errors.Annotatef(err, "filesystem %q", fs.StorageName)
 	}
 
 	labels := k8sutils.LabelsMerge(
@@ -1290,6 +2830,50 @@ func (a *app) filesystemToVolumeInfo(name string,
 	return nil, pvc, newStorageClass, nil
 }
 
+// ensureProvisionerUnchanged returns an error if a persistent volume claim
+// already bound for the filesystem uses a storage class with a different
+// provisioner to the one resolved now, since the storage class of a claim
+// can't be changed in place.
+func (a *app) ensureProvisionerUnchanged(
+	ctx context.Context,
+	storageName, pvcName, provisioner string,
+	storageClasses map[string]resources.StorageClass,
+) error {
+	if provisioner == "" {
+		return nil
+	}
+	pvcs, err := resources.ListPersistentVolumeClaims(ctx, a.client, a.namespace, metav1.ListOptions{
+		LabelSelector: k8sutils.LabelsToSelector(
+			k8sutils.LabelsForStorage(storageName, a.legacyLabels),
+		).String(),
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, pvc := range pvcs {
+		// Claims created from a stateful set's volume claim template are
+		// suffixed with the pod name.
+		if pvc.Name != pvcName && !strings.HasPrefix(pvc.Name, pvcName+"-") {
+			continue
+		}
+		if pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.StorageClassName == nil {
+			continue
+		}
+		sc, ok := storageClasses[*pvc.Spec.StorageClassName]
+		if !ok {
+			logger.Warningf("storage class %q of persistent volume claim %q not found", *pvc.Spec.StorageClassName, pvc.Name)
+			continue
+		}
+		if sc.Provisioner != provisioner {
+			return errors.NotSupportedf(
+				"changing the provisioner of filesystem %q from %q (storage class %q bound to persistent volume claim %q) to %q",
+				storageName, sc.Provisioner, sc.Name, pvc.Name, provisioner,
+			)
+		}
+	}
+	return nil
+}
+
 func int32Ptr(v int32) *int32 {
 	return &v
 }
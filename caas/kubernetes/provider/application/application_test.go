@@ -6,6 +6,7 @@ package application_test
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -18,11 +19,18 @@ import (
 	gc "gopkg.in/check.v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/juju/juju/caas"
@@ -50,6 +58,11 @@ type applicationSuite struct {
 	k8sWatcherFn k8swatcher.NewK8sWatcherFunc
 	watchers     []k8swatcher.KubernetesNotifyWatcher
 	applier      *resourcesmocks.MockApplier
+	resyncPeriod time.Duration
+
+	k8sStringsWatcherFn k8swatcher.NewK8sStringsWatcherFunc
+
+	storageClasses application.StorageClassLister
 }
 
 var _ = gc.Suite(&applicationSuite{})
@@ -67,7 +80,10 @@ func (s *applicationSuite) TearDownTest(c *gc.C) {
 	s.client = nil
 	s.clock = nil
 	s.watchers = nil
+	s.k8sStringsWatcherFn = nil
 	s.applier = nil
+	s.resyncPeriod = 0
+	s.storageClasses = nil
 
 	s.BaseSuite.TearDownTest(c)
 }
@@ -84,6 +100,13 @@ func (s *applicationSuite) getApp(c *gc.C, deploymentType caas.DeploymentType, m
 		}
 		return w, err
 	})
+	stringsWatcherFn := k8swatcher.NewK8sStringsWatcherFunc(func(i cache.SharedIndexInformer, n string, c jujuclock.Clock, e []string,
+		f k8swatcher.K8sStringsWatcherFilterFunc) (k8swatcher.KubernetesStringsWatcher, error) {
+		if s.k8sStringsWatcherFn == nil {
+			return nil, errors.NewNotFound(nil, "undefined k8sStringsWatcherFn for base test")
+		}
+		return s.k8sStringsWatcherFn(i, n, c, e, f)
+	})
 
 	ctrl := gomock.NewController(c)
 	s.applier = resourcesmocks.NewMockApplier(ctrl)
@@ -93,6 +116,8 @@ func (s *applicationSuite) getApp(c *gc.C, deploymentType caas.DeploymentType, m
 		deploymentType,
 		s.client,
 		watcherFn,
+		stringsWatcherFn,
+		s.storageClasses,
 		s.clock,
 		func() (string, error) {
 			return "appuuid", nil
@@ -103,6 +128,7 @@ func (s *applicationSuite) getApp(c *gc.C, deploymentType caas.DeploymentType, m
 			}
 			return resources.NewApplier()
 		},
+		s.resyncPeriod,
 	), ctrl
 }
 
@@ -139,7 +165,7 @@ func (s *applicationSuite) assertEnsure(c *gc.C, deploymentType caas.DeploymentT
 			Selector: map[string]string{"app.kubernetes.io/name": "gitlab"},
 			Type:     corev1.ServiceTypeClusterIP,
 			Ports: []corev1.ServicePort{{
-				Name: "placeholder",
+				Name: "juju-placeholder",
 				Port: 65535,
 			}},
 		},
@@ -148,6 +174,7 @@ func (s *applicationSuite) assertEnsure(c *gc.C, deploymentType caas.DeploymentT
 	app, _ := s.getApp(c, deploymentType, false)
 
 	c.Assert(app.Ensure(
+		context.Background(),
 		caas.ApplicationConfig{
 			AgentImagePath: "operator/image-path",
 			CharmBaseImage: coreresources.DockerImageDetails{
@@ -295,6 +322,7 @@ func getPodSpec(c *gc.C) corev1.PodSpec {
 				},
 				InitialDelaySeconds: 30,
 				PeriodSeconds:       10,
+				TimeoutSeconds:      3,
 				SuccessThreshold:    1,
 				FailureThreshold:    2,
 			},
@@ -307,6 +335,7 @@ func getPodSpec(c *gc.C) corev1.PodSpec {
 				},
 				InitialDelaySeconds: 30,
 				PeriodSeconds:       10,
+				TimeoutSeconds:      3,
 				SuccessThreshold:    1,
 				FailureThreshold:    2,
 			},
@@ -319,8 +348,9 @@ func getPodSpec(c *gc.C) corev1.PodSpec {
 				},
 				InitialDelaySeconds: 30,
 				PeriodSeconds:       10,
+				TimeoutSeconds:      3,
 				SuccessThreshold:    1,
-				FailureThreshold:    2,
+				FailureThreshold:    30,
 			},
 			VolumeMounts: []corev1.VolumeMount{
 				{
@@ -621,7 +651,7 @@ func (s *applicationSuite) TestEnsureDaemon(c *gc.C) {
 
 func (s *applicationSuite) TestExistsNotSupported(c *gc.C) {
 	app, _ := s.getApp(c, "notsupported", false)
-	_, err := app.Exists()
+	_, err := app.Exists(context.Background())
 	c.Assert(err, gc.ErrorMatches, `unknown deployment type not supported`)
 }
 
@@ -630,7 +660,7 @@ func (s *applicationSuite) TestExistsDeployment(c *gc.C) {
 
 	app, _ := s.getApp(c, caas.DeploymentStateless, false)
 	// Deployment does not exists.
-	result, err := app.Exists()
+	result, err := app.Exists(context.Background())
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, caas.DeploymentState{})
 
@@ -657,7 +687,7 @@ func (s *applicationSuite) TestExistsDeployment(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 
 	// Deployment exists and is terminating.
-	result, err = app.Exists()
+	result, err = app.Exists(context.Background())
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, caas.DeploymentState{
 		Exists: true, Terminating: true,
@@ -669,7 +699,7 @@ func (s *applicationSuite) TestExistsStatefulSet(c *gc.C) {
 
 	app, _ := s.getApp(c, caas.DeploymentStateful, false)
 	// Statefulset does not exists.
-	result, err := app.Exists()
+	result, err := app.Exists(context.Background())
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, caas.DeploymentState{})
 
@@ -696,7 +726,7 @@ func (s *applicationSuite) TestExistsStatefulSet(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 
 	// Statefulset exists and is terminating.
-	result, err = app.Exists()
+	result, err = app.Exists(context.Background())
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, caas.DeploymentState{
 		Exists: true, Terminating: true,
@@ -709,7 +739,7 @@ func (s *applicationSuite) TestExistsDaemonSet(c *gc.C) {
 
 	app, _ := s.getApp(c, caas.DeploymentDaemon, false)
 	// Daemonset does not exists.
-	result, err := app.Exists()
+	result, err := app.Exists(context.Background())
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, caas.DeploymentState{})
 
@@ -736,7 +766,7 @@ func (s *applicationSuite) TestExistsDaemonSet(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 
 	// Daemonset exists and is terminating.
-	result, err = app.Exists()
+	result, err = app.Exists(context.Background())
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, caas.DeploymentState{
 		Exists: true, Terminating: true,
@@ -750,12 +780,16 @@ func (s *applicationSuite) TestDeleteStateful(c *gc.C) {
 
 	gomock.InOrder(
 		s.applier.EXPECT().Delete(resources.NewStatefulSet("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewPodDisruptionBudget("gitlab", "test", nil)),
 		s.applier.EXPECT().Delete(resources.NewService("gitlab-endpoints", "test", nil)),
 		s.applier.EXPECT().Delete(resources.NewService("gitlab", "test", nil)),
 		s.applier.EXPECT().Delete(resources.NewSecret("gitlab-application-config", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRoleBinding("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRole("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewServiceAccount("gitlab", "test", nil)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.Delete(), jc.ErrorIsNil)
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{}), jc.ErrorIsNil)
 }
 
 func (s *applicationSuite) TestDeleteStateless(c *gc.C) {
@@ -764,11 +798,15 @@ func (s *applicationSuite) TestDeleteStateless(c *gc.C) {
 
 	gomock.InOrder(
 		s.applier.EXPECT().Delete(resources.NewDeployment("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewPodDisruptionBudget("gitlab", "test", nil)),
 		s.applier.EXPECT().Delete(resources.NewService("gitlab", "test", nil)),
 		s.applier.EXPECT().Delete(resources.NewSecret("gitlab-application-config", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRoleBinding("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRole("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewServiceAccount("gitlab", "test", nil)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.Delete(), jc.ErrorIsNil)
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{}), jc.ErrorIsNil)
 }
 
 func (s *applicationSuite) TestDeleteDaemon(c *gc.C) {
@@ -779,9 +817,81 @@ func (s *applicationSuite) TestDeleteDaemon(c *gc.C) {
 		s.applier.EXPECT().Delete(resources.NewDaemonSet("gitlab", "test", nil)),
 		s.applier.EXPECT().Delete(resources.NewService("gitlab", "test", nil)),
 		s.applier.EXPECT().Delete(resources.NewSecret("gitlab-application-config", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRoleBinding("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRole("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewServiceAccount("gitlab", "test", nil)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.Delete(), jc.ErrorIsNil)
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{}), jc.ErrorIsNil)
+}
+
+func (s *applicationSuite) createClaims(c *gc.C, claims map[string]map[string]string) {
+	for name, claimLabels := range claims {
+		_, err := s.client.CoreV1().PersistentVolumeClaims("test").Create(context.TODO(), &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: claimLabels,
+			},
+		}, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *applicationSuite) claimNames(c *gc.C) []string {
+	pvcs, err := s.client.CoreV1().PersistentVolumeClaims("test").List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	var names []string
+	for _, pvc := range pvcs.Items {
+		names = append(names, pvc.Name)
+	}
+	return names
+}
+
+func (s *applicationSuite) assertDeleteClaims(c *gc.C, deploymentType caas.DeploymentType) {
+	storageLabel := func(name string) map[string]string {
+		return map[string]string{"storage.juju.is/name": name}
+	}
+	s.createClaims(c, map[string]map[string]string{
+		"gitlab-database-appuuid": storageLabel("database"),
+		"gitlab-logs-0123abcd":    storageLabel("logs"),
+		// Claims of other applications, created from a statefulset's
+		// volume claim template, or not created by juju are kept.
+		"gitlab-db-data-4567cdef":          storageLabel("data"),
+		"gitlab-database-appuuid-gitlab-0": storageLabel("database"),
+		"gitlab-database-manual":           nil,
+	})
+
+	app, _ := s.getApp(c, deploymentType, false)
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{DestroyStorage: true}), jc.ErrorIsNil)
+	c.Assert(s.claimNames(c), jc.SameContents, []string{
+		"gitlab-db-data-4567cdef", "gitlab-database-appuuid-gitlab-0", "gitlab-database-manual",
+	})
+}
+
+func (s *applicationSuite) TestDeleteStatelessClaims(c *gc.C) {
+	s.assertDeleteClaims(c, caas.DeploymentStateless)
+}
+
+func (s *applicationSuite) TestDeleteDaemonClaims(c *gc.C) {
+	s.assertDeleteClaims(c, caas.DeploymentDaemon)
+}
+
+func (s *applicationSuite) TestDeleteStatefulKeepsClaims(c *gc.C) {
+	s.createClaims(c, map[string]map[string]string{
+		"gitlab-database-appuuid": {"storage.juju.is/name": "database"},
+	})
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{DestroyStorage: true}), jc.ErrorIsNil)
+	c.Assert(s.claimNames(c), jc.DeepEquals, []string{"gitlab-database-appuuid"})
+}
+
+func (s *applicationSuite) TestDeleteKeepsClaimsByDefault(c *gc.C) {
+	s.createClaims(c, map[string]map[string]string{
+		"gitlab-database-appuuid": {"storage.juju.is/name": "database"},
+	})
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{}), jc.ErrorIsNil)
+	c.Assert(s.claimNames(c), jc.DeepEquals, []string{"gitlab-database-appuuid"})
 }
 
 func (s *applicationSuite) TestWatchNotsupported(c *gc.C) {
@@ -817,6 +927,78 @@ func (s *applicationSuite) TestWatch(c *gc.C) {
 	}
 }
 
+func (s *applicationSuite) TestWatchResyncPeriod(c *gc.C) {
+	s.resyncPeriod = 5 * time.Minute
+	app, ctrl := s.getApp(c, caas.DeploymentStateful, true)
+	defer ctrl.Finish()
+
+	var informers []cache.SharedIndexInformer
+	s.k8sWatcherFn = func(i cache.SharedIndexInformer, _ string, _ jujuclock.Clock) (k8swatcher.KubernetesNotifyWatcher, error) {
+		informers = append(informers, i)
+		w, _ := k8swatchertest.NewKubernetesTestWatcher()
+		return w, nil
+	}
+
+	_, err := app.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = app.WatchReplicas()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(informers, gc.HasLen, 2)
+	for _, i := range informers {
+		// The informer doesn't expose its resync period.
+		resync := reflect.ValueOf(i).Elem().FieldByName("defaultEventHandlerResyncPeriod")
+		c.Assert(time.Duration(resync.Int()), gc.Equals, 5*time.Minute)
+	}
+}
+
+func (s *applicationSuite) TestWatchDeletion(c *gc.C) {
+	app, ctrl := s.getApp(c, caas.DeploymentStateful, true)
+	defer ctrl.Finish()
+
+	var informer cache.SharedIndexInformer
+	s.k8sWatcherFn = func(i cache.SharedIndexInformer, _ string, _ jujuclock.Clock) (k8swatcher.KubernetesNotifyWatcher, error) {
+		informer = i
+		w, _ := k8swatchertest.NewKubernetesTestWatcher()
+		return w, nil
+	}
+
+	w, err := app.WatchDeletion()
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case _, ok := <-w.Changes():
+		c.Assert(ok, jc.IsTrue)
+	case <-time.After(testing.LongWait):
+		c.Fatal("timed out waiting for event")
+	}
+	c.Assert(informer, gc.NotNil)
+}
+
+func (s *applicationSuite) TestDeletionEventHandler(c *gc.C) {
+	var events []string
+	handler := application.NewDeletionEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { events = append(events, "add") },
+		UpdateFunc: func(interface{}, interface{}) {
+			events = append(events, "update")
+		},
+		DeleteFunc: func(interface{}) { events = append(events, "delete") },
+	})
+
+	live := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "gitlab"}}
+	terminating := live.DeepCopy()
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	handler.OnAdd(live)
+	handler.OnUpdate(live, live)
+	c.Assert(events, gc.HasLen, 0)
+
+	handler.OnUpdate(live, terminating)
+	handler.OnUpdate(terminating, terminating)
+	handler.OnDelete(terminating)
+	handler.OnAdd(terminating)
+	c.Assert(events, jc.DeepEquals, []string{"update", "delete", "add"})
+}
+
 func (s *applicationSuite) TestWatchReplicas(c *gc.C) {
 	app, ctrl := s.getApp(c, caas.DeploymentDaemon, true)
 	defer ctrl.Finish()
@@ -837,9 +1019,75 @@ func (s *applicationSuite) TestWatchReplicas(c *gc.C) {
 	}
 }
 
+func (s *applicationSuite) TestWatchUnits(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	pod := func(name, appName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+				Labels:    map[string]string{"app.kubernetes.io/name": appName},
+			},
+		}
+	}
+	for _, p := range []*corev1.Pod{pod("gitlab-1", "gitlab"), pod("gitlab-0", "gitlab"), pod("mariadb-0", "mariadb")} {
+		_, err := s.client.CoreV1().Pods("test").Create(context.TODO(), p, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	var (
+		informer      cache.SharedIndexInformer
+		initialEvents []string
+		filter        k8swatcher.K8sStringsWatcherFilterFunc
+	)
+	s.k8sStringsWatcherFn = func(i cache.SharedIndexInformer, _ string, _ jujuclock.Clock, e []string,
+		f k8swatcher.K8sStringsWatcherFilterFunc) (k8swatcher.KubernetesStringsWatcher, error) {
+		informer = i
+		initialEvents = e
+		filter = f
+		return nil, nil
+	}
+	_, err := app.WatchUnits(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(initialEvents, jc.DeepEquals, []string{"gitlab-0", "gitlab-1"})
+
+	// The informer was synced before the watcher was created, and
+	// running it returns once stopped.
+	c.Assert(informer.HasSynced(), jc.IsTrue)
+	stop := make(chan struct{})
+	close(stop)
+	informer.Run(stop)
+
+	for i, t := range []struct {
+		evt      k8swatcher.WatchEvent
+		obj      interface{}
+		name     string
+		notified bool
+	}{
+		// The informer adding the existing pods isn't notified again.
+		{evt: k8swatcher.WatchEventAdd, obj: pod("gitlab-0", "gitlab")},
+		{evt: k8swatcher.WatchEventAdd, obj: pod("gitlab-2", "gitlab"), name: "gitlab-2", notified: true},
+		{evt: k8swatcher.WatchEventUpdate, obj: pod("gitlab-2", "gitlab")},
+		{evt: k8swatcher.WatchEventDelete, obj: pod("gitlab-0", "gitlab"), name: "gitlab-0", notified: true},
+		{evt: k8swatcher.WatchEventDelete, obj: pod("gitlab-0", "gitlab")},
+		{
+			evt:      k8swatcher.WatchEventDelete,
+			obj:      cache.DeletedFinalStateUnknown{Key: "test/gitlab-1", Obj: pod("gitlab-1", "gitlab")},
+			name:     "gitlab-1",
+			notified: true,
+		},
+		{evt: k8swatcher.WatchEventAdd, obj: pod("gitlab-0", "gitlab"), name: "gitlab-0", notified: true},
+	} {
+		c.Logf("test %d", i)
+		name, notified := filter(t.evt, t.obj)
+		c.Check(name, gc.Equals, t.name)
+		c.Check(notified, gc.Equals, t.notified)
+	}
+}
+
 func (s *applicationSuite) TestStateNotSupported(c *gc.C) {
 	app, _ := s.getApp(c, "notsupported", false)
-	_, err := app.State()
+	_, err := app.State(context.Background())
 	c.Assert(err, gc.ErrorMatches, `unknown deployment type not supported`)
 }
 
@@ -870,16 +1118,23 @@ func (s *applicationSuite) assertState(c *gc.C, deploymentType caas.DeploymentTy
 			Labels:      map[string]string{"app.kubernetes.io/name": "gitlab"},
 			Annotations: map[string]string{"juju.is/version": "0.0.0"},
 		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodReady,
+				Status: corev1.ConditionTrue,
+			}},
+		},
 	}
 	_, err = s.client.CoreV1().Pods("test").Create(context.TODO(),
 		pod2, metav1.CreateOptions{})
 	c.Assert(err, jc.ErrorIsNil)
 
-	appState, err := app.State()
+	appState, err := app.State(context.Background())
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(appState, gc.DeepEquals, caas.ApplicationState{
 		DesiredReplicas: desiredReplicas,
 		Replicas:        []string{"pod1", "pod2"},
+		ReadyReplicas:   1,
 	})
 }
 func (s *applicationSuite) TestStateStateful(c *gc.C) {
@@ -968,6 +1223,100 @@ func (s *applicationSuite) TestStateDaemon(c *gc.C) {
 	})
 }
 
+func (s *applicationSuite) TestStateFilesystems(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+
+	_, err := s.client.AppsV1().StatefulSets("test").Create(context.TODO(), &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gitlab",
+			Namespace: "test",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: application.Int32Ptr(2),
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for i, phase := range []corev1.PersistentVolumeClaimPhase{corev1.ClaimBound, corev1.ClaimPending} {
+		podSpec := getPodSpec(c)
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "gitlab-database-appuuid",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: fmt.Sprintf("gitlab-database-appuuid-gitlab-%d", i),
+				},
+			},
+		})
+		_, err := s.client.CoreV1().Pods("test").Create(context.TODO(), &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("gitlab-%d", i),
+				Namespace: "test",
+				Labels:    map[string]string{"app.kubernetes.io/name": "gitlab"},
+			},
+			Spec: podSpec,
+		}, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+
+		_, err = s.client.CoreV1().PersistentVolumeClaims("test").Create(context.TODO(), &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("gitlab-database-appuuid-gitlab-%d", i),
+				Namespace: "test",
+				Labels:    map[string]string{"storage.juju.is/name": "database"},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("1Gi"),
+					},
+				},
+				VolumeName: fmt.Sprintf("pv-%d", i),
+			},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Phase: phase,
+			},
+		}, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	_, err = s.client.CoreV1().PersistentVolumes().Create(context.TODO(), &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pv-0",
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse("2Gi"),
+			},
+		},
+		Status: corev1.PersistentVolumeStatus{
+			Phase: corev1.VolumeBound,
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	appState, err := app.State(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(appState.Replicas, jc.DeepEquals, []string{"gitlab-0", "gitlab-1"})
+
+	// Only the bound filesystem of gitlab-0 is reported.
+	mc := jc.NewMultiChecker()
+	mc.AddExpr(`_[_].Status.Since`, jc.Ignore)
+	mc.AddExpr(`_[_].Volume.Status.Since`, jc.Ignore)
+	c.Assert(appState.Filesystems, mc, []caas.FilesystemInfo{{
+		StorageName: "database",
+		Size:        1024,
+		MountPoint:  "path/to/here",
+		Status: status.StatusInfo{
+			Status: status.Attached,
+		},
+		Volume: caas.VolumeInfo{
+			VolumeId: "pv-0",
+			Size:     2048,
+			Status: status.StatusInfo{
+				Status: status.Attached,
+			},
+		},
+	}})
+}
+
 func getDefaultSvc() *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -987,6 +1336,233 @@ func getDefaultSvc() *corev1.Service {
 	}
 }
 
+func (s *applicationSuite) assertUpdateService(c *gc.C, param caas.ServiceParam) (*corev1.Service, error) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+
+	_, err := s.client.CoreV1().Services("test").Create(context.TODO(), getDefaultSvc(), metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.client.AppsV1().Deployments("test").Create(context.TODO(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gitlab",
+			Namespace: "test",
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	if err := app.UpdateService(context.Background(), param); err != nil {
+		return nil, err
+	}
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	return svc, nil
+}
+
+func (s *applicationSuite) TestUpdateServiceLoadBalancerSourceRanges(c *gc.C) {
+	svc, err := s.assertUpdateService(c, caas.ServiceParam{
+		Type:                     "LoadBalancer",
+		Ports:                    []caas.ServicePort{{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"}},
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8", "192.168.1.0/24"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.Type, gc.Equals, corev1.ServiceTypeLoadBalancer)
+	c.Assert(svc.Spec.LoadBalancerSourceRanges, gc.DeepEquals, []string{"10.0.0.0/8", "192.168.1.0/24"})
+}
+
+func (s *applicationSuite) TestUpdateServiceLoadBalancerSourceRangesIgnored(c *gc.C) {
+	svc, err := s.assertUpdateService(c, caas.ServiceParam{
+		Type:                     "ClusterIP",
+		Ports:                    []caas.ServicePort{{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"}},
+		LoadBalancerSourceRanges: []string{"not-a-cidr"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.LoadBalancerSourceRanges, gc.HasLen, 0)
+}
+
+func (s *applicationSuite) TestUpdateServiceLoadBalancerSourceRangesCleared(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+	ports := []caas.ServicePort{{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"}}
+
+	err := app.UpdateService(context.Background(), caas.ServiceParam{
+		Type:                     "LoadBalancer",
+		Ports:                    ports,
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.LoadBalancerSourceRanges, gc.DeepEquals, []string{"10.0.0.0/8"})
+
+	// Unexposing the application removes the source ranges, which are
+	// only valid for a load balancer.
+	err = app.UpdateService(context.Background(), caas.ServiceParam{
+		Type:  "ClusterIP",
+		Ports: ports,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	svc, err = s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.Type, gc.Equals, corev1.ServiceTypeClusterIP)
+	c.Assert(svc.Spec.LoadBalancerSourceRanges, gc.HasLen, 0)
+}
+
+func (s *applicationSuite) TestUpdateServiceLoadBalancerSourceRangesInvalid(c *gc.C) {
+	_, err := s.assertUpdateService(c, caas.ServiceParam{
+		Type:                     "LoadBalancer",
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8", "10.0.0.1"},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `load balancer source range "10.0.0.1" not valid`)
+}
+
+func (s *applicationSuite) TestUpdateServiceNoPorts(c *gc.C) {
+	_, err := s.assertUpdateService(c, caas.ServiceParam{
+		Type: "ClusterIP",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `no ports for service "gitlab" not valid`)
+}
+
+func (s *applicationSuite) TestUpdateServiceReplacesPlaceholderPort(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	err := app.UpdateService(context.Background(), caas.ServiceParam{
+		Type:  "ClusterIP",
+		Ports: []caas.ServicePort{{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.Ports, jc.DeepEquals, []corev1.ServicePort{{
+		Name:       "http",
+		Port:       80,
+		TargetPort: intstr.FromInt(8080),
+		Protocol:   corev1.ProtocolTCP,
+	}})
+}
+
+func (s *applicationSuite) TestUpdateServiceUserPortNamedPlaceholder(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	err := app.UpdateService(context.Background(), caas.ServiceParam{
+		Type:  "ClusterIP",
+		Ports: []caas.ServicePort{{Name: "placeholder", Port: 8080, TargetPort: 8080, Protocol: "TCP"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.Ports, jc.DeepEquals, []corev1.ServicePort{{
+		Name:       "placeholder",
+		Port:       8080,
+		TargetPort: intstr.FromInt(8080),
+		Protocol:   corev1.ProtocolTCP,
+	}})
+
+	c.Assert(app.UpdatePorts(context.Background(), []caas.ServicePort{
+		{Name: "placeholder", Port: 65535, TargetPort: 9090, Protocol: "TCP"},
+	}, false), jc.ErrorIsNil)
+
+	svc, err = s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.Ports, jc.DeepEquals, []corev1.ServicePort{{
+		Name:       "placeholder",
+		Port:       65535,
+		TargetPort: intstr.FromInt(9090),
+		Protocol:   corev1.ProtocolTCP,
+	}})
+}
+
+func (s *applicationSuite) TestUpdateServiceReservedPortName(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	err := app.UpdateService(context.Background(), caas.ServiceParam{
+		Type:  "ClusterIP",
+		Ports: []caas.ServicePort{{Name: "juju-placeholder", Port: 80, TargetPort: 8080, Protocol: "TCP"}},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `reserved port name "juju-placeholder" not valid`)
+
+	err = app.UpdatePorts(context.Background(), []caas.ServicePort{
+		{Name: "juju-placeholder", Port: 80, TargetPort: 8080, Protocol: "TCP"},
+	}, false)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `reserved port name "juju-placeholder" not valid`)
+}
+
+func (s *applicationSuite) TestUpdateServiceExternalIPs(c *gc.C) {
+	svc, err := s.assertUpdateService(c, caas.ServiceParam{
+		Type:        "ClusterIP",
+		Ports:       []caas.ServicePort{{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"}},
+		ExternalIPs: []string{"192.0.2.10", "2001:db8::10"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.ExternalIPs, gc.DeepEquals, []string{"192.0.2.10", "2001:db8::10"})
+}
+
+func (s *applicationSuite) TestUpdateServiceExternalIPsRemoved(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+	ports := []caas.ServicePort{{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"}}
+
+	err := app.UpdateService(context.Background(), caas.ServiceParam{
+		Type:        "ClusterIP",
+		Ports:       ports,
+		ExternalIPs: []string{"192.0.2.10"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.ExternalIPs, gc.DeepEquals, []string{"192.0.2.10"})
+
+	err = app.UpdateService(context.Background(), caas.ServiceParam{
+		Type:  "ClusterIP",
+		Ports: ports,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	svc, err = s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.ExternalIPs, gc.HasLen, 0)
+}
+
+func (s *applicationSuite) TestUpdateServiceExternalIPsInvalid(c *gc.C) {
+	_, err := s.assertUpdateService(c, caas.ServiceParam{
+		Type:        "ClusterIP",
+		ExternalIPs: []string{"192.0.2.10", "192.0.2.0/24"},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `external IP "192.0.2.0/24" not valid`)
+}
+
+func (s *applicationSuite) TestUpdateServiceLabelsAndAnnotations(c *gc.C) {
+	svc, err := s.assertUpdateService(c, caas.ServiceParam{
+		Type:  "LoadBalancer",
+		Ports: []caas.ServicePort{{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"}},
+		Labels: map[string]string{
+			"team":                         "gitlab",
+			"app.kubernetes.io/managed-by": "someone-else",
+		},
+		Annotations: map[string]string{
+			"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+			"juju.is/version": "9.9.9",
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Labels, jc.DeepEquals, map[string]string{
+		"app.kubernetes.io/name":       "gitlab",
+		"app.kubernetes.io/managed-by": "juju",
+		"team":                         "gitlab",
+	})
+	c.Assert(svc.Annotations, jc.DeepEquals, map[string]string{
+		"juju.is/version": "0.0.0",
+		"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+	})
+}
+
 func (s *applicationSuite) TestUpdatePortsStatelessUpdateContainerPorts(c *gc.C) {
 	app, ctrl := s.getApp(c, caas.DeploymentStateless, true)
 	defer ctrl.Finish()
@@ -1042,6 +1618,7 @@ func (s *applicationSuite) TestUpdatePortsStatelessUpdateContainerPorts(c *gc.C)
 								},
 								InitialDelaySeconds: 30,
 								PeriodSeconds:       10,
+								TimeoutSeconds:      3,
 								SuccessThreshold:    1,
 								FailureThreshold:    2,
 							},
@@ -1054,6 +1631,7 @@ func (s *applicationSuite) TestUpdatePortsStatelessUpdateContainerPorts(c *gc.C)
 								},
 								InitialDelaySeconds: 30,
 								PeriodSeconds:       10,
+								TimeoutSeconds:      3,
 								SuccessThreshold:    1,
 								FailureThreshold:    2,
 							},
@@ -1066,8 +1644,9 @@ func (s *applicationSuite) TestUpdatePortsStatelessUpdateContainerPorts(c *gc.C)
 								},
 								InitialDelaySeconds: 30,
 								PeriodSeconds:       10,
+								TimeoutSeconds:      3,
 								SuccessThreshold:    1,
-								FailureThreshold:    2,
+								FailureThreshold:    30,
 							},
 						}, {
 							Name:            "gitlab",
@@ -1107,7 +1686,7 @@ func (s *applicationSuite) TestUpdatePortsStatelessUpdateContainerPorts(c *gc.C)
 		s.applier.EXPECT().Apply(resources.NewDeployment("gitlab", "test", updatedMainResource)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.UpdatePorts([]caas.ServicePort{
+	c.Assert(app.UpdatePorts(context.Background(), []caas.ServicePort{
 		{
 			Name:       "port1",
 			Port:       80,
@@ -1172,6 +1751,7 @@ func (s *applicationSuite) TestUpdatePortsStatefulUpdateContainerPorts(c *gc.C)
 								},
 								InitialDelaySeconds: 30,
 								PeriodSeconds:       10,
+								TimeoutSeconds:      3,
 								SuccessThreshold:    1,
 								FailureThreshold:    2,
 							},
@@ -1184,6 +1764,7 @@ func (s *applicationSuite) TestUpdatePortsStatefulUpdateContainerPorts(c *gc.C)
 								},
 								InitialDelaySeconds: 30,
 								PeriodSeconds:       10,
+								TimeoutSeconds:      3,
 								SuccessThreshold:    1,
 								FailureThreshold:    2,
 							},
@@ -1196,8 +1777,9 @@ func (s *applicationSuite) TestUpdatePortsStatefulUpdateContainerPorts(c *gc.C)
 								},
 								InitialDelaySeconds: 30,
 								PeriodSeconds:       10,
+								TimeoutSeconds:      3,
 								SuccessThreshold:    1,
-								FailureThreshold:    2,
+								FailureThreshold:    30,
 							},
 						}, {
 							Name:            "gitlab",
@@ -1237,7 +1819,7 @@ func (s *applicationSuite) TestUpdatePortsStatefulUpdateContainerPorts(c *gc.C)
 		s.applier.EXPECT().Apply(resources.NewStatefulSet("gitlab", "test", updatedMainResource)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.UpdatePorts([]caas.ServicePort{
+	c.Assert(app.UpdatePorts(context.Background(), []caas.ServicePort{
 		{
 			Name:       "port1",
 			Port:       80,
@@ -1327,7 +1909,7 @@ func (s *applicationSuite) TestUpdatePortsDaemonUpdateContainerPorts(c *gc.C) {
 		s.applier.EXPECT().Apply(resources.NewDaemonSet("gitlab", "test", updatedMainResource)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.UpdatePorts([]caas.ServicePort{
+	c.Assert(app.UpdatePorts(context.Background(), []caas.ServicePort{
 		{
 			Name:       "port1",
 			Port:       80,
@@ -1358,7 +1940,7 @@ func (s *applicationSuite) TestUpdatePortsStateless(c *gc.C) {
 		s.applier.EXPECT().Apply(resources.NewService("gitlab", "test", updatedSvc)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.UpdatePorts([]caas.ServicePort{
+	c.Assert(app.UpdatePorts(context.Background(), []caas.ServicePort{
 		{
 			Name:       "port1",
 			Port:       80,
@@ -1368,6 +1950,25 @@ func (s *applicationSuite) TestUpdatePortsStateless(c *gc.C) {
 	}, false), jc.ErrorIsNil)
 }
 
+func (s *applicationSuite) TestUpdatePortsUsesContext(c *gc.C) {
+	app, ctrl := s.getApp(c, caas.DeploymentStateless, true)
+	defer ctrl.Finish()
+
+	_, err := s.client.CoreV1().Services("test").Create(context.TODO(), getDefaultSvc(), metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "update-ports")
+	gomock.InOrder(
+		s.applier.EXPECT().Apply(gomock.Any()),
+		s.applier.EXPECT().Run(ctx, s.client, false).Return(context.Canceled),
+	)
+	err = app.UpdatePorts(ctx, []caas.ServicePort{
+		{Name: "port1", Port: 80, TargetPort: 8080, Protocol: "TCP"},
+	}, false)
+	c.Assert(errors.Cause(err), gc.Equals, context.Canceled)
+}
+
 func (s *applicationSuite) TestUpdatePortsStateful(c *gc.C) {
 	app, ctrl := s.getApp(c, caas.DeploymentStateful, true)
 	defer ctrl.Finish()
@@ -1389,7 +1990,7 @@ func (s *applicationSuite) TestUpdatePortsStateful(c *gc.C) {
 		s.applier.EXPECT().Apply(resources.NewService("gitlab", "test", updatedSvc)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.UpdatePorts([]caas.ServicePort{
+	c.Assert(app.UpdatePorts(context.Background(), []caas.ServicePort{
 		{
 			Name:       "port1",
 			Port:       80,
@@ -1420,7 +2021,7 @@ func (s *applicationSuite) TestUpdatePortsDaemonUpdate(c *gc.C) {
 		s.applier.EXPECT().Apply(resources.NewService("gitlab", "test", updatedSvc)),
 		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
 	)
-	c.Assert(app.UpdatePorts([]caas.ServicePort{
+	c.Assert(app.UpdatePorts(context.Background(), []caas.ServicePort{
 		{
 			Name:       "port1",
 			Port:       80,
@@ -1430,13 +2031,230 @@ func (s *applicationSuite) TestUpdatePortsDaemonUpdate(c *gc.C) {
 	}, false), jc.ErrorIsNil)
 }
 
-func (s *applicationSuite) TestUnits(c *gc.C) {
+func (s *applicationSuite) TestUnitsImagePullFailure(c *gc.C) {
 	app, _ := s.getApp(c, caas.DeploymentStateful, false)
 
-	for i := 0; i < 4; i++ {
-		podSpec := getPodSpec(c)
-		podSpec.Volumes = append(podSpec.Volumes,
-			corev1.Volume{
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.namespace,
+			Name:      "gitlab-0",
+			Labels:    map[string]string{"app.kubernetes.io/name": "gitlab"},
+		},
+		Spec: getPodSpec(c),
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "charm",
+				Image: "operator/image-path",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+			}, {
+				Name:  "gitlab",
+				Image: "gitlab-image:latest",
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: `Back-off pulling image "gitlab-image:latest"`,
+					},
+				},
+			}},
+		},
+	}
+	_, err := s.client.CoreV1().Pods(s.namespace).Create(context.TODO(), &pod, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	units, err := app.Units(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+	c.Assert(units[0].Status.Status, gc.Equals, status.Error)
+	c.Assert(units[0].Status.Message, gc.Equals,
+		`image pull failed for container "gitlab": ImagePullBackOff "gitlab-image:latest": Back-off pulling image "gitlab-image:latest"`)
+}
+
+func (s *applicationSuite) TestUnitsRestartCount(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.namespace,
+			Name:      "gitlab-0",
+			Labels:    map[string]string{"app.kubernetes.io/name": "gitlab"},
+		},
+		Spec: getPodSpec(c),
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "charm",
+				RestartCount: 1,
+			}, {
+				Name:         "gitlab",
+				RestartCount: 3,
+			}},
+		},
+	}
+	_, err := s.client.CoreV1().Pods(s.namespace).Create(context.TODO(), &pod, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	units, err := app.Units(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+	c.Assert(units[0].RestartCount, gc.Equals, 4)
+}
+
+func (s *applicationSuite) TestUnitsPartialFailure(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+
+	for _, name := range []string{"gitlab-0", "gitlab-1"} {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      name,
+				Labels:    map[string]string{"app.kubernetes.io/name": "gitlab"},
+			},
+			Spec:   getPodSpec(c),
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		_, err := s.client.CoreV1().Pods(s.namespace).Create(context.TODO(), &pod, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	s.client.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		fieldSelector := action.(k8stesting.ListAction).GetListRestrictions().Fields
+		if name, _ := fieldSelector.RequiresExactMatch("involvedObject.name"); name == "gitlab-1" {
+			return true, nil, errors.New("boom")
+		}
+		return false, nil, nil
+	})
+
+	units, err := app.Units(context.Background())
+	c.Assert(err, jc.Satisfies, caas.IsUnitsError)
+	c.Assert(err, gc.ErrorMatches, `computing details of 1 unit\(s\): gitlab-1: computing status: boom`)
+	statuses := make(map[string]status.Status)
+	for _, u := range units {
+		statuses[u.Id] = u.Status.Status
+	}
+	c.Assert(statuses, jc.DeepEquals, map[string]status.Status{
+		"gitlab-0": status.Running,
+		"gitlab-1": status.Unknown,
+	})
+}
+
+func (s *applicationSuite) TestUnitsQoSClass(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+
+	for name, qosClass := range map[string]corev1.PodQOSClass{
+		"gitlab-0": corev1.PodQOSGuaranteed,
+		"gitlab-1": corev1.PodQOSBestEffort,
+		"gitlab-2": "",
+	} {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      name,
+				Labels:    map[string]string{"app.kubernetes.io/name": "gitlab"},
+			},
+			Spec: getPodSpec(c),
+			Status: corev1.PodStatus{
+				Phase:    corev1.PodRunning,
+				QOSClass: qosClass,
+			},
+		}
+		_, err := s.client.CoreV1().Pods(s.namespace).Create(context.TODO(), &pod, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	units, err := app.Units(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	qosClasses := make(map[string]string)
+	for _, u := range units {
+		qosClasses[u.Id] = u.QoSClass
+	}
+	c.Assert(qosClasses, jc.DeepEquals, map[string]string{
+		"gitlab-0": "Guaranteed",
+		"gitlab-1": "BestEffort",
+		"gitlab-2": "",
+	})
+}
+
+func (s *applicationSuite) TestUnitsFilesystemCapacity(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+
+	for i := 0; i < 2; i++ {
+		podSpec := getPodSpec(c)
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "gitlab-database-appuuid",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: fmt.Sprintf("gitlab-database-appuuid-gitlab-%d", i),
+				},
+			},
+		})
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      fmt.Sprintf("%s-%d", s.appName, i),
+				Labels:    map[string]string{"app.kubernetes.io/name": "gitlab"},
+			},
+			Spec:   podSpec,
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		_, err := s.client.CoreV1().Pods(s.namespace).Create(context.TODO(), &pod, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+
+		pvc := corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      fmt.Sprintf("gitlab-database-appuuid-gitlab-%d", i),
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						"storage": resource.MustParse("1Gi"),
+					},
+				},
+				VolumeName: fmt.Sprintf("pv-%d", i),
+			},
+		}
+		if i == 0 {
+			// The provisioner rounded up the requested size.
+			pvc.Status = corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{
+					"storage": resource.MustParse("2Gi"),
+				},
+				Phase: corev1.ClaimBound,
+			}
+		} else {
+			pvc.Status.Phase = corev1.ClaimPending
+		}
+		_, err = s.client.CoreV1().PersistentVolumeClaims(s.namespace).Create(context.TODO(), &pvc, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	_, err := s.client.CoreV1().PersistentVolumes().Create(context.TODO(), &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-0"},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{
+				"storage": resource.MustParse("2Gi"),
+			},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	units, err := app.Units(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 2)
+	c.Assert(units[0].FilesystemInfo, gc.HasLen, 1)
+	c.Assert(units[0].FilesystemInfo[0].Size, gc.Equals, uint64(2048))
+	c.Assert(units[0].FilesystemInfo[0].Volume.Size, gc.Equals, uint64(2048))
+	c.Assert(units[1].FilesystemInfo, gc.HasLen, 0)
+}
+
+func (s *applicationSuite) TestUnits(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+
+	for i := 0; i < 4; i++ {
+		podSpec := getPodSpec(c)
+		podSpec.Volumes = append(podSpec.Volumes,
+			corev1.Volume{
 				Name: "gitlab-database-appuuid",
 				VolumeSource: corev1.VolumeSource{
 					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
@@ -1522,7 +2340,7 @@ func (s *applicationSuite) TestUnits(c *gc.C) {
 		c.Assert(err, jc.ErrorIsNil)
 	}
 
-	units, err := app.Units()
+	units, err := app.Units(context.Background())
 	c.Assert(err, jc.ErrorIsNil)
 
 	mc := jc.NewMultiChecker()
@@ -1752,6 +2570,1703 @@ func (s *applicationSuite) TestEnsureConstraints(c *gc.C) {
 	)
 }
 
+func (s *applicationSuite) ensureStatefulPodSpec(c *gc.C, config caas.ApplicationConfig) corev1.PodSpec {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), config), jc.ErrorIsNil)
+
+	ss, err := s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	return ss.Spec.Template.Spec
+}
+
+func (s *applicationSuite) TestEnsureContainerResourcesFromCharm(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Resources: caas.ContainerResources{
+					Requests: map[string]string{"cpu": "500m", "memory": "256Mi"},
+					Limits:   map[string]string{"memory": "512Mi"},
+				},
+			},
+		},
+	})
+	c.Assert(ps.Containers[0].Resources, gc.DeepEquals, corev1.ResourceRequirements{})
+	c.Assert(ps.Containers[1].Resources, gc.DeepEquals, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	})
+}
+
+func (s *applicationSuite) TestEnsureContainerResourcesFromConstraints(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {Name: "gitlab"},
+		},
+		Constraints: constraints.MustParse("mem=1G cpu-power=1000"),
+	})
+	c.Assert(ps.Containers[0].Resources, gc.DeepEquals, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1000m"),
+			corev1.ResourceMemory: resource.MustParse("1024Mi"),
+		},
+	})
+	c.Assert(ps.Containers[1].Resources, gc.DeepEquals, corev1.ResourceRequirements{})
+}
+
+func (s *applicationSuite) TestEnsureContainerResourcesKeptWithConstraints(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Resources: caas.ContainerResources{
+					Requests: map[string]string{"cpu": "500m", "memory": "256Mi"},
+					Limits:   map[string]string{"cpu": "1", "memory": "512Mi"},
+				},
+			},
+		},
+		Constraints: constraints.MustParse("mem=1G"),
+	})
+	// The constraint applies to the charm container...
+	c.Assert(ps.Containers[0].Resources, gc.DeepEquals, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1024Mi"),
+		},
+	})
+	// ...and the workload container keeps what the charm declared.
+	c.Assert(ps.Containers[1].Resources, gc.DeepEquals, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	})
+}
+
+func (s *applicationSuite) TestEnsureContainerResourcesInvalidQuantity(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Resources: caas.ContainerResources{
+					Requests: map[string]string{"memory": "lots"},
+				},
+			},
+		},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*memory request "lots" for container "gitlab" not valid`)
+}
+
+func (s *applicationSuite) TestEnsureContainerEntrypoint(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name:       "gitlab",
+				Command:    []string{"/docker-entrypoint.sh"},
+				Args:       []string{"--pebble", "/charm/bin/pebble"},
+				WorkingDir: "/srv/gitlab",
+			},
+			"nginx": {
+				Name: "nginx",
+				Args: []string{"run", "--hold"},
+			},
+			"redis": {
+				Name: "redis",
+			},
+		},
+	})
+	c.Assert(ps.Containers, gc.HasLen, 4)
+	gitlab := ps.Containers[1]
+	c.Assert(gitlab.Name, gc.Equals, "gitlab")
+	c.Assert(gitlab.Command, gc.DeepEquals, []string{"/docker-entrypoint.sh"})
+	c.Assert(gitlab.Args, gc.DeepEquals, []string{"--pebble", "/charm/bin/pebble"})
+	c.Assert(gitlab.WorkingDir, gc.Equals, "/srv/gitlab")
+	// Pebble remains available to the overridden entrypoint.
+	c.Assert(gitlab.Env, jc.DeepEquals, []corev1.EnvVar{
+		{Name: "JUJU_CONTAINER_NAME", Value: "gitlab"},
+		{Name: "PEBBLE_SOCKET", Value: "/charm/container/pebble.socket"},
+	})
+	c.Assert(gitlab.VolumeMounts, gc.HasLen, 2)
+	c.Assert(gitlab.VolumeMounts[0].MountPath, gc.Equals, "/charm/bin/pebble")
+	c.Assert(gitlab.VolumeMounts[1].MountPath, gc.Equals, "/charm/container")
+
+	nginx := ps.Containers[2]
+	c.Assert(nginx.Name, gc.Equals, "nginx")
+	c.Assert(nginx.Command, gc.DeepEquals, []string{"/charm/bin/pebble"})
+	c.Assert(nginx.Args, gc.DeepEquals, []string{"run", "--hold"})
+
+	redis := ps.Containers[3]
+	c.Assert(redis.Name, gc.Equals, "redis")
+	c.Assert(redis.Command, gc.DeepEquals, []string{"/charm/bin/pebble"})
+	c.Assert(redis.Args, gc.DeepEquals, []string{"run", "--create-dirs", "--hold"})
+	c.Assert(redis.WorkingDir, gc.Equals, "")
+}
+
+func (s *applicationSuite) TestEnsureContainerNameInvalid(c *gc.C) {
+	for i, t := range []struct {
+		containers map[string]caas.ContainerConfig
+		err        string
+	}{{
+		containers: map[string]caas.ContainerConfig{"charm": {Name: "charm"}},
+		err:        `.*container name "charm" is reserved`,
+	}, {
+		containers: map[string]caas.ContainerConfig{"charm-init": {Name: "charm-init"}},
+		err:        `.*container name "charm-init" is reserved`,
+	}, {
+		containers: map[string]caas.ContainerConfig{"Git_Lab": {Name: "Git_Lab"}},
+		err:        `.*container name "Git_Lab" not valid`,
+	}, {
+		containers: map[string]caas.ContainerConfig{
+			"gitlab":  {Name: "gitlab"},
+			"gitlab2": {Name: "gitlab"},
+		},
+		err: `.*duplicate container name "gitlab" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		app, _ := s.getApp(c, caas.DeploymentStateful, false)
+		err := app.Ensure(context.Background(), caas.ApplicationConfig{
+			Containers: t.containers,
+		})
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *applicationSuite) TestEnsureContainerEnv(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Env: []caas.EnvVar{{
+					Name:  "GITLAB_HOST",
+					Value: "gitlab.example.com",
+				}, {
+					Name:  "PEBBLE_SOCKET",
+					Value: "/tmp/pebble.socket",
+				}, {
+					Name: "DB_PASSWORD",
+					ValueFrom: &caas.EnvVarSource{
+						SecretName: "gitlab-db",
+						Key:        "password",
+					},
+				}},
+				EnvFrom: []caas.EnvFromSource{{
+					ConfigMapName: "gitlab-config",
+				}, {
+					SecretName: "gitlab-tokens",
+					Prefix:     "TOKEN_",
+				}},
+			},
+		},
+	})
+	gitlab := ps.Containers[1]
+	c.Assert(gitlab.Name, gc.Equals, "gitlab")
+	c.Assert(gitlab.Env, gc.DeepEquals, []corev1.EnvVar{{
+		Name:  "JUJU_CONTAINER_NAME",
+		Value: "gitlab",
+	}, {
+		Name:  "PEBBLE_SOCKET",
+		Value: "/charm/container/pebble.socket",
+	}, {
+		Name:  "GITLAB_HOST",
+		Value: "gitlab.example.com",
+	}, {
+		Name: "DB_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "gitlab-db"},
+				Key:                  "password",
+			},
+		},
+	}})
+	c.Assert(gitlab.EnvFrom, gc.DeepEquals, []corev1.EnvFromSource{{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "gitlab-config"},
+		},
+	}, {
+		Prefix: "TOKEN_",
+		SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "gitlab-tokens"},
+		},
+	}})
+}
+
+func (s *applicationSuite) TestEnsureContainerEnvInvalidSource(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				EnvFrom: []caas.EnvFromSource{{
+					ConfigMapName: "gitlab-config",
+					SecretName:    "gitlab-tokens",
+				}},
+			},
+		},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*container "gitlab": environment source requires exactly one of config map or secret`)
+}
+
+func (s *applicationSuite) TestEnsureProbeTiming(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {Name: "gitlab"},
+		},
+		ProbeTiming: &caas.ProbeTiming{
+			InitialDelay:            time.Minute,
+			Period:                  20 * time.Second,
+			Timeout:                 5 * time.Second,
+			FailureThreshold:        3,
+			StartupFailureThreshold: 60,
+		},
+	})
+	probe := func(path string, failure int32) *corev1.Probe {
+		return &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: path,
+					Port: intstr.Parse(constants.AgentHTTPProbePort),
+				},
+			},
+			InitialDelaySeconds: 60,
+			PeriodSeconds:       20,
+			TimeoutSeconds:      5,
+			SuccessThreshold:    1,
+			FailureThreshold:    failure,
+		}
+	}
+	charm := ps.Containers[0]
+	c.Assert(charm.Name, gc.Equals, "charm")
+	c.Assert(charm.LivenessProbe, gc.DeepEquals, probe(constants.AgentHTTPPathLiveness, 3))
+	c.Assert(charm.ReadinessProbe, gc.DeepEquals, probe(constants.AgentHTTPPathReadiness, 3))
+	c.Assert(charm.StartupProbe, gc.DeepEquals, probe(constants.AgentHTTPPathStartup, 60))
+}
+
+func (s *applicationSuite) TestEnsureProbeTimingImagePullAllowance(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		ProbeTiming: &caas.ProbeTiming{
+			Period:             20 * time.Second,
+			ImagePullAllowance: 5*time.Minute + time.Second,
+		},
+	})
+	charm := ps.Containers[0]
+	c.Assert(charm.Name, gc.Equals, "charm")
+	// The default 30 failures plus 16 periods to cover the allowance.
+	c.Assert(charm.StartupProbe.FailureThreshold, gc.Equals, int32(46))
+	c.Assert(charm.StartupProbe.PeriodSeconds, gc.Equals, int32(20))
+	c.Assert(charm.LivenessProbe.FailureThreshold, gc.Equals, int32(2))
+	c.Assert(charm.ReadinessProbe.FailureThreshold, gc.Equals, int32(2))
+}
+
+func (s *applicationSuite) TestEnsureAgentProbePort(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		AgentProbePort: 9856,
+	})
+	charm := ps.Containers[0]
+	c.Assert(charm.Name, gc.Equals, "charm")
+	c.Assert(charm.Env, jc.DeepEquals, []corev1.EnvVar{{
+		Name:  "JUJU_CONTAINER_NAMES",
+		Value: "",
+	}, {
+		Name:  constants.EnvAgentHTTPProbePort,
+		Value: "9856",
+	}})
+	for _, probe := range []*corev1.Probe{charm.LivenessProbe, charm.ReadinessProbe, charm.StartupProbe} {
+		c.Assert(probe.HTTPGet.Port, gc.Equals, intstr.FromInt(9856))
+	}
+}
+
+func (s *applicationSuite) TestEnsureAgentProbePortDefault(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{})
+	charm := ps.Containers[0]
+	c.Assert(charm.Env, jc.DeepEquals, []corev1.EnvVar{{
+		Name:  "JUJU_CONTAINER_NAMES",
+		Value: "",
+	}, {
+		Name:  constants.EnvAgentHTTPProbePort,
+		Value: constants.AgentHTTPProbePort,
+	}})
+	for _, probe := range []*corev1.Probe{charm.LivenessProbe, charm.ReadinessProbe, charm.StartupProbe} {
+		c.Assert(probe.HTTPGet.Port, gc.Equals, intstr.Parse(constants.AgentHTTPProbePort))
+	}
+}
+
+func (s *applicationSuite) TestEnsureAgentProbePortInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{AgentProbePort: 70000})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*agent probe port 70000 not valid`)
+}
+
+func (s *applicationSuite) TestEnsureDisruptionBudget(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{
+		DisruptionBudget: &caas.DisruptionBudget{MinAvailable: "2"},
+	}), jc.ErrorIsNil)
+
+	pdb, err := s.client.PolicyV1beta1().PodDisruptionBudgets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	minAvailable := intstr.FromInt(2)
+	c.Assert(pdb.Spec, gc.DeepEquals, policyv1beta1.PodDisruptionBudgetSpec{
+		MinAvailable: &minAvailable,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app.kubernetes.io/name": "gitlab"},
+		},
+	})
+
+	// Removing the budget from the config removes the pod disruption budget.
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+	_, err = s.client.PolicyV1beta1().PodDisruptionBudgets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+}
+
+func (s *applicationSuite) TestEnsureDisruptionBudgetPercentage(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{
+		DisruptionBudget: &caas.DisruptionBudget{MaxUnavailable: "25%"},
+	}), jc.ErrorIsNil)
+
+	pdb, err := s.client.PolicyV1beta1().PodDisruptionBudgets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	maxUnavailable := intstr.FromString("25%")
+	c.Assert(pdb.Spec.MaxUnavailable, gc.DeepEquals, &maxUnavailable)
+	c.Assert(pdb.Spec.MinAvailable, gc.IsNil)
+}
+
+func (s *applicationSuite) TestEnsureDisruptionBudgetInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		DisruptionBudget: &caas.DisruptionBudget{MinAvailable: "1", MaxUnavailable: "1"},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, "disruption budget requires exactly one of min available or max unavailable")
+}
+
+func (s *applicationSuite) TestEnsureImmutableSelectorChanged(c *gc.C) {
+	_, err := s.client.AppsV1().StatefulSets("test").Create(context.TODO(), &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gitlab",
+			Namespace: "test",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"juju-app": "gitlab"},
+			},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err = app.Ensure(context.Background(), caas.ApplicationConfig{})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `cannot update immutable field\(s\) selector of statefulset "gitlab": `+
+		`the application needs to be removed and deployed again for this change to take effect`)
+}
+
+func (s *applicationSuite) TestEnsureImmutableVolumeClaimTemplateChanged(c *gc.C) {
+	config := func(size uint64) caas.ApplicationConfig {
+		return caas.ApplicationConfig{
+			Filesystems: []storage.KubernetesFilesystemParams{{
+				StorageName: "database",
+				Size:        size,
+				Provider:    "kubernetes",
+				Attributes:  map[string]interface{}{"storage-class": "workload-storage"},
+				Attachment: &storage.KubernetesFilesystemAttachmentParams{
+					Path: "path/to/here",
+				},
+			}},
+		}
+	}
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), config(100)), jc.ErrorIsNil)
+
+	err := app.Ensure(context.Background(), config(200))
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `cannot update immutable field\(s\) volumeClaimTemplates of statefulset "gitlab": .*`)
+
+	// Nothing was changed.
+	ss, err := s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ss.Spec.VolumeClaimTemplates, gc.HasLen, 1)
+	c.Assert(ss.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage], gc.DeepEquals, k8sresource.MustParse("100Mi"))
+}
+
+func (s *applicationSuite) TestEnsureStorageUniqPrefixLegacyAnnotation(c *gc.C) {
+	_, err := s.client.AppsV1().Deployments("test").Create(context.TODO(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "gitlab",
+			Namespace:   "test",
+			Annotations: map[string]string{"juju-app-uuid": "legacyuuid"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app.kubernetes.io/name": "gitlab"},
+			},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes:  map[string]interface{}{"storage-class": "workload-storage"},
+			Attachment: &storage.KubernetesFilesystemAttachmentParams{
+				Path: "path/to/here",
+			},
+		}},
+	}), jc.ErrorIsNil)
+
+	// The existing claim is used rather than one with a new prefix.
+	_, err = s.client.CoreV1().PersistentVolumeClaims("test").Get(context.TODO(), "gitlab-database-legacyuuid", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.client.CoreV1().PersistentVolumeClaims("test").Get(context.TODO(), "gitlab-database-appuuid", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+
+	// The prefix is now recorded under the current annotation key.
+	d, err := s.client.AppsV1().Deployments("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(d.Annotations["app.juju.is/uuid"], gc.Equals, "legacyuuid")
+}
+
+func (s *applicationSuite) assertStorageClassReclaimPolicy(c *gc.C, attrs map[string]interface{}, expected corev1.PersistentVolumeReclaimPolicy) {
+	attrs["storage-class"] = "fast"
+	attrs["storage-provisioner"] = "ebs.csi.aws.com"
+	s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes:  attrs,
+			Attachment: &storage.KubernetesFilesystemAttachmentParams{
+				Path: "path/to/here",
+			},
+		}},
+	})
+	sc, err := s.client.StorageV1().StorageClasses().Get(context.TODO(), "test-fast", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sc.ReclaimPolicy, gc.NotNil)
+	c.Assert(*sc.ReclaimPolicy, gc.Equals, expected)
+}
+
+func (s *applicationSuite) TestEnsureStorageClassReclaimPolicyDefault(c *gc.C) {
+	s.assertStorageClassReclaimPolicy(c, map[string]interface{}{}, corev1.PersistentVolumeReclaimRetain)
+}
+
+func (s *applicationSuite) TestEnsureStorageClassReclaimPolicyRetain(c *gc.C) {
+	s.assertStorageClassReclaimPolicy(c, map[string]interface{}{
+		"storage-reclaim-policy": "Retain",
+	}, corev1.PersistentVolumeReclaimRetain)
+}
+
+func (s *applicationSuite) TestEnsureStorageClassReclaimPolicyDelete(c *gc.C) {
+	s.assertStorageClassReclaimPolicy(c, map[string]interface{}{
+		"storage-reclaim-policy": "Delete",
+	}, corev1.PersistentVolumeReclaimDelete)
+}
+
+func (s *applicationSuite) TestEnsureFilesystemZeroSize(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        0,
+			Provider:    "kubernetes",
+			Attributes:  map[string]interface{}{"storage-class": "workload-storage"},
+			Attachment: &storage.KubernetesFilesystemAttachmentParams{
+				Path: "path/to/here",
+			},
+		}},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*zero size for storage "database" of "gitlab" not valid`)
+
+	// Nothing was applied.
+	_, err = s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+}
+
+func (s *applicationSuite) TestEnsureDuplicateMountPath(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes:  map[string]interface{}{"storage-class": "workload-storage"},
+			Attachment: &storage.KubernetesFilesystemAttachmentParams{
+				Path: "path/to/here",
+			},
+		}, {
+			StorageName: "logs",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes:  map[string]interface{}{"storage-class": "workload-storage"},
+			Attachment: &storage.KubernetesFilesystemAttachmentParams{
+				Path: "path/to/there",
+			},
+		}},
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Mounts: []caas.MountConfig{{
+					StorageName: "database",
+					Path:        "/var/lib/gitlab",
+				}, {
+					StorageName: "logs",
+					Path:        "/var/lib/gitlab",
+				}},
+			},
+		},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*storage "database" and "logs" both mounted at "/var/lib/gitlab" in container "gitlab" not valid`)
+}
+
+func (s *applicationSuite) assertFilesystemMountPath(c *gc.C, strategy, expected string) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes:  map[string]interface{}{"storage-class": "workload-storage"},
+		}},
+		FilesystemMountPathStrategy: strategy,
+	})
+	var mountPaths []string
+	for _, m := range podSpec.Containers[0].VolumeMounts {
+		if m.Name == "gitlab-database-appuuid" {
+			mountPaths = append(mountPaths, m.MountPath)
+		}
+	}
+	c.Assert(mountPaths, jc.DeepEquals, []string{expected})
+}
+
+func (s *applicationSuite) TestEnsureFilesystemMountPathIndexed(c *gc.C) {
+	s.assertFilesystemMountPath(c, "", constants.StorageBaseDir+"/fs/gitlab/database/0")
+}
+
+func (s *applicationSuite) TestEnsureFilesystemMountPathByName(c *gc.C) {
+	s.assertFilesystemMountPath(c, "name", constants.StorageBaseDir+"/fs/database")
+}
+
+func (s *applicationSuite) TestEnsureImagePullSecrets(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		CharmBaseImage: coreresources.DockerImageDetails{
+			RegistryPath: "ubuntu:20.04",
+		},
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Image: coreresources.DockerImageDetails{
+					RegistryPath: "registry.example.com/gitlab:latest",
+					Username:     "jujuqa",
+					Password:     "secret",
+				},
+			},
+		},
+		ImagePullSecrets: []string{"existing-secret"},
+	})
+	c.Assert(ps.ImagePullSecrets, gc.DeepEquals, []corev1.LocalObjectReference{
+		{Name: "gitlab-gitlab-secret"},
+		{Name: "existing-secret"},
+	})
+
+	secret, err := s.client.CoreV1().Secrets("test").Get(context.TODO(), "gitlab-gitlab-secret", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(secret.Type, gc.Equals, corev1.SecretTypeDockerConfigJson)
+	c.Assert(secret.Labels, gc.DeepEquals, map[string]string{
+		"app.kubernetes.io/name":       "gitlab",
+		"app.kubernetes.io/managed-by": "juju",
+	})
+	c.Assert(string(secret.Data[corev1.DockerConfigJsonKey]), gc.Equals,
+		`{"auths":{"registry.example.com":{"Username":"jujuqa","Password":"secret"}}}`)
+
+	// The charm base image is public, so no secret is created for it.
+	_, err = s.client.CoreV1().Secrets("test").Get(context.TODO(), "gitlab-charm-secret", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+}
+
+func (s *applicationSuite) TestDeleteImagePullSecrets(c *gc.C) {
+	app, ctrl := s.getApp(c, caas.DeploymentStateless, true)
+	defer ctrl.Finish()
+
+	for _, secret := range []corev1.Secret{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "gitlab-gitlab-secret",
+			Labels: map[string]string{"app.kubernetes.io/name": "gitlab"},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "gitlab-application-config",
+			Labels: map[string]string{"app.kubernetes.io/name": "gitlab"},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "other-gitlab-secret",
+			Labels: map[string]string{"app.kubernetes.io/name": "other"},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}} {
+		_, err := s.client.CoreV1().Secrets("test").Create(context.TODO(), &secret, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	gomock.InOrder(
+		s.applier.EXPECT().Delete(resources.NewDeployment("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewPodDisruptionBudget("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewService("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewSecret("gitlab-application-config", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRoleBinding("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRole("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewServiceAccount("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewSecret("gitlab-gitlab-secret", "test", nil)),
+		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
+	)
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{}), jc.ErrorIsNil)
+}
+
+func (s *applicationSuite) TestEnsureImagePullPolicy(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		CharmImagePullPolicy: "Always",
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {Name: "gitlab", ImagePullPolicy: "Never"},
+			"nginx":  {Name: "nginx"},
+		},
+	})
+	c.Assert(ps.InitContainers[0].ImagePullPolicy, gc.Equals, corev1.PullAlways)
+	c.Assert(ps.Containers, gc.HasLen, 3)
+	c.Assert(ps.Containers[0].Name, gc.Equals, "charm")
+	c.Assert(ps.Containers[0].ImagePullPolicy, gc.Equals, corev1.PullAlways)
+	c.Assert(ps.Containers[1].Name, gc.Equals, "gitlab")
+	c.Assert(ps.Containers[1].ImagePullPolicy, gc.Equals, corev1.PullNever)
+	c.Assert(ps.Containers[2].Name, gc.Equals, "nginx")
+	c.Assert(ps.Containers[2].ImagePullPolicy, gc.Equals, corev1.PullIfNotPresent)
+}
+
+func (s *applicationSuite) TestEnsureImagePullPolicyInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {Name: "gitlab", ImagePullPolicy: "Sometimes"},
+		},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*container "gitlab": image pull policy "Sometimes" not valid`)
+
+	err = app.Ensure(context.Background(), caas.ApplicationConfig{CharmImagePullPolicy: "always"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*charm image: image pull policy "always" not valid`)
+}
+
+func (s *applicationSuite) TestEnsureWorkloadContainerProbes(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Probes: caas.ContainerProbes{
+					Liveness: &caas.ContainerProbe{
+						HTTPGet:          &caas.HTTPGetProbe{Path: "/health", Port: 8080, Scheme: "https"},
+						Period:           20 * time.Second,
+						FailureThreshold: 5,
+					},
+					Readiness: &caas.ContainerProbe{
+						TCPSocket: &caas.TCPSocketProbe{Port: 5432},
+						Timeout:   5 * time.Second,
+					},
+					Startup: &caas.ContainerProbe{
+						Exec:         &caas.ExecProbe{Command: []string{"pg_isready"}},
+						InitialDelay: 10 * time.Second,
+					},
+				},
+			},
+			"nginx": {Name: "nginx"},
+		},
+	})
+	c.Assert(ps.Containers, gc.HasLen, 3)
+	gitlab := ps.Containers[1]
+	c.Assert(gitlab.Name, gc.Equals, "gitlab")
+	c.Assert(gitlab.LivenessProbe, jc.DeepEquals, &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/health",
+				Port:   intstr.FromInt(8080),
+				Scheme: corev1.URISchemeHTTPS,
+			},
+		},
+		PeriodSeconds:    20,
+		FailureThreshold: 5,
+	})
+	c.Assert(gitlab.ReadinessProbe, jc.DeepEquals, &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(5432)},
+		},
+		TimeoutSeconds: 5,
+	})
+	c.Assert(gitlab.StartupProbe, jc.DeepEquals, &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"pg_isready"}},
+		},
+		InitialDelaySeconds: 10,
+	})
+
+	// Containers without probes are unchanged.
+	nginx := ps.Containers[2]
+	c.Assert(nginx.Name, gc.Equals, "nginx")
+	c.Assert(nginx.LivenessProbe, gc.IsNil)
+	c.Assert(nginx.ReadinessProbe, gc.IsNil)
+	c.Assert(nginx.StartupProbe, gc.IsNil)
+}
+
+func (s *applicationSuite) TestEnsureWorkloadContainerProbeInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Probes: caas.ContainerProbes{
+					Readiness: &caas.ContainerProbe{
+						TCPSocket: &caas.TCPSocketProbe{Port: 5432},
+						Exec:      &caas.ExecProbe{Command: []string{"pg_isready"}},
+					},
+				},
+			},
+		},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*container "gitlab" readiness: probe requires exactly one of http get, tcp socket or exec`)
+}
+
+func (s *applicationSuite) TestEnsureWorkloadContainerLifecycle(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {
+				Name: "gitlab",
+				Lifecycle: caas.ContainerLifecycle{
+					PostStart: &caas.LifecycleHandler{
+						HTTPGet: &caas.HTTPGetProbe{Path: "/warm", Port: 8080},
+					},
+					PreStop: &caas.LifecycleHandler{
+						Exec: &caas.ExecProbe{Command: []string{"/bin/drain", "--timeout=30s"}},
+					},
+				},
+			},
+			"nginx": {Name: "nginx"},
+		},
+	})
+	c.Assert(ps.Containers, gc.HasLen, 3)
+	gitlab := ps.Containers[1]
+	c.Assert(gitlab.Name, gc.Equals, "gitlab")
+	c.Assert(gitlab.Lifecycle, jc.DeepEquals, &corev1.Lifecycle{
+		PostStart: &corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/warm",
+				Port:   intstr.FromInt(8080),
+				Scheme: corev1.URISchemeHTTP,
+			},
+		},
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/drain", "--timeout=30s"}},
+		},
+	})
+
+	// Containers without hooks are unchanged.
+	nginx := ps.Containers[2]
+	c.Assert(nginx.Name, gc.Equals, "nginx")
+	c.Assert(nginx.Lifecycle, gc.IsNil)
+}
+
+func (s *applicationSuite) TestEnsureWorkloadContainerLifecycleInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	for i, t := range []struct {
+		lifecycle caas.ContainerLifecycle
+		err       string
+	}{{
+		lifecycle: caas.ContainerLifecycle{
+			PreStop: &caas.LifecycleHandler{
+				HTTPGet: &caas.HTTPGetProbe{Path: "/drain", Port: 8080},
+				Exec:    &caas.ExecProbe{Command: []string{"/bin/drain"}},
+			},
+		},
+		err: `.*container "gitlab": pre stop: lifecycle hook requires exactly one of http get or exec`,
+	}, {
+		lifecycle: caas.ContainerLifecycle{
+			PostStart: &caas.LifecycleHandler{},
+		},
+		err: `.*container "gitlab": post start: lifecycle hook requires exactly one of http get or exec`,
+	}, {
+		lifecycle: caas.ContainerLifecycle{
+			PreStop: &caas.LifecycleHandler{Exec: &caas.ExecProbe{}},
+		},
+		err: `.*container "gitlab": pre stop: empty hook command not valid`,
+	}, {
+		lifecycle: caas.ContainerLifecycle{
+			PreStop: &caas.LifecycleHandler{
+				HTTPGet: &caas.HTTPGetProbe{Path: "/drain", Port: 8080, Scheme: "ftp"},
+			},
+		},
+		err: `.*container "gitlab": pre stop: http get scheme "ftp" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		err := app.Ensure(context.Background(), caas.ApplicationConfig{
+			Containers: map[string]caas.ContainerConfig{
+				"gitlab": {Name: "gitlab", Lifecycle: t.lifecycle},
+			},
+		})
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *applicationSuite) TestEnsureUpdateStrategyDefault(c *gc.C) {
+	s.ensureStatefulPodSpec(c, caas.ApplicationConfig{})
+	ss, err := s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ss.Spec.UpdateStrategy, jc.DeepEquals, appsv1.StatefulSetUpdateStrategy{})
+}
+
+func (s *applicationSuite) TestEnsureUpdateStrategyStateful(c *gc.C) {
+	s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		UpdateStrategy: &caas.UpdateStrategy{Type: "OnDelete"},
+	})
+	ss, err := s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ss.Spec.UpdateStrategy, jc.DeepEquals, appsv1.StatefulSetUpdateStrategy{
+		Type: appsv1.OnDeleteStatefulSetStrategyType,
+	})
+}
+
+func (s *applicationSuite) TestEnsureUpdateStrategyStatefulRecreate(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		UpdateStrategy: &caas.UpdateStrategy{Type: "Recreate"},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `update strategy "Recreate" for stateful applications not valid`)
+}
+
+func (s *applicationSuite) TestEnsureUpdateStrategyStateless(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{
+		UpdateStrategy: &caas.UpdateStrategy{Type: "Recreate"},
+	}), jc.ErrorIsNil)
+	d, err := s.client.AppsV1().Deployments("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(d.Spec.Strategy, jc.DeepEquals, appsv1.DeploymentStrategy{
+		Type: appsv1.RecreateDeploymentStrategyType,
+	})
+
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{
+		UpdateStrategy: &caas.UpdateStrategy{
+			Type:           "RollingUpdate",
+			MaxUnavailable: "1",
+			MaxSurge:       "50%",
+		},
+	}), jc.ErrorIsNil)
+	d, err = s.client.AppsV1().Deployments("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	maxUnavailable := intstr.FromInt(1)
+	maxSurge := intstr.FromString("50%")
+	c.Assert(d.Spec.Strategy, jc.DeepEquals, appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: &maxUnavailable,
+			MaxSurge:       &maxSurge,
+		},
+	})
+}
+
+func (s *applicationSuite) TestEnsureUpdateStrategyStatelessOnDelete(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		UpdateStrategy: &caas.UpdateStrategy{Type: "OnDelete"},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `update strategy "OnDelete" for stateless applications not valid`)
+}
+
+func (s *applicationSuite) TestEnsureAffinityAndTolerations(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Constraints: constraints.MustParse("arch=arm64 tags=pool=gpu|fast,^dedicated=other zones=a,b"),
+		Tolerations: []caas.Toleration{{
+			Key:      "nvidia.com/gpu",
+			Operator: "Exists",
+			Effect:   "NoSchedule",
+		}},
+	})
+	// The arch node selector is kept alongside the affinity.
+	c.Assert(ps.NodeSelector, gc.DeepEquals, map[string]string{"kubernetes.io/arch": "arm64"})
+	c.Assert(ps.Affinity, gc.DeepEquals, &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      "pool",
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"gpu", "fast"},
+					}, {
+						Key:      "dedicated",
+						Operator: corev1.NodeSelectorOpNotIn,
+						Values:   []string{"other"},
+					}, {
+						Key:      "failure-domain.beta.kubernetes.io/zone",
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"a", "b"},
+					}},
+				}},
+			},
+		},
+	})
+	c.Assert(ps.Tolerations, gc.DeepEquals, []corev1.Toleration{{
+		Key:      "nvidia.com/gpu",
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}})
+}
+
+func (s *applicationSuite) TestEnsureTolerationInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Tolerations: []caas.Toleration{{Key: "dedicated", Effect: "NoScheduleEver"}},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*toleration effect "NoScheduleEver" not valid`)
+}
+
+func (s *applicationSuite) TestEnsureTopologySpreadConstraints(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		TopologySpreadConstraints: []caas.TopologySpreadConstraint{{
+			TopologyKey: "topology.kubernetes.io/zone",
+			MaxSkew:     1,
+		}, {
+			TopologyKey:       "kubernetes.io/hostname",
+			MaxSkew:           2,
+			WhenUnsatisfiable: "ScheduleAnyway",
+		}},
+	})
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"app.kubernetes.io/name": "gitlab"},
+	}
+	c.Assert(ps.TopologySpreadConstraints, gc.DeepEquals, []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       "topology.kubernetes.io/zone",
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+		LabelSelector:     selector,
+	}, {
+		MaxSkew:           2,
+		TopologyKey:       "kubernetes.io/hostname",
+		WhenUnsatisfiable: corev1.ScheduleAnyway,
+		LabelSelector:     selector,
+	}})
+}
+
+func (s *applicationSuite) TestEnsureTopologySpreadConstraintsStateless(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{
+		TopologySpreadConstraints: []caas.TopologySpreadConstraint{{
+			TopologyKey: "topology.kubernetes.io/zone",
+			MaxSkew:     1,
+		}},
+	}), jc.ErrorIsNil)
+
+	d, err := s.client.AppsV1().Deployments("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	spread := d.Spec.Template.Spec.TopologySpreadConstraints
+	c.Assert(spread, gc.HasLen, 1)
+	c.Assert(spread[0].TopologyKey, gc.Equals, "topology.kubernetes.io/zone")
+}
+
+func (s *applicationSuite) TestEnsureTopologySpreadConstraintsInvalidMaxSkew(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		TopologySpreadConstraints: []caas.TopologySpreadConstraint{{
+			TopologyKey: "topology.kubernetes.io/zone",
+		}},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*topology spread constraint max skew 0 for "topology.kubernetes.io/zone" not valid`)
+}
+
+func (s *applicationSuite) TestEnsureTerminationGracePeriod(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{})
+	c.Assert(ps.TerminationGracePeriodSeconds, gc.IsNil)
+
+	period := 2*time.Minute + 500*time.Millisecond
+	ps = s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		TerminationGracePeriod: &period,
+	})
+	// The period is rounded up to whole seconds.
+	c.Assert(ps.TerminationGracePeriodSeconds, gc.NotNil)
+	c.Assert(*ps.TerminationGracePeriodSeconds, gc.Equals, int64(121))
+}
+
+func (s *applicationSuite) TestEnsureTerminationGracePeriodNegative(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	period := -time.Second
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		TerminationGracePeriod: &period,
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*negative termination grace period -1s not valid`)
+}
+
+func (s *applicationSuite) TestEnsureDNSDefault(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{})
+	c.Assert(ps.DNSPolicy, gc.Equals, corev1.DNSPolicy(""))
+	c.Assert(ps.DNSConfig, gc.IsNil)
+
+	ps = s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		DNS: &caas.DNSConfig{},
+	})
+	c.Assert(ps.DNSPolicy, gc.Equals, corev1.DNSClusterFirst)
+	c.Assert(ps.DNSConfig, gc.IsNil)
+}
+
+func (s *applicationSuite) TestEnsureDNSConfig(c *gc.C) {
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		DNS: &caas.DNSConfig{
+			Policy:      "None",
+			Nameservers: []string{"10.0.0.10"},
+			Searches:    []string{"svc.cluster.local"},
+			Options: []caas.DNSOption{
+				{Name: "ndots", Value: "2"},
+				{Name: "edns0"},
+			},
+		},
+	})
+	c.Assert(ps.DNSPolicy, gc.Equals, corev1.DNSNone)
+	c.Assert(ps.DNSConfig, gc.DeepEquals, &corev1.PodDNSConfig{
+		Nameservers: []string{"10.0.0.10"},
+		Searches:    []string{"svc.cluster.local"},
+		Options: []corev1.PodDNSConfigOption{
+			{Name: "ndots", Value: application.StrPtr("2")},
+			{Name: "edns0"},
+		},
+	})
+}
+
+func (s *applicationSuite) TestEnsureDNSInvalid(c *gc.C) {
+	for i, t := range []struct {
+		dns *caas.DNSConfig
+		err string
+	}{{
+		dns: &caas.DNSConfig{Policy: "Mirror"},
+		err: `.*DNS policy "Mirror" not valid`,
+	}, {
+		dns: &caas.DNSConfig{Policy: "ClusterFirst", Nameservers: []string{"10.0.0.10"}},
+		err: `.*DNS resolver config with "ClusterFirst" policy not valid`,
+	}, {
+		dns: &caas.DNSConfig{Policy: "None", Searches: []string{"svc.cluster.local"}},
+		err: `.*"None" DNS policy without nameservers not valid`,
+	}} {
+		c.Logf("test %d", i)
+		app, _ := s.getApp(c, caas.DeploymentStateful, false)
+		err := app.Ensure(context.Background(), caas.ApplicationConfig{DNS: t.dns})
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *applicationSuite) TestEnsureHostAliases(c *gc.C) {
+	config := caas.ApplicationConfig{
+		HostAliases: []caas.HostAlias{{
+			IP:        "10.1.2.3",
+			Hostnames: []string{"legacy.example.com", "legacy"},
+		}},
+	}
+	expected := []corev1.HostAlias{{
+		IP:        "10.1.2.3",
+		Hostnames: []string{"legacy.example.com", "legacy"},
+	}}
+
+	ps := s.ensureStatefulPodSpec(c, config)
+	c.Assert(ps.HostAliases, gc.DeepEquals, expected)
+
+	app, _ := s.getApp(c, caas.DeploymentStateless, false)
+	c.Assert(app.Ensure(context.Background(), config), jc.ErrorIsNil)
+	d, err := s.client.AppsV1().Deployments("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(d.Spec.Template.Spec.HostAliases, gc.DeepEquals, expected)
+
+	app, _ = s.getApp(c, caas.DeploymentDaemon, false)
+	c.Assert(app.Ensure(context.Background(), config), jc.ErrorIsNil)
+	ds, err := s.client.AppsV1().DaemonSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ds.Spec.Template.Spec.HostAliases, gc.DeepEquals, expected)
+}
+
+func (s *applicationSuite) TestEnsureHostAliasesInvalid(c *gc.C) {
+	for i, t := range []struct {
+		alias caas.HostAlias
+		err   string
+	}{{
+		alias: caas.HostAlias{IP: "10.1.2", Hostnames: []string{"legacy"}},
+		err:   `.*host alias IP "10.1.2" not valid`,
+	}, {
+		alias: caas.HostAlias{IP: "10.1.2.3"},
+		err:   `.*host alias for "10.1.2.3" without hostnames not valid`,
+	}, {
+		alias: caas.HostAlias{IP: "10.1.2.3", Hostnames: []string{"Legacy_Host"}},
+		err:   `.*host alias hostname "Legacy_Host" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		app, _ := s.getApp(c, caas.DeploymentStateful, false)
+		err := app.Ensure(context.Background(), caas.ApplicationConfig{
+			HostAliases: []caas.HostAlias{t.alias},
+		})
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *applicationSuite) TestEnsureSecurityContext(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {Name: "gitlab"},
+		},
+		SecurityContext: &caas.SecurityContext{
+			RunAsUser:  int64Ptr(1000),
+			RunAsGroup: int64Ptr(1001),
+			FSGroup:    int64Ptr(1002),
+		},
+	})
+	c.Assert(podSpec.SecurityContext, jc.DeepEquals, &corev1.PodSecurityContext{
+		RunAsUser:  int64Ptr(1000),
+		RunAsGroup: int64Ptr(1001),
+		FSGroup:    int64Ptr(1002),
+	})
+	c.Assert(podSpec.Containers[0].Name, gc.Equals, "charm")
+	c.Assert(podSpec.Containers[0].SecurityContext, jc.DeepEquals, &corev1.SecurityContext{
+		RunAsUser:    int64Ptr(1000),
+		RunAsGroup:   int64Ptr(1001),
+		RunAsNonRoot: application.BoolPtr(true),
+	})
+
+	// Workload containers still run Pebble as root, so they aren't
+	// required to run as non-root.
+	c.Assert(podSpec.Containers[1].Name, gc.Equals, "gitlab")
+	c.Assert(podSpec.Containers[1].SecurityContext, jc.DeepEquals, &corev1.SecurityContext{
+		RunAsUser:  int64Ptr(0),
+		RunAsGroup: int64Ptr(0),
+	})
+}
+
+func (s *applicationSuite) TestEnsureSecurityContextRootless(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		SecurityContext: &caas.SecurityContext{
+			RunAsUser:  int64Ptr(1000),
+			RunAsGroup: int64Ptr(1000),
+		},
+	})
+	// Volume ownership defaults to the charm's group.
+	c.Assert(podSpec.SecurityContext.FSGroup, jc.DeepEquals, int64Ptr(1000))
+
+	expected := &corev1.SecurityContext{
+		RunAsUser:    int64Ptr(1000),
+		RunAsGroup:   int64Ptr(1000),
+		RunAsNonRoot: application.BoolPtr(true),
+	}
+	c.Assert(podSpec.InitContainers[0].Name, gc.Equals, "charm-init")
+	c.Assert(podSpec.InitContainers[0].SecurityContext, jc.DeepEquals, expected)
+	c.Assert(podSpec.Containers[0].Name, gc.Equals, "charm")
+	c.Assert(podSpec.Containers[0].SecurityContext, jc.DeepEquals, expected)
+}
+
+func (s *applicationSuite) TestEnsureSecurityContextDefaultsToRoot(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		SecurityContext: &caas.SecurityContext{
+			FSGroup: int64Ptr(1002),
+		},
+	})
+	c.Assert(podSpec.SecurityContext, jc.DeepEquals, &corev1.PodSecurityContext{
+		FSGroup: int64Ptr(1002),
+	})
+	c.Assert(podSpec.Containers[0].SecurityContext, jc.DeepEquals, &corev1.SecurityContext{
+		RunAsUser:  int64Ptr(0),
+		RunAsGroup: int64Ptr(0),
+	})
+}
+
+func (s *applicationSuite) TestEnsureSecurityContextReadOnlyRootFilesystem(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {Name: "gitlab"},
+		},
+		SecurityContext: &caas.SecurityContext{
+			ReadOnlyRootFilesystem: true,
+		},
+	})
+	expected := &corev1.SecurityContext{
+		RunAsUser:                int64Ptr(0),
+		RunAsGroup:               int64Ptr(0),
+		ReadOnlyRootFilesystem:   application.BoolPtr(true),
+		AllowPrivilegeEscalation: application.BoolPtr(false),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+	c.Assert(podSpec.InitContainers[0].Name, gc.Equals, "charm-init")
+	c.Assert(podSpec.InitContainers[0].SecurityContext, jc.DeepEquals, expected)
+	charm := podSpec.Containers[0]
+	c.Assert(charm.Name, gc.Equals, "charm")
+	c.Assert(charm.SecurityContext, jc.DeepEquals, expected)
+
+	// The charm can still write to its data dirs and temporary files.
+	var writable []string
+	for _, mount := range charm.VolumeMounts {
+		if !mount.ReadOnly {
+			writable = append(writable, mount.MountPath)
+		}
+	}
+	c.Assert(writable, jc.DeepEquals, []string{"/var/lib/juju", "/charm/containers", "/tmp"})
+	c.Assert(charm.VolumeMounts[len(charm.VolumeMounts)-1], jc.DeepEquals, corev1.VolumeMount{
+		Name:      "charm-data",
+		MountPath: "/tmp",
+		SubPath:   "charm/tmp",
+	})
+
+	// Workload containers are unaffected.
+	c.Assert(podSpec.Containers[1].Name, gc.Equals, "gitlab")
+	c.Assert(podSpec.Containers[1].SecurityContext, jc.DeepEquals, &corev1.SecurityContext{
+		RunAsUser:  int64Ptr(0),
+		RunAsGroup: int64Ptr(0),
+	})
+}
+
+func (s *applicationSuite) TestEnsureSecurityContextInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		SecurityContext: &caas.SecurityContext{RunAsUser: int64Ptr(-1)},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*security context ID -1 not valid`)
+}
+
+func (s *applicationSuite) TestEnsureCharmVolumeDefault(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{})
+	c.Assert(podSpec.Volumes, gc.HasLen, 1)
+	c.Assert(podSpec.Volumes[0].EmptyDir, jc.DeepEquals, &corev1.EmptyDirVolumeSource{})
+}
+
+func (s *applicationSuite) TestEnsureCharmVolumeSizeLimit(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		CharmVolume: &caas.CharmVolume{SizeLimit: "1Gi"},
+	})
+	sizeLimit := k8sresource.MustParse("1Gi")
+	c.Assert(podSpec.Volumes, gc.HasLen, 1)
+	c.Assert(podSpec.Volumes[0].Name, gc.Equals, "charm-data")
+	c.Assert(podSpec.Volumes[0].EmptyDir, jc.DeepEquals, &corev1.EmptyDirVolumeSource{
+		SizeLimit: &sizeLimit,
+	})
+}
+
+func (s *applicationSuite) TestEnsureCharmVolumeMemory(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		CharmVolume: &caas.CharmVolume{
+			Medium:    "Memory",
+			SizeLimit: "256Mi",
+		},
+	})
+	sizeLimit := k8sresource.MustParse("256Mi")
+	c.Assert(podSpec.Volumes, gc.HasLen, 1)
+	c.Assert(podSpec.Volumes[0].Name, gc.Equals, "charm-data")
+	c.Assert(podSpec.Volumes[0].EmptyDir, jc.DeepEquals, &corev1.EmptyDirVolumeSource{
+		Medium:    corev1.StorageMediumMemory,
+		SizeLimit: &sizeLimit,
+	})
+}
+
+func (s *applicationSuite) TestEnsureCharmVolumeInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		CharmVolume: &caas.CharmVolume{Medium: "Tape"},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*charm volume medium "Tape" not valid`)
+
+	err = app.Ensure(context.Background(), caas.ApplicationConfig{
+		CharmVolume: &caas.CharmVolume{SizeLimit: "lots"},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*charm volume size limit "lots" not valid`)
+}
+
+func (s *applicationSuite) assertEventReasons(c *gc.C, expected ...string) {
+	events, err := s.client.CoreV1().Events("test").List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	var reasons []string
+	for _, e := range events.Items {
+		c.Check(e.InvolvedObject.Kind, gc.Equals, "StatefulSet")
+		c.Check(e.InvolvedObject.Name, gc.Equals, "gitlab")
+		reasons = append(reasons, e.Reason)
+	}
+	c.Assert(reasons, jc.SameContents, expected)
+}
+
+func (s *applicationSuite) TestEnsureRecordsEvents(c *gc.C) {
+	s.ensureStatefulPodSpec(c, caas.ApplicationConfig{})
+	s.assertEventReasons(c,
+		"ServiceConfigured", "ServiceConfigured", "SecretApplied", "WorkloadApplied",
+	)
+}
+
+func (s *applicationSuite) TestEnsureRecordsFailureEvent(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	cpuArch := "i686"
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Constraints: constraints.Value{Arch: &cpuArch},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	s.assertEventReasons(c, "ServiceConfigured", "EnsureFailed")
+}
+
+func (s *applicationSuite) TestEnsureIgnoresEventFailures(c *gc.C) {
+	s.client.PrependReactor("create", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("boom")
+	})
+	s.ensureStatefulPodSpec(c, caas.ApplicationConfig{})
+}
+
+func (s *applicationSuite) TestEnsurePriorityClassName(c *gc.C) {
+	_, err := s.client.SchedulingV1().PriorityClasses().Create(context.TODO(), &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "juju-critical"},
+		Value:      1000000,
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		PriorityClassName: "juju-critical",
+	})
+	c.Assert(ps.PriorityClassName, gc.Equals, "juju-critical")
+	s.assertEventReasons(c,
+		"ServiceConfigured", "ServiceConfigured", "SecretApplied", "WorkloadApplied",
+	)
+}
+
+func (s *applicationSuite) TestEnsurePriorityClassNameNotFound(c *gc.C) {
+	// The priority class may be created later, so the application is
+	// still ensured.
+	ps := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		PriorityClassName: "juju-critical",
+	})
+	c.Assert(ps.PriorityClassName, gc.Equals, "juju-critical")
+	s.assertEventReasons(c,
+		"ServiceConfigured", "ServiceConfigured", "PriorityClassNotFound", "SecretApplied", "WorkloadApplied",
+	)
+}
+
+func (s *applicationSuite) TestEnsurePriorityClassNameInvalid(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		PriorityClassName: "Juju_Critical",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*priority class name "Juju_Critical" not valid`)
+}
+
+func (s *applicationSuite) TestEnsureDryRun(c *gc.C) {
+	app, ctrl := s.getApp(c, caas.DeploymentStateful, true)
+	defer ctrl.Finish()
+
+	s.applier.EXPECT().Apply(gomock.Any()).AnyTimes()
+	s.applier.EXPECT().Delete(gomock.Any()).AnyTimes()
+	s.applier.EXPECT().Run(gomock.Any(), s.client, false).DoAndReturn(
+		func(ctx context.Context, _ kubernetes.Interface, _ bool) error {
+			c.Check(resources.IsDryRun(ctx), jc.IsTrue)
+			return nil
+		},
+	)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{DryRun: true}), jc.ErrorIsNil)
+	s.assertEventReasons(c)
+}
+
+func (s *applicationSuite) TestEnsureDryRunFailure(c *gc.C) {
+	app, ctrl := s.getApp(c, caas.DeploymentStateful, true)
+	defer ctrl.Finish()
+
+	s.applier.EXPECT().Apply(gomock.Any()).AnyTimes()
+	s.applier.EXPECT().Delete(gomock.Any()).AnyTimes()
+	s.applier.EXPECT().Run(gomock.Any(), s.client, false).Return(
+		k8serrors.NewForbidden(appsv1.Resource("statefulsets"), "gitlab", errors.New("denied by webhook")),
+	)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{DryRun: true})
+	c.Assert(errors.Cause(err), jc.Satisfies, k8serrors.IsForbidden)
+	s.assertEventReasons(c)
+}
+
+func (s *applicationSuite) TestEnsureUnsupportedArch(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	cpuArch := "i686"
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		Constraints: constraints.Value{Arch: &cpuArch},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `.*architecture "i686" not supported, expected one of amd64, arm64, ppc64le, s390x`)
+}
+
+func (s *applicationSuite) TestEnsureHeadlessServiceDefault(c *gc.C) {
+	s.ensureStatefulPodSpec(c, caas.ApplicationConfig{})
+
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab-endpoints", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.ClusterIP, gc.Equals, "None")
+	c.Assert(svc.Spec.PublishNotReadyAddresses, jc.IsTrue)
+	c.Assert(svc.Annotations["service.alpha.kubernetes.io/tolerate-unready-endpoints"], gc.Equals, "true")
+}
+
+func (s *applicationSuite) TestEnsureHeadlessServiceConfigured(c *gc.C) {
+	s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		HeadlessService: &caas.HeadlessServiceConfig{
+			AllocateClusterIP:  true,
+			ReadyAddressesOnly: true,
+		},
+	})
+
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab-endpoints", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.ClusterIP, gc.Equals, "")
+	c.Assert(svc.Spec.PublishNotReadyAddresses, jc.IsFalse)
+	c.Assert(svc.Annotations["service.alpha.kubernetes.io/tolerate-unready-endpoints"], gc.Equals, "false")
+}
+
+func (s *applicationSuite) TestEnsureHeadlessServiceReadyAddressesOnlyUpdated(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		HeadlessService: &caas.HeadlessServiceConfig{ReadyAddressesOnly: true},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab-endpoints", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.ClusterIP, gc.Equals, "None")
+	c.Assert(svc.Spec.PublishNotReadyAddresses, jc.IsFalse)
+	c.Assert(svc.Annotations["service.alpha.kubernetes.io/tolerate-unready-endpoints"], gc.Equals, "false")
+
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+	svc, err = s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab-endpoints", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.PublishNotReadyAddresses, jc.IsTrue)
+	c.Assert(svc.Annotations["service.alpha.kubernetes.io/tolerate-unready-endpoints"], gc.Equals, "true")
+}
+
+func (s *applicationSuite) TestEnsureHeadlessServiceClusterIPChanged(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		HeadlessService: &caas.HeadlessServiceConfig{AllocateClusterIP: true},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `creating or updating headless service for "stateful" "gitlab": `+
+		`cannot update immutable field\(s\) clusterIP of headless service "gitlab": .*`)
+
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab-endpoints", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Spec.ClusterIP, gc.Equals, "None")
+}
+
+func (s *applicationSuite) TestEnsureServiceLabelsAndAnnotations(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	config := caas.ApplicationConfig{
+		ServiceLabels: map[string]string{
+			"team":                   "gitlab",
+			"app.kubernetes.io/name": "not-gitlab",
+		},
+		ServiceAnnotations: map[string]string{
+			"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+			"juju.is/version": "9.9.9",
+		},
+	}
+	c.Assert(app.Ensure(context.Background(), config), jc.ErrorIsNil)
+
+	for _, name := range []string{"gitlab", "gitlab-endpoints"} {
+		svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), name, metav1.GetOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(svc.Labels["team"], gc.Equals, "gitlab")
+		c.Check(svc.Labels["app.kubernetes.io/name"], gc.Equals, "gitlab")
+		c.Check(svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"], gc.Equals, "nlb")
+		c.Check(svc.Annotations["juju.is/version"], gc.Equals, "0.0.0")
+	}
+
+	// Extra annotations are added to the existing default service.
+	config.ServiceAnnotations["networking.gke.io/load-balancer-type"] = "Internal"
+	c.Assert(app.Ensure(context.Background(), config), jc.ErrorIsNil)
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.Annotations["networking.gke.io/load-balancer-type"], gc.Equals, "Internal")
+	c.Assert(svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"], gc.Equals, "nlb")
+}
+
+func (s *applicationSuite) TestHeadlessServiceName(c *gc.C) {
+	c.Assert(application.HeadlessServiceName("gitlab"), gc.Equals, "gitlab-endpoints")
+
+	// Names up to the limit are unchanged.
+	name := strings.Repeat("a", 53)
+	c.Assert(application.HeadlessServiceName(name), gc.Equals, name+"-endpoints")
+
+	long1 := strings.Repeat("a", 60) + "-one"
+	long2 := strings.Repeat("a", 60) + "-two"
+	name1 := application.HeadlessServiceName(long1)
+	name2 := application.HeadlessServiceName(long2)
+	c.Assert(len(name1), jc.LessThan, 64)
+	c.Assert(len(name2), jc.LessThan, 64)
+	c.Assert(name1, gc.Matches, `a+-[0-9a-f]{8}-endpoints`)
+	c.Assert(name1, gc.Not(gc.Equals), name2)
+	c.Assert(application.HeadlessServiceName(long1), gc.Equals, name1)
+}
+
+func (s *applicationSuite) TestEnsureHeadlessServiceLongName(c *gc.C) {
+	s.appName = strings.Repeat("gitlab", 10)
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), application.HeadlessServiceName(s.appName), metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(len(svc.Name), jc.LessThan, 64)
+}
+
+func (s *applicationSuite) TestEnsureOwnerReferences(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	// The fake clientset doesn't assign UIDs.
+	ss, err := s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	ss.UID = "gitlab-uid"
+	_, err = s.client.AppsV1().StatefulSets("test").Update(context.TODO(), ss, metav1.UpdateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	expected := []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+		Name:       "gitlab",
+		UID:        "gitlab-uid",
+	}}
+	secret, err := s.client.CoreV1().Secrets("test").Get(context.TODO(), "gitlab-application-config", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(secret.OwnerReferences, jc.DeepEquals, expected)
+	for _, name := range []string{"gitlab", "gitlab-endpoints"} {
+		svc, err := s.client.CoreV1().Services("test").Get(context.TODO(), name, metav1.GetOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(svc.OwnerReferences, jc.DeepEquals, expected)
+	}
+
+	// Ensuring again doesn't duplicate the references.
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+	secret, err = s.client.CoreV1().Secrets("test").Get(context.TODO(), "gitlab-application-config", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(secret.OwnerReferences, jc.DeepEquals, expected)
+}
+
+func (s *applicationSuite) TestEnsureStorageProvisionerChanged(c *gc.C) {
+	for _, sc := range []storagev1.StorageClass{{
+		ObjectMeta:  metav1.ObjectMeta{Name: "old-storage"},
+		Provisioner: "kubernetes.io/old",
+	}, {
+		ObjectMeta:  metav1.ObjectMeta{Name: "workload-storage"},
+		Provisioner: "kubernetes.io/new",
+	}} {
+		_, err := s.client.StorageV1().StorageClasses().Create(context.TODO(), &sc, metav1.CreateOptions{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	_, err := s.client.CoreV1().PersistentVolumeClaims("test").Create(context.TODO(), &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "gitlab-database-appuuid-gitlab-0",
+			Labels: map[string]string{"storage.juju.is/name": "database"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: application.StrPtr("old-storage"),
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase: corev1.ClaimBound,
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err = app.Ensure(context.Background(), caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes:  map[string]interface{}{"storage-class": "workload-storage"},
+		}},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `changing the provisioner of filesystem "database" from "kubernetes.io/old" `+
+		`\(storage class "old-storage" bound to persistent volume claim "gitlab-database-appuuid-gitlab-0"\) `+
+		`to "kubernetes.io/new" not supported`)
+}
+
+func (s *applicationSuite) TestEnsureStorageModeReadWriteMany(c *gc.C) {
+	_, err := s.client.StorageV1().StorageClasses().Create(context.TODO(), &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "shared-storage"},
+		Provisioner: "example.com/nfs",
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err = app.Ensure(context.Background(), caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes: map[string]interface{}{
+				"storage-class": "shared-storage",
+				"storage-mode":  "RWX",
+			},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	ss, err := s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ss.Spec.VolumeClaimTemplates, gc.HasLen, 1)
+	c.Assert(ss.Spec.VolumeClaimTemplates[0].Spec.AccessModes, jc.DeepEquals, []corev1.PersistentVolumeAccessMode{
+		corev1.ReadWriteMany,
+	})
+}
+
+func (s *applicationSuite) TestEnsureStorageModeNotSupportedByProvisioner(c *gc.C) {
+	_, err := s.client.StorageV1().StorageClasses().Create(context.TODO(), &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "block-storage"},
+		Provisioner: "kubernetes.io/aws-ebs",
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err = app.Ensure(context.Background(), caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes: map[string]interface{}{
+				"storage-class": "block-storage",
+				"storage-mode":  "ReadWriteMany",
+			},
+		}},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `.*filesystem "database": storage mode "ReadWriteMany" with provisioner "kubernetes.io/aws-ebs" not supported`)
+}
+
 type fakeCharm struct {
 	// TODO: remove this once `api/common/charms.CharmInfo` has upgraded to use the new charm.Charm.
 	Name       string
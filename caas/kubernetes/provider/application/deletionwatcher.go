@@ -0,0 +1,65 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// deletionInformer wraps an informer so that its event handlers are only
+// told about the deletion of resources.
+type deletionInformer struct {
+	cache.SharedIndexInformer
+}
+
+// AddEventHandler is part of cache.SharedInformer.
+func (i *deletionInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	i.SharedIndexInformer.AddEventHandler(newDeletionEventHandler(handler))
+}
+
+// AddEventHandlerWithResyncPeriod is part of cache.SharedInformer.
+func (i *deletionInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	i.SharedIndexInformer.AddEventHandlerWithResyncPeriod(newDeletionEventHandler(handler), resyncPeriod)
+}
+
+// deletionEventHandler passes on events for resources which start
+// terminating (their deletion timestamp is set) or which are removed.
+type deletionEventHandler struct {
+	handler cache.ResourceEventHandler
+}
+
+func newDeletionEventHandler(handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	return &deletionEventHandler{handler: handler}
+}
+
+// OnAdd is part of cache.ResourceEventHandler.
+func (h *deletionEventHandler) OnAdd(obj interface{}) {
+	if isTerminating(obj) {
+		h.handler.OnAdd(obj)
+	}
+}
+
+// OnUpdate is part of cache.ResourceEventHandler.
+func (h *deletionEventHandler) OnUpdate(oldObj, newObj interface{}) {
+	if !isTerminating(oldObj) && isTerminating(newObj) {
+		h.handler.OnUpdate(oldObj, newObj)
+	}
+}
+
+// OnDelete is part of cache.ResourceEventHandler.
+func (h *deletionEventHandler) OnDelete(obj interface{}) {
+	h.handler.OnDelete(obj)
+}
+
+func isTerminating(obj interface{}) bool {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		logger.Errorf("getting kubernetes watcher event meta: %v", err)
+		return false
+	}
+	return m.GetDeletionTimestamp() != nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/juju/juju/caas"
+)
+
+const (
+	eventReasonServiceConfigured = "ServiceConfigured"
+	eventReasonSecretApplied     = "SecretApplied"
+	eventReasonWorkloadApplied   = "WorkloadApplied"
+	eventReasonEnsureFailed      = "EnsureFailed"
+
+	eventReasonPriorityClassNotFound = "PriorityClassNotFound"
+
+	// eventSourceComponent identifies juju as the source of the events.
+	eventSourceComponent = "juju"
+)
+
+// workloadKind returns the kind of the k8s resource running the
+// application's units.
+func (a *app) workloadKind() string {
+	switch a.deploymentType {
+	case caas.DeploymentStateful:
+		return "StatefulSet"
+	case caas.DeploymentStateless:
+		return "Deployment"
+	case caas.DeploymentDaemon:
+		return "DaemonSet"
+	}
+	return ""
+}
+
+// recordEvent records a k8s event against the application's workload so
+// that it shows up when describing the workload. Recording is best effort:
+// failures are logged and otherwise ignored.
+func (a *app) recordEvent(ctx context.Context, eventType, reason, messageFormat string, args ...interface{}) {
+	kind := a.workloadKind()
+	if kind == "" {
+		return
+	}
+	now := metav1.NewTime(a.clock.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// Name events like the k8s event recorder does, with a random
+			// suffix so events recorded at the same time don't clash.
+			Name:      fmt.Sprintf("%s.%x.%s", a.name, now.UnixNano(), utilrand.String(5)),
+			Namespace: a.namespace,
+			Labels:    a.labels(),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       kind,
+			Name:       a.name,
+			Namespace:  a.namespace,
+		},
+		Reason:         reason,
+		Message:        fmt.Sprintf(messageFormat, args...),
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: eventSourceComponent},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := a.client.CoreV1().Events(a.namespace).Create(ctx, event, metav1.CreateOptions{})
+	if err != nil {
+		logger.Warningf("recording %q event for %q: %v", reason, a.name, err)
+	}
+}
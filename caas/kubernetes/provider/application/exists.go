@@ -0,0 +1,162 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"context"
+	"sort"
+
+	"github.com/juju/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/constants"
+)
+
+// ApplicationsExist reports the deployment state of each of the given
+// applications, keyed by name, in the same way as Application.Exists.
+// Rather than getting each application's resources in turn, the resources
+// of each kind are listed once for all of the applications.
+func ApplicationsExist(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespace string,
+	deploymentTypes map[string]caas.DeploymentType,
+	legacyLabels bool,
+) (map[string]caas.DeploymentState, error) {
+	states := make(map[string]caas.DeploymentState, len(deploymentTypes))
+	if len(deploymentTypes) == 0 {
+		return states, nil
+	}
+
+	apps := make(map[string]*app, len(deploymentTypes))
+	names := make([]string, 0, len(deploymentTypes))
+	handlers := make(map[string]DeploymentHandler)
+	for name, deploymentType := range deploymentTypes {
+		switch deploymentType {
+		case caas.DeploymentStateful, caas.DeploymentStateless, caas.DeploymentDaemon:
+		default:
+			handler, err := deploymentHandler(deploymentType)
+			if err != nil {
+				return nil, errors.Annotatef(err, "application %q", name)
+			}
+			handlers[name] = handler
+		}
+		apps[name] = &app{
+			name:           name,
+			namespace:      namespace,
+			client:         client,
+			deploymentType: deploymentType,
+			legacyLabels:   legacyLabels,
+		}
+		names = append(names, name)
+		states[name] = caas.DeploymentState{}
+	}
+	sort.Strings(names)
+
+	nameLabel := constants.LabelKubernetesAppName
+	if legacyLabels {
+		nameLabel = constants.LegacyLabelKubernetesAppName
+	}
+	requirement, err := labels.NewRequirement(nameLabel, selection.In, names)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	listOptions := metav1.ListOptions{
+		LabelSelector: labels.NewSelector().Add(*requirement).String(),
+	}
+
+	// found records that the named resource exists for the application it's
+	// labelled with, if it's one of the resources checked for that
+	// application.
+	found := func(meta metav1.ObjectMeta, resourceName func(*app) string) {
+		a, ok := apps[meta.Labels[nameLabel]]
+		if !ok || meta.Name != resourceName(a) {
+			return
+		}
+		state := states[a.name]
+		state.Exists = true
+		if meta.DeletionTimestamp != nil {
+			state.Terminating = true
+		}
+		states[a.name] = state
+	}
+	// workloadNameFor only matches workloads of the given type, as an
+	// application's workload is only checked for its deployment type.
+	workloadNameFor := func(deploymentType caas.DeploymentType) func(*app) string {
+		return func(a *app) string {
+			if a.deploymentType != deploymentType {
+				return ""
+			}
+			return a.name
+		}
+	}
+	hasType := func(deploymentType caas.DeploymentType) bool {
+		for _, t := range deploymentTypes {
+			if t == deploymentType {
+				return true
+			}
+		}
+		return false
+	}
+
+	if hasType(caas.DeploymentStateful) {
+		list, err := client.AppsV1().StatefulSets(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, errors.Annotate(err, "statefulset resource check")
+		}
+		for _, item := range list.Items {
+			found(item.ObjectMeta, workloadNameFor(caas.DeploymentStateful))
+		}
+	}
+	if hasType(caas.DeploymentStateless) {
+		list, err := client.AppsV1().Deployments(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, errors.Annotate(err, "deployment resource check")
+		}
+		for _, item := range list.Items {
+			found(item.ObjectMeta, workloadNameFor(caas.DeploymentStateless))
+		}
+	}
+	if hasType(caas.DeploymentDaemon) {
+		list, err := client.AppsV1().DaemonSets(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, errors.Annotate(err, "daemonset resource check")
+		}
+		for _, item := range list.Items {
+			found(item.ObjectMeta, workloadNameFor(caas.DeploymentDaemon))
+		}
+	}
+	for _, name := range names {
+		handler, ok := handlers[name]
+		if !ok {
+			continue
+		}
+		a := apps[name]
+		exists, terminating, err := handler.Exists(ctx, a.workload())
+		if err != nil {
+			return nil, errors.Annotatef(err, "%s resource check", a.deploymentType)
+		}
+		states[name] = caas.DeploymentState{Exists: exists, Terminating: exists && terminating}
+	}
+
+	secrets, err := client.CoreV1().Secrets(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Annotate(err, "secret resource check")
+	}
+	for _, item := range secrets.Items {
+		found(item.ObjectMeta, (*app).secretName)
+	}
+	services, err := client.CoreV1().Services(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Annotate(err, "service resource check")
+	}
+	for _, item := range services.Items {
+		found(item.ObjectMeta, func(a *app) string { return a.name })
+	}
+	return states, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application_test
+
+import (
+	"context"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/application"
+)
+
+func appObjectMeta(name, appName string, terminating bool) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{"app.kubernetes.io/name": appName},
+	}
+	if terminating {
+		now := metav1.Now()
+		meta.DeletionTimestamp = &now
+	}
+	return meta
+}
+
+func (s *applicationSuite) TestApplicationsExist(c *gc.C) {
+	ctx := context.Background()
+	_, err := s.client.AppsV1().StatefulSets("test").Create(ctx, &appsv1.StatefulSet{
+		ObjectMeta: appObjectMeta("gitlab", "gitlab", false),
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.client.CoreV1().Services("test").Create(ctx, &corev1.Service{
+		ObjectMeta: appObjectMeta("gitlab", "gitlab", false),
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	// Only the dangling secret of mariadb remains.
+	_, err = s.client.CoreV1().Secrets("test").Create(ctx, &corev1.Secret{
+		ObjectMeta: appObjectMeta("mariadb-application-config", "mariadb", true),
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	// A deployment isn't the workload of a stateful application.
+	_, err = s.client.AppsV1().Deployments("test").Create(ctx, &appsv1.Deployment{
+		ObjectMeta: appObjectMeta("redis", "redis", false),
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	// Resources of other applications are ignored.
+	_, err = s.client.AppsV1().StatefulSets("test").Create(ctx, &appsv1.StatefulSet{
+		ObjectMeta: appObjectMeta("postgresql", "postgresql", false),
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	lists := make(map[string]int)
+	s.client.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists[action.GetResource().Resource]++
+		return false, nil, nil
+	})
+	s.client.PrependReactor("get", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		c.Errorf("unexpected get of %s", action.GetResource().Resource)
+		return false, nil, nil
+	})
+
+	states, err := application.ApplicationsExist(ctx, s.client, "test", map[string]caas.DeploymentType{
+		"gitlab":  caas.DeploymentStateful,
+		"mariadb": caas.DeploymentStateful,
+		"redis":   caas.DeploymentStateful,
+		"nginx":   caas.DeploymentDaemon,
+	}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, jc.DeepEquals, map[string]caas.DeploymentState{
+		"gitlab":  {Exists: true},
+		"mariadb": {Exists: true, Terminating: true},
+		"redis":   {},
+		"nginx":   {},
+	})
+	c.Assert(lists, jc.DeepEquals, map[string]int{
+		"statefulsets": 1,
+		"daemonsets":   1,
+		"secrets":      1,
+		"services":     1,
+	})
+}
+
+func (s *applicationSuite) TestApplicationsExistMatchesExists(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+	state, err := app.Exists(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+
+	states, err := application.ApplicationsExist(context.Background(), s.client, "test", map[string]caas.DeploymentType{
+		"gitlab": caas.DeploymentStateful,
+	}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, jc.DeepEquals, map[string]caas.DeploymentState{"gitlab": state})
+	c.Assert(state.Exists, jc.IsTrue)
+}
+
+func (s *applicationSuite) TestApplicationsExistCustomDeploymentType(c *gc.C) {
+	_, err := application.ApplicationsExist(context.Background(), s.client, "test", map[string]caas.DeploymentType{
+		"gitlab": customDeployment,
+	}, false)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+
+	handler := &fakeDeploymentHandler{
+		workloads: map[string]application.Workload{"gitlab": {}},
+	}
+	unregister := application.RegisterDeploymentHandler(customDeployment, handler)
+	defer unregister()
+	states, err := application.ApplicationsExist(context.Background(), s.client, "test", map[string]caas.DeploymentType{
+		"gitlab":  customDeployment,
+		"mariadb": customDeployment,
+	}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, jc.DeepEquals, map[string]caas.DeploymentState{
+		"gitlab":  {Exists: true},
+		"mariadb": {},
+	})
+}
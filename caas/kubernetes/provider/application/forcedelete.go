@@ -0,0 +1,118 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/retry"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/resources"
+)
+
+const (
+	// forceDeleteTimeout is how long ForceDelete waits for the
+	// application's workload and pods to be removed.
+	forceDeleteTimeout = 2 * time.Minute
+	// forceDeletePollInterval is how often ForceDelete checks whether the
+	// application has been removed.
+	forceDeletePollInterval = 2 * time.Second
+)
+
+var errStillTerminating = errors.New("still terminating")
+
+// ForceDelete deletes the application like Delete, then deletes any of its
+// pods which remain without waiting for them to terminate gracefully. This
+// frees pods stuck terminating on an unresponsive kubelet; pods held by
+// finalizers are not removed until their finalizers are. It waits for the
+// application's workload and pods to be removed, returning an error if they
+// remain after a timeout.
+func (a *app) ForceDelete(ctx context.Context) error {
+	if err := a.Delete(ctx, caas.DeleteOptions{}); err != nil {
+		return errors.Trace(err)
+	}
+
+	pods, err := resources.ListPods(ctx, a.client, a.namespace, metav1.ListOptions{
+		LabelSelector: a.labelSelector(),
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	propagation := metav1.DeletePropagationBackground
+	api := a.client.CoreV1().Pods(a.namespace)
+	for _, pod := range pods {
+		logger.Debugf("force deleting pod %q", pod.Name)
+		err := api.Delete(ctx, pod.Name, metav1.DeleteOptions{
+			GracePeriodSeconds: int64Ptr(0),
+			PropagationPolicy:  &propagation,
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Annotatef(err, "force deleting pod %q", pod.Name)
+		}
+	}
+
+	err = retry.Call(retry.CallArgs{
+		Func: func() error {
+			return a.checkRemoved(ctx)
+		},
+		IsFatalError: func(err error) bool {
+			return errors.Cause(err) != errStillTerminating
+		},
+		Delay:       forceDeletePollInterval,
+		MaxDuration: forceDeleteTimeout,
+		Clock:       a.clock,
+		Stop:        ctx.Done(),
+		NotifyFunc: func(err error, attempt int) {
+			logger.Debugf("waiting for application %q to be removed: %v", a.name, err)
+		},
+	})
+	if err != nil {
+		return errors.Annotatef(retry.LastError(err), "force deleting application %q", a.name)
+	}
+	return nil
+}
+
+// checkRemoved returns an error satisfying errStillTerminating if the
+// application's workload or any of its pods still exist.
+func (a *app) checkRemoved(ctx context.Context) error {
+	var workloadExists func(context.Context) (bool, bool, error)
+	switch a.deploymentType {
+	case caas.DeploymentStateful:
+		workloadExists = a.statefulSetExists
+	case caas.DeploymentStateless:
+		workloadExists = a.deploymentExists
+	case caas.DeploymentDaemon:
+		workloadExists = a.daemonSetExists
+	default:
+		return errors.NotSupportedf("unknown deployment type")
+	}
+	exists, _, err := workloadExists(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if exists {
+		return errors.Annotatef(errStillTerminating, "%s %q", strings.ToLower(a.workloadKind()), a.name)
+	}
+
+	pods, err := resources.ListPods(ctx, a.client, a.namespace, metav1.ListOptions{
+		LabelSelector: a.labelSelector(),
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(pods) > 0 {
+		var names []string
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		return errors.Annotatef(errStillTerminating, "pods %s", strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/application"
+	"github.com/juju/juju/caas/kubernetes/provider/resources"
+	"github.com/juju/juju/testing"
+)
+
+func (s *applicationSuite) expectDeleteStateful() {
+	gomock.InOrder(
+		s.applier.EXPECT().Delete(resources.NewStatefulSet("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewPodDisruptionBudget("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewService("gitlab-endpoints", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewService("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewSecret("gitlab-application-config", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRoleBinding("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewRole("gitlab", "test", nil)),
+		s.applier.EXPECT().Delete(resources.NewServiceAccount("gitlab", "test", nil)),
+		s.applier.EXPECT().Run(context.Background(), s.client, false).Return(nil),
+	)
+}
+
+func (s *applicationSuite) createPod(c *gc.C, name string) {
+	_, err := s.client.CoreV1().Pods("test").Create(context.TODO(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"app.kubernetes.io/name": "gitlab"},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *applicationSuite) TestForceDelete(c *gc.C) {
+	app, ctrl := s.getApp(c, caas.DeploymentStateful, true)
+	defer ctrl.Finish()
+	s.expectDeleteStateful()
+	s.createPod(c, "gitlab-0")
+	s.createPod(c, "gitlab-1")
+
+	var deleteOptions []metav1.DeleteOptions
+	s.client.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteOptions = append(deleteOptions, action.(k8stesting.DeleteActionImpl).DeleteOptions)
+		return false, nil, nil
+	})
+
+	c.Assert(app.ForceDelete(context.Background()), jc.ErrorIsNil)
+
+	pods, err := s.client.CoreV1().Pods("test").List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pods.Items, gc.HasLen, 0)
+
+	background := metav1.DeletePropagationBackground
+	c.Assert(deleteOptions, jc.DeepEquals, []metav1.DeleteOptions{{
+		GracePeriodSeconds: application.Int64Ptr(0),
+		PropagationPolicy:  &background,
+	}, {
+		GracePeriodSeconds: application.Int64Ptr(0),
+		PropagationPolicy:  &background,
+	}})
+}
+
+func (s *applicationSuite) TestForceDeletePodsRemain(c *gc.C) {
+	app, ctrl := s.getApp(c, caas.DeploymentStateful, true)
+	defer ctrl.Finish()
+	s.expectDeleteStateful()
+	s.createPod(c, "gitlab-0")
+
+	// The pod is stuck and never goes away.
+	s.client.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.ForceDelete(context.Background())
+	}()
+	for i := 0; ; i++ {
+		select {
+		case err := <-errCh:
+			c.Assert(err, gc.ErrorMatches, `force deleting application "gitlab": pods gitlab-0: still terminating`)
+			return
+		case <-time.After(testing.ShortWait):
+			if i > 100 {
+				c.Fatalf("timed out waiting for force delete")
+			}
+			s.clock.Advance(time.Minute)
+		}
+	}
+}
+
+func (s *applicationSuite) TestForceDeleteContextCancelled(c *gc.C) {
+	app, ctrl := s.getApp(c, caas.DeploymentStateful, true)
+	defer ctrl.Finish()
+	s.expectDeleteStateful()
+	s.createPod(c, "gitlab-0")
+
+	s.client.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+
+	// Cancelling the context stops waiting without advancing the clock.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := app.ForceDelete(ctx)
+	c.Assert(err, gc.ErrorMatches, `force deleting application "gitlab": pods gitlab-0: still terminating`)
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/juju/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/core/annotations"
+)
+
+// DeploymentHandler manages the workload of an application with a custom
+// deployment type, such as one run by a controller watching a custom
+// resource, in place of a statefulset, deployment or daemonset.
+type DeploymentHandler interface {
+	// Ensure creates or updates the workload so that it runs pods
+	// with the workload's pod spec.
+	Ensure(ctx context.Context, workload Workload, config caas.ApplicationConfig) error
+
+	// Exists reports whether the workload exists, and whether it's
+	// terminating.
+	Exists(ctx context.Context, workload Workload) (exists bool, terminating bool, err error)
+
+	// DesiredReplicas returns the number of pods the workload should run.
+	DesiredReplicas(ctx context.Context, workload Workload) (int, error)
+
+	// Delete removes the workload.
+	Delete(ctx context.Context, workload Workload) error
+}
+
+// Workload describes the application workload managed by a
+// DeploymentHandler.
+type Workload struct {
+	Client    kubernetes.Interface
+	Name      string
+	Namespace string
+
+	// Labels are set on the workload, and SelectorLabels on its pods.
+	// Units are found by matching pods with the selector labels.
+	Labels         labels.Set
+	SelectorLabels labels.Set
+
+	// Annotations to set on the workload, and PodSpec, the spec of the
+	// pods the workload runs, are only set when ensuring the workload.
+	Annotations annotations.Annotation
+	PodSpec     *corev1.PodSpec
+}
+
+var (
+	deploymentHandlersMu sync.Mutex
+	deploymentHandlers   = make(map[caas.DeploymentType]DeploymentHandler)
+)
+
+// RegisterDeploymentHandler registers the handler for applications with
+// the given deployment type. It panics if the type is built in or already
+// registered.
+func RegisterDeploymentHandler(deploymentType caas.DeploymentType, handler DeploymentHandler) (unregister func()) {
+	deploymentHandlersMu.Lock()
+	defer deploymentHandlersMu.Unlock()
+	switch deploymentType {
+	case caas.DeploymentStateful, caas.DeploymentStateless, caas.DeploymentDaemon:
+		panic(fmt.Errorf("juju: deployment type %q is built in", deploymentType))
+	}
+	if _, ok := deploymentHandlers[deploymentType]; ok {
+		panic(fmt.Errorf("juju: duplicate deployment handler for %q", deploymentType))
+	}
+	deploymentHandlers[deploymentType] = handler
+	return func() {
+		deploymentHandlersMu.Lock()
+		defer deploymentHandlersMu.Unlock()
+		delete(deploymentHandlers, deploymentType)
+	}
+}
+
+func deploymentHandler(deploymentType caas.DeploymentType) (DeploymentHandler, error) {
+	deploymentHandlersMu.Lock()
+	defer deploymentHandlersMu.Unlock()
+	handler, ok := deploymentHandlers[deploymentType]
+	if !ok {
+		return nil, errors.NotSupportedf("unknown deployment type")
+	}
+	return handler, nil
+}
+
+// workload returns the description of the application's workload passed
+// to a DeploymentHandler.
+func (a *app) workload() Workload {
+	return Workload{
+		Client:         a.client,
+		Name:           a.name,
+		Namespace:      a.namespace,
+		Labels:         a.labels(),
+		SelectorLabels: a.selectorLabels(),
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application_test
+
+import (
+	"context"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/application"
+)
+
+const customDeployment caas.DeploymentType = "custom"
+
+// fakeDeploymentHandler records the workloads it manages in memory.
+type fakeDeploymentHandler struct {
+	workloads map[string]application.Workload
+	replicas  int
+}
+
+func (h *fakeDeploymentHandler) Ensure(ctx context.Context, workload application.Workload, config caas.ApplicationConfig) error {
+	h.workloads[workload.Name] = workload
+	return nil
+}
+
+func (h *fakeDeploymentHandler) Exists(ctx context.Context, workload application.Workload) (bool, bool, error) {
+	_, ok := h.workloads[workload.Name]
+	return ok, false, nil
+}
+
+func (h *fakeDeploymentHandler) DesiredReplicas(ctx context.Context, workload application.Workload) (int, error) {
+	if _, ok := h.workloads[workload.Name]; !ok {
+		return 0, errors.NotFoundf("workload %q", workload.Name)
+	}
+	return h.replicas, nil
+}
+
+func (h *fakeDeploymentHandler) Delete(ctx context.Context, workload application.Workload) error {
+	delete(h.workloads, workload.Name)
+	return nil
+}
+
+func (s *applicationSuite) TestCustomDeploymentHandler(c *gc.C) {
+	handler := &fakeDeploymentHandler{
+		workloads: make(map[string]application.Workload),
+		replicas:  3,
+	}
+	unregister := application.RegisterDeploymentHandler(customDeployment, handler)
+	defer unregister()
+
+	app, _ := s.getApp(c, customDeployment, false)
+	state, err := app.Exists(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(state, jc.DeepEquals, caas.DeploymentState{})
+
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{
+		Containers: map[string]caas.ContainerConfig{
+			"gitlab": {Name: "gitlab"},
+		},
+	}), jc.ErrorIsNil)
+	workload, ok := handler.workloads["gitlab"]
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(workload.Namespace, gc.Equals, "test")
+	c.Assert(map[string]string(workload.SelectorLabels), jc.DeepEquals, map[string]string{"app.kubernetes.io/name": "gitlab"})
+	c.Assert(workload.PodSpec, gc.NotNil)
+	var containers []string
+	for _, container := range workload.PodSpec.Containers {
+		containers = append(containers, container.Name)
+	}
+	c.Assert(containers, jc.DeepEquals, []string{"charm", "gitlab"})
+
+	// Juju's own resources are still applied for the workload.
+	_, err = s.client.CoreV1().Secrets("test").Get(context.TODO(), "gitlab-application-config", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	state, err = app.Exists(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(state, jc.DeepEquals, caas.DeploymentState{Exists: true})
+
+	_, err = s.client.CoreV1().Pods("test").Create(context.TODO(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "gitlab-0",
+			Labels: map[string]string{"app.kubernetes.io/name": "gitlab"},
+		},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	appState, err := app.State(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(appState.DesiredReplicas, gc.Equals, 3)
+	c.Assert(appState.Replicas, jc.DeepEquals, []string{"gitlab-0"})
+
+	c.Assert(app.Delete(context.Background(), caas.DeleteOptions{}), jc.ErrorIsNil)
+	c.Assert(handler.workloads, gc.HasLen, 0)
+	state, err = app.Exists(context.Background())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(state, jc.DeepEquals, caas.DeploymentState{})
+}
+
+func (s *applicationSuite) TestCustomDeploymentHandlerUnregistered(c *gc.C) {
+	app, _ := s.getApp(c, customDeployment, false)
+	_, err := app.Exists(context.Background())
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	err = app.Ensure(context.Background(), caas.ApplicationConfig{})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *applicationSuite) TestRegisterDeploymentHandlerBuiltIn(c *gc.C) {
+	register := func() {
+		application.RegisterDeploymentHandler(caas.DeploymentStateful, &fakeDeploymentHandler{})
+	}
+	c.Assert(register, gc.PanicMatches, `juju: deployment type "stateful" is built in`)
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/docker/distribution/reference"
+	"github.com/juju/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/resources"
+	coreresources "github.com/juju/juju/core/resources"
+)
+
+// dockerConfigJSON represents the ~/.docker/config.json file stored in an
+// image pull secret.
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// dockerConfigEntry represents an auth entry in the docker config.
+type dockerConfigEntry struct {
+	Username string
+	Password string
+}
+
+func imagePullSecretName(appName, containerName string) string {
+	return appName + "-" + containerName + "-secret"
+}
+
+// privateImages returns the details of the images requiring credentials to
+// pull, keyed by the name of the image pull secret holding the credentials.
+func (a *app) privateImages(config caas.ApplicationConfig) map[string]coreresources.DockerImageDetails {
+	images := make(map[string]coreresources.DockerImageDetails)
+	if config.CharmBaseImage.Password != "" {
+		images[imagePullSecretName(a.name, unitContainerName)] = config.CharmBaseImage
+	}
+	for _, v := range config.Containers {
+		if v.Image.Password != "" {
+			images[imagePullSecretName(a.name, v.Name)] = v.Image
+		}
+	}
+	return images
+}
+
+// imagePullSecrets returns the references to the secrets the application
+// pod uses to pull its images.
+func (a *app) imagePullSecrets(config caas.ApplicationConfig) []corev1.LocalObjectReference {
+	var names []string
+	for name := range a.privateImages(config) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	names = append(names, config.ImagePullSecrets...)
+
+	var refs []corev1.LocalObjectReference
+	for _, name := range names {
+		refs = append(refs, corev1.LocalObjectReference{Name: name})
+	}
+	return refs
+}
+
+// imagePullSecretResources returns the secrets holding the credentials
+// for the application's private images.
+func (a *app) imagePullSecretResources(config caas.ApplicationConfig) ([]*resources.Secret, error) {
+	var secrets []*resources.Secret
+	for name, image := range a.privateImages(config) {
+		data, err := createDockerConfigJSON(image)
+		if err != nil {
+			return nil, errors.Annotatef(err, "creating image pull secret %q", name)
+		}
+		secrets = append(secrets, resources.NewSecret(name, a.namespace, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      a.labels(),
+				Annotations: a.annotations(config),
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: data,
+			},
+		}))
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+	return secrets, nil
+}
+
+// listImagePullSecrets returns the names of the image pull secrets
+// created for the application.
+func (a *app) listImagePullSecrets(ctx context.Context) ([]string, error) {
+	secrets, err := resources.ListSecrets(ctx, a.client, a.namespace, metav1.ListOptions{
+		LabelSelector: a.labelSelector(),
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var names []string
+	for _, s := range secrets {
+		if s.Type == corev1.SecretTypeDockerConfigJson {
+			names = append(names, s.Name)
+		}
+	}
+	return names, nil
+}
+
+func createDockerConfigJSON(image coreresources.DockerImageDetails) ([]byte, error) {
+	imageNamed, err := reference.ParseNormalizedNamed(image.RegistryPath)
+	if err != nil {
+		return nil, errors.Annotate(err, "extracting registry from path")
+	}
+	return json.Marshal(dockerConfigJSON{
+		Auths: map[string]dockerConfigEntry{
+			reference.Domain(imageNamed): {
+				Username: image.Username,
+				Password: image.Password,
+			},
+		},
+	})
+}
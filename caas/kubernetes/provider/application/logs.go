@@ -0,0 +1,63 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"context"
+	"io"
+
+	"github.com/juju/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/resources"
+)
+
+// UnitLogs streams the logs of a container of the unit with the given pod
+// name. The charm container's logs are streamed if no container is given.
+func (a *app) UnitLogs(ctx context.Context, unitID, containerName string, opts caas.LogOptions) (io.ReadCloser, error) {
+	if opts.TailLines < 0 {
+		return nil, errors.NotValidf("negative tail lines %d", opts.TailLines)
+	}
+	pod := resources.NewPod(unitID, a.namespace, nil)
+	if err := pod.Get(ctx, a.client); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Only stream the logs of the application's own units.
+	if !a.selectorLabels().AsSelector().Matches(labels.Set(pod.Labels)) {
+		return nil, errors.NotFoundf("unit %q of application %q", unitID, a.name)
+	}
+	if containerName == "" {
+		containerName = unitContainerName
+	}
+	found := false
+	for _, c := range pod.Spec.Containers {
+		if c.Name == containerName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.NotFoundf("container %q in unit %q", containerName, unitID)
+	}
+
+	logOpts := &corev1.PodLogOptions{
+		Container: containerName,
+		Previous:  opts.Previous,
+	}
+	if opts.TailLines > 0 {
+		logOpts.TailLines = &opts.TailLines
+	}
+	if !opts.Since.IsZero() {
+		since := metav1.NewTime(opts.Since)
+		logOpts.SinceTime = &since
+	}
+	stream, err := a.client.CoreV1().Pods(a.namespace).GetLogs(unitID, logOpts).Stream(ctx)
+	if err != nil {
+		return nil, errors.Annotatef(err, "streaming logs of container %q in unit %q", containerName, unitID)
+	}
+	return stream, nil
+}
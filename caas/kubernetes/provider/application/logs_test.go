@@ -0,0 +1,68 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application_test
+
+import (
+	"context"
+	"io/ioutil"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/juju/juju/caas"
+)
+
+func (s *applicationSuite) createUnitPod(c *gc.C, name string, podLabels map[string]string) {
+	_, err := s.client.CoreV1().Pods(s.namespace).Create(context.TODO(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.namespace,
+			Name:      name,
+			Labels:    podLabels,
+		},
+		Spec: getPodSpec(c),
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *applicationSuite) TestUnitLogs(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	s.createUnitPod(c, "gitlab-0", map[string]string{"app.kubernetes.io/name": "gitlab"})
+
+	stream, err := app.UnitLogs(context.Background(), "gitlab-0", "gitlab", caas.LogOptions{
+		TailLines: 10,
+		Previous:  true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer stream.Close()
+	logs, err := ioutil.ReadAll(stream)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(logs), gc.Equals, "fake logs")
+}
+
+func (s *applicationSuite) TestUnitLogsOtherApplication(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	s.createUnitPod(c, "mariadb-0", map[string]string{"app.kubernetes.io/name": "mariadb"})
+
+	_, err := app.UnitLogs(context.Background(), "mariadb-0", "", caas.LogOptions{})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `unit "mariadb-0" of application "gitlab" not found`)
+}
+
+func (s *applicationSuite) TestUnitLogsUnknownContainer(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	s.createUnitPod(c, "gitlab-0", map[string]string{"app.kubernetes.io/name": "gitlab"})
+
+	_, err := app.UnitLogs(context.Background(), "gitlab-0", "mariadb", caas.LogOptions{})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `container "mariadb" in unit "gitlab-0" not found`)
+}
+
+func (s *applicationSuite) TestUnitLogsNegativeTailLines(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	_, err := app.UnitLogs(context.Background(), "gitlab-0", "", caas.LogOptions{TailLines: -1})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
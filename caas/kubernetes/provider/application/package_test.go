@@ -14,9 +14,11 @@ func Test(t *testing.T) {
 }
 
 var (
-	Int32Ptr              = int32Ptr
-	Int64Ptr              = int64Ptr
-	BoolPtr               = boolPtr
-	StrPtr                = strPtr
-	NewApplicationForTest = newApplication
+	Int32Ptr                = int32Ptr
+	Int64Ptr                = int64Ptr
+	BoolPtr                 = boolPtr
+	StrPtr                  = strPtr
+	NewApplicationForTest   = newApplication
+	NewDeletionEventHandler = newDeletionEventHandler
+	HeadlessServiceName     = headlessServiceName
 )
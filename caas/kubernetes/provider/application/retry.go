@@ -0,0 +1,59 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/retry"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/juju/juju/caas/kubernetes/provider/resources"
+)
+
+const (
+	// defaultApplyAttempts is how many times the application's resources
+	// are applied before giving up, unless configured otherwise.
+	defaultApplyAttempts = 5
+	// applyRetryDelay is the delay before the first retry, which doubles
+	// on each subsequent attempt up to applyRetryMaxDelay.
+	applyRetryDelay    = time.Second
+	applyRetryMaxDelay = 30 * time.Second
+)
+
+// runWithRetry runs the applier, retrying with exponential backoff if the
+// Kubernetes API reports a transient error. Any other error fails fast,
+// and cancelling the context stops any further retries.
+func (a *app) runWithRetry(ctx context.Context, applier resources.Applier, attempts int) error {
+	if attempts <= 0 {
+		attempts = defaultApplyAttempts
+	}
+	err := retry.Call(retry.CallArgs{
+		Func: func() error {
+			return applier.Run(ctx, a.client, false)
+		},
+		IsFatalError: func(err error) bool {
+			return !isTransientError(err)
+		},
+		Attempts:    attempts,
+		Delay:       applyRetryDelay,
+		MaxDelay:    applyRetryMaxDelay,
+		BackoffFunc: retry.DoubleDelay,
+		Clock:       a.clock,
+		Stop:        ctx.Done(),
+		NotifyFunc: func(err error, attempt int) {
+			logger.Debugf("applying application %q (attempt %d): %v", a.name, attempt, err)
+		},
+	})
+	return errors.Trace(retry.LastError(err))
+}
+
+// isTransientError reports whether the error is a conflict or timeout
+// which may succeed if retried.
+func isTransientError(err error) bool {
+	err = errors.Cause(err)
+	return k8serrors.IsConflict(err) || k8serrors.IsServerTimeout(err)
+}
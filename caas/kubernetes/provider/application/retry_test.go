@@ -0,0 +1,112 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/testing"
+)
+
+// failStatefulSetPatches makes the first n patches of the statefulset fail
+// with the error, and returns a pointer to the number of patches made.
+func (s *applicationSuite) failStatefulSetPatches(n int, err error) *int {
+	var patches int
+	s.client.PrependReactor("patch", "statefulsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patches++
+		if patches <= n {
+			return true, nil, err
+		}
+		return false, nil, nil
+	})
+	return &patches
+}
+
+// ensureAdvancingClock runs Ensure, advancing the clock until it completes.
+func (s *applicationSuite) ensureAdvancingClock(c *gc.C, app caas.Application, config caas.ApplicationConfig) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.Ensure(context.Background(), config)
+	}()
+	for i := 0; ; i++ {
+		select {
+		case err := <-errCh:
+			return err
+		case <-time.After(testing.ShortWait):
+			if i > 100 {
+				c.Fatalf("timed out waiting for ensure")
+			}
+			s.clock.Advance(time.Minute)
+		}
+	}
+}
+
+func (s *applicationSuite) TestEnsureRetriesConflict(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	conflict := k8serrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, "gitlab", errors.New("modified"))
+	patches := s.failStatefulSetPatches(2, conflict)
+
+	err := s.ensureAdvancingClock(c, app, caas.ApplicationConfig{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*patches, gc.Equals, 3)
+
+	_, err = s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *applicationSuite) TestEnsureRetriesServerTimeout(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	timeout := k8serrors.NewServerTimeout(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, "patch", 1)
+	patches := s.failStatefulSetPatches(1, timeout)
+
+	err := s.ensureAdvancingClock(c, app, caas.ApplicationConfig{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*patches, gc.Equals, 2)
+}
+
+func (s *applicationSuite) TestEnsureRetryAttemptsExceeded(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	conflict := k8serrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, "gitlab", errors.New("modified"))
+	patches := s.failStatefulSetPatches(10, conflict)
+
+	err := s.ensureAdvancingClock(c, app, caas.ApplicationConfig{ApplyAttempts: 2})
+	c.Assert(errors.Cause(err), jc.Satisfies, k8serrors.IsConflict)
+	c.Assert(*patches, gc.Equals, 2)
+}
+
+func (s *applicationSuite) TestEnsureForbiddenFailsFast(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, "gitlab", errors.New("denied"))
+	patches := s.failStatefulSetPatches(10, forbidden)
+
+	// The clock isn't advanced, so a retry would block.
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{})
+	c.Assert(errors.Cause(err), jc.Satisfies, k8serrors.IsForbidden)
+	c.Assert(*patches, gc.Equals, 1)
+}
+
+func (s *applicationSuite) TestEnsureRetryStoppedByContext(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	conflict := k8serrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, "gitlab", errors.New("modified"))
+	patches := s.failStatefulSetPatches(10, conflict)
+
+	// The clock isn't advanced, so only the cancelled context stops
+	// the retry from blocking.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := app.Ensure(ctx, caas.ApplicationConfig{})
+	c.Assert(errors.Cause(err), jc.Satisfies, k8serrors.IsConflict)
+	c.Assert(*patches, gc.Equals, 1)
+}
@@ -49,3 +49,13 @@ func (s *applicationSuite) TestApplicationScaleStatefulLessThanZero(c *gc.C) {
 
 	c.Assert(errors.IsNotValid(app.Scale(-1)), jc.IsTrue)
 }
+
+func (s *applicationSuite) TestApplicationScaleNotFound(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(errors.IsNotFound(app.Scale(3)), jc.IsTrue)
+}
+
+func (s *applicationSuite) TestApplicationScaleDaemonNotSupported(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentDaemon, false)
+	c.Assert(errors.IsNotSupported(app.Scale(3)), jc.IsTrue)
+}
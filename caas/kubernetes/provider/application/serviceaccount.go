@@ -0,0 +1,83 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"github.com/juju/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/resources"
+)
+
+// serviceAccountResources returns the service account, role and role
+// binding used by the application pod when a service account is configured.
+func (a *app) serviceAccountResources() (*resources.ServiceAccount, *resources.Role, *resources.RoleBinding) {
+	meta := metav1.ObjectMeta{
+		Labels: a.labels(),
+	}
+	sa := resources.NewServiceAccount(a.name, a.namespace, &corev1.ServiceAccount{
+		ObjectMeta:                   *meta.DeepCopy(),
+		AutomountServiceAccountToken: boolPtr(true),
+	})
+	role := resources.NewRole(a.name, a.namespace, &rbacv1.Role{
+		ObjectMeta: *meta.DeepCopy(),
+	})
+	binding := resources.NewRoleBinding(a.name, a.namespace, &rbacv1.RoleBinding{
+		ObjectMeta: *meta.DeepCopy(),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     a.name,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      a.name,
+			Namespace: a.namespace,
+		}},
+	})
+	return sa, role, binding
+}
+
+// ensureServiceAccount applies the service account for the application and,
+// if any rules are configured, its role and role binding. Any which are not
+// configured are removed.
+func (a *app) ensureServiceAccount(applier resources.Applier, config *caas.ServiceAccountConfig) error {
+	if config == nil {
+		a.deleteServiceAccount(applier)
+		return nil
+	}
+	sa, role, binding := a.serviceAccountResources()
+	applier.Apply(sa)
+	if len(config.Rules) == 0 {
+		applier.Delete(resources.NewRoleBinding(a.name, a.namespace, nil))
+		applier.Delete(resources.NewRole(a.name, a.namespace, nil))
+		return nil
+	}
+	for _, r := range config.Rules {
+		if len(r.Verbs) == 0 {
+			return errors.NotValidf("service account rule without verbs")
+		}
+		role.Rules = append(role.Rules, rbacv1.PolicyRule{
+			Verbs:           r.Verbs,
+			APIGroups:       r.APIGroups,
+			Resources:       r.Resources,
+			ResourceNames:   r.ResourceNames,
+			NonResourceURLs: r.NonResourceURLs,
+		})
+	}
+	applier.Apply(role)
+	applier.Apply(binding)
+	return nil
+}
+
+// deleteServiceAccount removes the application's service account, role and
+// role binding.
+func (a *app) deleteServiceAccount(applier resources.Applier) {
+	applier.Delete(resources.NewRoleBinding(a.name, a.namespace, nil))
+	applier.Delete(resources.NewRole(a.name, a.namespace, nil))
+	applier.Delete(resources.NewServiceAccount(a.name, a.namespace, nil))
+}
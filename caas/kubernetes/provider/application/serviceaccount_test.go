@@ -0,0 +1,106 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application_test
+
+import (
+	"context"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/specs"
+)
+
+func (s *applicationSuite) TestEnsureServiceAccount(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		ServiceAccount: &caas.ServiceAccountConfig{
+			Rules: []specs.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get", "list"},
+			}},
+		},
+	})
+	c.Assert(podSpec.ServiceAccountName, gc.Equals, "gitlab")
+	c.Assert(*podSpec.AutomountServiceAccountToken, jc.IsTrue)
+
+	sa, err := s.client.CoreV1().ServiceAccounts("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sa.Labels, jc.DeepEquals, map[string]string{
+		"app.kubernetes.io/name":       "gitlab",
+		"app.kubernetes.io/managed-by": "juju",
+	})
+
+	role, err := s.client.RbacV1().Roles("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(role.Rules, jc.DeepEquals, []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"pods"},
+		Verbs:     []string{"get", "list"},
+	}})
+
+	binding, err := s.client.RbacV1().RoleBindings("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(binding.RoleRef, jc.DeepEquals, rbacv1.RoleRef{
+		APIGroup: "rbac.authorization.k8s.io",
+		Kind:     "Role",
+		Name:     "gitlab",
+	})
+	c.Assert(binding.Subjects, jc.DeepEquals, []rbacv1.Subject{{
+		Kind:      "ServiceAccount",
+		Name:      "gitlab",
+		Namespace: "test",
+	}})
+}
+
+func (s *applicationSuite) TestEnsureServiceAccountWithoutRules(c *gc.C) {
+	podSpec := s.ensureStatefulPodSpec(c, caas.ApplicationConfig{
+		ServiceAccount: &caas.ServiceAccountConfig{},
+	})
+	c.Assert(podSpec.ServiceAccountName, gc.Equals, "gitlab")
+
+	_, err := s.client.CoreV1().ServiceAccounts("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.client.RbacV1().Roles("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+	_, err = s.client.RbacV1().RoleBindings("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+}
+
+func (s *applicationSuite) TestEnsureServiceAccountRemoved(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{
+		ServiceAccount: &caas.ServiceAccountConfig{
+			Rules: []specs.PolicyRule{{Resources: []string{"pods"}, Verbs: []string{"get"}}},
+		},
+	}), jc.ErrorIsNil)
+	c.Assert(app.Ensure(context.Background(), caas.ApplicationConfig{}), jc.ErrorIsNil)
+
+	ss, err := s.client.AppsV1().StatefulSets("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*ss.Spec.Template.Spec.AutomountServiceAccountToken, jc.IsFalse)
+
+	_, err = s.client.CoreV1().ServiceAccounts("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+	_, err = s.client.RbacV1().Roles("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+	_, err = s.client.RbacV1().RoleBindings("test").Get(context.TODO(), "gitlab", metav1.GetOptions{})
+	c.Assert(err, jc.Satisfies, k8serrors.IsNotFound)
+}
+
+func (s *applicationSuite) TestEnsureServiceAccountInvalidRule(c *gc.C) {
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	err := app.Ensure(context.Background(), caas.ApplicationConfig{
+		ServiceAccount: &caas.ServiceAccountConfig{
+			Rules: []specs.PolicyRule{{Resources: []string{"pods"}}},
+		},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `.*service account rule without verbs not valid`)
+}
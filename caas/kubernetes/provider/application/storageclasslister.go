@@ -0,0 +1,82 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/juju/clock"
+	"github.com/juju/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/juju/juju/caas/kubernetes/provider/resources"
+)
+
+// StorageClassLister lists the cluster's storage classes.
+type StorageClassLister interface {
+	// List returns the cluster's storage classes.
+	List(ctx context.Context, client kubernetes.Interface) ([]resources.StorageClass, error)
+
+	// Invalidate discards any cached storage classes, so that the next
+	// List fetches them from the cluster.
+	Invalidate()
+}
+
+// NewCachedStorageClassLister returns a StorageClassLister which caches
+// the storage classes for the given TTL, so that they can be shared by
+// the applications being ensured.
+func NewCachedStorageClassLister(clock clock.Clock, ttl time.Duration) StorageClassLister {
+	return &cachedStorageClassLister{
+		clock: clock,
+		ttl:   ttl,
+	}
+}
+
+type cachedStorageClassLister struct {
+	clock clock.Clock
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	items   []resources.StorageClass
+	expires time.Time
+}
+
+// List is part of the StorageClassLister interface.
+func (l *cachedStorageClassLister) List(ctx context.Context, client kubernetes.Interface) ([]resources.StorageClass, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.items != nil && l.clock.Now().Before(l.expires) {
+		return l.items, nil
+	}
+	items, err := resources.ListStorageClass(ctx, client, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if items == nil {
+		// Cache the absence of storage classes too.
+		items = []resources.StorageClass{}
+	}
+	l.items = items
+	l.expires = l.clock.Now().Add(l.ttl)
+	return items, nil
+}
+
+// Invalidate is part of the StorageClassLister interface.
+func (l *cachedStorageClassLister) Invalidate() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.items = nil
+}
+
+// listStorageClasses lists the cluster's storage classes, from the shared
+// cache if the application has one.
+func (a *app) listStorageClasses(ctx context.Context) ([]resources.StorageClass, error) {
+	if a.storageClasses == nil {
+		return resources.ListStorageClass(ctx, a.client, metav1.ListOptions{})
+	}
+	return a.storageClasses.List(ctx, a.client)
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package application_test
+
+import (
+	"context"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/application"
+	"github.com/juju/juju/storage"
+)
+
+// countStorageClassLists returns a pointer to the number of times the
+// storage classes are listed.
+func (s *applicationSuite) countStorageClassLists() *int {
+	var lists int
+	s.client.PrependReactor("list", "storageclasses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists++
+		return false, nil, nil
+	})
+	return &lists
+}
+
+func (s *applicationSuite) TestCachedStorageClassLister(c *gc.C) {
+	_, err := s.client.StorageV1().StorageClasses().Create(context.TODO(), &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "fast"},
+	}, metav1.CreateOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	lists := s.countStorageClassLists()
+
+	lister := application.NewCachedStorageClassLister(s.clock, time.Minute)
+	for i := 0; i < 2; i++ {
+		items, err := lister.List(context.Background(), s.client)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(items, gc.HasLen, 1)
+		c.Assert(items[0].Name, gc.Equals, "fast")
+	}
+	c.Assert(*lists, gc.Equals, 1)
+
+	s.clock.Advance(time.Minute)
+	_, err = lister.List(context.Background(), s.client)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*lists, gc.Equals, 2)
+
+	lister.Invalidate()
+	_, err = lister.List(context.Background(), s.client)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*lists, gc.Equals, 3)
+}
+
+func (s *applicationSuite) TestEnsureSharesStorageClassCache(c *gc.C) {
+	s.storageClasses = application.NewCachedStorageClassLister(s.clock, time.Minute)
+	lists := s.countStorageClassLists()
+
+	config := caas.ApplicationConfig{
+		Filesystems: []storage.KubernetesFilesystemParams{{
+			StorageName: "database",
+			Size:        100,
+			Provider:    "kubernetes",
+			Attributes: map[string]interface{}{
+				"storage-class":       "fast",
+				"storage-provisioner": "ebs.csi.aws.com",
+			},
+			Attachment: &storage.KubernetesFilesystemAttachmentParams{
+				Path: "path/to/here",
+			},
+		}},
+	}
+	app, _ := s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), config), jc.ErrorIsNil)
+	c.Assert(*lists, gc.Equals, 1)
+	_, err := s.client.StorageV1().StorageClasses().Get(context.TODO(), "test-fast", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Creating the storage class invalidated the cache, so it's listed
+	// again to find the new storage class.
+	app, _ = s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), config), jc.ErrorIsNil)
+	c.Assert(*lists, gc.Equals, 2)
+
+	// Nothing was created this time, so the cached storage classes are used.
+	app, _ = s.getApp(c, caas.DeploymentStateful, false)
+	c.Assert(app.Ensure(context.Background(), config), jc.ErrorIsNil)
+	c.Assert(*lists, gc.Equals, 2)
+}
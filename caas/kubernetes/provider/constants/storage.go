@@ -22,6 +22,9 @@ const (
 	StorageProvisioner = "storage-provisioner"
 	StorageMedium      = "storage-medium"
 	StorageMode        = "storage-mode"
+	// StorageReclaimPolicy is the reclaim policy of storage classes
+	// created by Juju, "Retain" (the default) or "Delete".
+	StorageReclaimPolicy = "storage-reclaim-policy"
 )
 
 const (
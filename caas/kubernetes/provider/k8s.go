@@ -40,6 +40,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/juju/juju/caas"
+	"github.com/juju/juju/caas/kubernetes/provider/application"
 	"github.com/juju/juju/caas/kubernetes/provider/constants"
 	k8sspecs "github.com/juju/juju/caas/kubernetes/provider/specs"
 	k8sstorage "github.com/juju/juju/caas/kubernetes/provider/storage"
@@ -79,6 +80,10 @@ const (
 	// InformerResyncPeriod is the default resync period set on IndexInformers
 	InformerResyncPeriod = time.Minute * 5
 
+	// storageClassCacheTTL is how long the storage classes listed when
+	// ensuring applications are reused for.
+	storageClassCacheTTL = 30 * time.Second
+
 	// A set of constants defining history limits for certain k8s deployment
 	// types.
 	// TODO We may want to make these configurable in the future.
@@ -129,6 +134,10 @@ type kubernetesClient struct {
 
 	// randomPrefix generates an annotation for stateful sets.
 	randomPrefix utils.RandomPrefixFunc
+
+	// storageClasses caches the storage classes listed when ensuring
+	// applications.
+	storageClasses application.StorageClassLister
 }
 
 // To regenerate the mocks for the kubernetes Client used by this broker,
@@ -209,6 +218,7 @@ func newK8sBroker(
 		newClient:         newClient,
 		newRestClient:     newRestClient,
 		randomPrefix:      randomPrefix,
+		storageClasses:    application.NewCachedStorageClassLister(clock, storageClassCacheTTL),
 		annotations: k8sannotations.New(nil).
 			Add(utils.AnnotationModelUUIDKey(isLegacy), modelUUID),
 		isLegacyLabels: isLegacy,
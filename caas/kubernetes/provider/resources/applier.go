@@ -76,7 +76,8 @@ func (a *applier) Run(ctx context.Context, client kubernetes.Interface, noRollba
 	rollback := NewApplier()
 
 	defer func() {
-		if noRollback || err == nil {
+		// Nothing is persisted in dry-run mode, so there's nothing to roll back.
+		if noRollback || err == nil || IsDryRun(ctx) {
 			return
 		}
 		if rollbackErr := rollback.Run(ctx, client, true); rollbackErr != nil {
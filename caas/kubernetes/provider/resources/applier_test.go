@@ -72,6 +72,31 @@ func (s *applierSuite) TestRunApplyFailedWithRollBackForNewResource(c *gc.C) {
 	c.Assert(applier.Run(context.TODO(), nil, false), gc.ErrorMatches, `something was wrong`)
 }
 
+func (s *applierSuite) TestRunApplyFailedDryRunNoRollBack(c *gc.C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	r1 := mocks.NewMockResource(ctrl)
+
+	applier := resources.NewApplierForTest()
+	applier.Apply(r1)
+
+	existingR1 := mocks.NewMockResource(ctrl)
+
+	gomock.InOrder(
+		r1.EXPECT().Clone().Return(existingR1),
+		existingR1.EXPECT().Get(gomock.Any(), gomock.Any()).Return(errors.NewNotFound(nil, "")),
+		r1.EXPECT().Apply(gomock.Any(), gomock.Any()).Return(errors.New("something was wrong")),
+	)
+	ctx := resources.WithDryRun(context.TODO())
+	c.Assert(applier.Run(ctx, nil, false), gc.ErrorMatches, `something was wrong`)
+}
+
+func (s *applierSuite) TestDryRun(c *gc.C) {
+	c.Assert(resources.IsDryRun(context.TODO()), jc.IsFalse)
+	c.Assert(resources.IsDryRun(resources.WithDryRun(context.TODO())), jc.IsTrue)
+}
+
 func (s *applierSuite) TestRunApplyFailedWithRollBackForExistingResource(c *gc.C) {
 	ctrl := gomock.NewController(c)
 	defer ctrl.Finish()
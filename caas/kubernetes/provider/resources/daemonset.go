@@ -51,10 +51,12 @@ func (ds *DaemonSet) Apply(ctx context.Context, client kubernetes.Interface) err
 	}
 	res, err := api.Patch(ctx, ds.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &ds.DaemonSet, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -82,6 +84,7 @@ func (ds *DaemonSet) Delete(ctx context.Context, client kubernetes.Interface) er
 	api := client.AppsV1().DaemonSets(ds.Namespace)
 	err := api.Delete(ctx, ds.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
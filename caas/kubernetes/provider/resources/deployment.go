@@ -51,10 +51,12 @@ func (d *Deployment) Apply(ctx context.Context, client kubernetes.Interface) err
 	}
 	res, err := api.Patch(ctx, d.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &d.Deployment, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -82,6 +84,7 @@ func (d *Deployment) Delete(ctx context.Context, client kubernetes.Interface) er
 	api := client.AppsV1().Deployments(d.Namespace)
 	err := api.Delete(ctx, d.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
@@ -0,0 +1,33 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context under which resources are applied and
+// deleted in server-side dry-run mode. The API server validates and admits
+// the changes, running any admission webhooks, but doesn't persist them.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether the context is in dry-run mode.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// dryRun returns the dry-run option for a request made with the context.
+func dryRun(ctx context.Context) []string {
+	if IsDryRun(ctx) {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
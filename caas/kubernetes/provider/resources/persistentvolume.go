@@ -49,10 +49,12 @@ func (pv *PersistentVolume) Apply(ctx context.Context, client kubernetes.Interfa
 	}
 	res, err := api.Patch(ctx, pv.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &pv.PersistentVolume, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -80,6 +82,7 @@ func (pv *PersistentVolume) Delete(ctx context.Context, client kubernetes.Interf
 	api := client.CoreV1().PersistentVolumes()
 	err := api.Delete(ctx, pv.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
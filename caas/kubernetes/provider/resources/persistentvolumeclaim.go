@@ -35,6 +35,26 @@ func NewPersistentVolumeClaim(name string, namespace string, in *corev1.Persiste
 	return &PersistentVolumeClaim{*in}
 }
 
+// ListPersistentVolumeClaims returns a list of persistent volume claims.
+func ListPersistentVolumeClaims(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) ([]PersistentVolumeClaim, error) {
+	api := client.CoreV1().PersistentVolumeClaims(namespace)
+	var items []PersistentVolumeClaim
+	for {
+		res, err := api.List(ctx, opts)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, v := range res.Items {
+			items = append(items, PersistentVolumeClaim{PersistentVolumeClaim: v})
+		}
+		if res.RemainingItemCount == nil || *res.RemainingItemCount == 0 {
+			break
+		}
+		opts.Continue = res.Continue
+	}
+	return items, nil
+}
+
 // Clone returns a copy of the resource.
 func (pvc *PersistentVolumeClaim) Clone() Resource {
 	clone := *pvc
@@ -50,10 +70,12 @@ func (pvc *PersistentVolumeClaim) Apply(ctx context.Context, client kubernetes.I
 	}
 	res, err := api.Patch(ctx, pvc.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &pvc.PersistentVolumeClaim, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -81,6 +103,7 @@ func (pvc *PersistentVolumeClaim) Delete(ctx context.Context, client kubernetes.
 	api := client.CoreV1().PersistentVolumeClaims(pvc.Namespace)
 	err := api.Delete(ctx, pvc.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
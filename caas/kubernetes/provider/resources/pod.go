@@ -5,6 +5,7 @@ package resources
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/juju/errors"
@@ -70,10 +71,12 @@ func (p *Pod) Apply(ctx context.Context, client kubernetes.Interface) error {
 	}
 	res, err := api.Patch(ctx, p.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &p.Pod, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -101,6 +104,7 @@ func (p *Pod) Delete(ctx context.Context, client kubernetes.Interface) error {
 	api := client.CoreV1().Pods(p.Namespace)
 	err := api.Delete(ctx, p.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
@@ -129,6 +133,9 @@ func (p *Pod) ComputeStatus(ctx context.Context, client kubernetes.Interface, no
 	case corev1.PodPending:
 		jujuStatus = status.Allocating
 	}
+	if message, ok := p.imagePullFailure(); ok {
+		return message, status.Error, now, nil
+	}
 	statusMessage := p.Status.Message
 	since := now
 	if statusMessage == "" {
@@ -155,3 +162,30 @@ func (p *Pod) ComputeStatus(ctx context.Context, client kubernetes.Interface, no
 	}
 	return statusMessage, jujuStatus, since, nil
 }
+
+// imagePullWaitingReasons are the reasons a container waits when its image
+// can't be pulled.
+var imagePullWaitingReasons = map[string]bool{
+	"ErrImagePull":     true,
+	"ImagePullBackOff": true,
+	"InvalidImageName": true,
+}
+
+// imagePullFailure returns a message describing the first container of the
+// pod whose image can't be pulled, if any.
+func (p *Pod) imagePullFailure() (string, bool) {
+	statuses := append([]corev1.ContainerStatus(nil), p.Status.InitContainerStatuses...)
+	statuses = append(statuses, p.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		waiting := cs.State.Waiting
+		if waiting == nil || !imagePullWaitingReasons[waiting.Reason] {
+			continue
+		}
+		message := fmt.Sprintf("image pull failed for container %q: %s %q", cs.Name, waiting.Reason, cs.Image)
+		if waiting.Message != "" {
+			message += ": " + waiting.Message
+		}
+		return message, true
+	}
+	return "", false
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/juju/errors"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	k8sconstants "github.com/juju/juju/caas/kubernetes/provider/constants"
+	"github.com/juju/juju/core/status"
+)
+
+// PodDisruptionBudget extends the k8s pod disruption budget.
+type PodDisruptionBudget struct {
+	policyv1beta1.PodDisruptionBudget
+}
+
+// NewPodDisruptionBudget creates a new pod disruption budget resource.
+func NewPodDisruptionBudget(name string, namespace string, in *policyv1beta1.PodDisruptionBudget) *PodDisruptionBudget {
+	if in == nil {
+		in = &policyv1beta1.PodDisruptionBudget{}
+	}
+	in.SetName(name)
+	in.SetNamespace(namespace)
+	return &PodDisruptionBudget{*in}
+}
+
+// Clone returns a copy of the resource.
+func (pdb *PodDisruptionBudget) Clone() Resource {
+	clone := *pdb
+	return &clone
+}
+
+// Apply patches the resource change.
+func (pdb *PodDisruptionBudget) Apply(ctx context.Context, client kubernetes.Interface) error {
+	api := client.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace)
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, &pdb.PodDisruptionBudget)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	res, err := api.Patch(ctx, pdb.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
+		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
+	})
+	if k8serrors.IsNotFound(err) {
+		res, err = api.Create(ctx, &pdb.PodDisruptionBudget, metav1.CreateOptions{
+			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
+		})
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	pdb.PodDisruptionBudget = *res
+	return nil
+}
+
+// Get refreshes the resource.
+func (pdb *PodDisruptionBudget) Get(ctx context.Context, client kubernetes.Interface) error {
+	api := client.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace)
+	res, err := api.Get(ctx, pdb.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return errors.NewNotFound(err, "k8s")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	pdb.PodDisruptionBudget = *res
+	return nil
+}
+
+// Delete removes the resource.
+func (pdb *PodDisruptionBudget) Delete(ctx context.Context, client kubernetes.Interface) error {
+	api := client.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace)
+	err := api.Delete(ctx, pdb.Name, metav1.DeleteOptions{
+		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// Events emitted by the resource.
+func (pdb *PodDisruptionBudget) Events(ctx context.Context, client kubernetes.Interface) ([]corev1.Event, error) {
+	return ListEventsForObject(ctx, client, pdb.Namespace, pdb.Name, "PodDisruptionBudget")
+}
+
+// ComputeStatus returns a juju status for the resource.
+func (pdb *PodDisruptionBudget) ComputeStatus(ctx context.Context, client kubernetes.Interface, now time.Time) (string, status.Status, time.Time, error) {
+	if pdb.DeletionTimestamp != nil {
+		return "", status.Terminated, pdb.DeletionTimestamp.Time, nil
+	}
+	return "", status.Active, pdb.CreationTimestamp.Time, nil
+}
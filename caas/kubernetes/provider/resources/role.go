@@ -0,0 +1,108 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/juju/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	k8sconstants "github.com/juju/juju/caas/kubernetes/provider/constants"
+	"github.com/juju/juju/core/status"
+)
+
+// Role extends the k8s role.
+type Role struct {
+	rbacv1.Role
+}
+
+// NewRole creates a new role resource.
+func NewRole(name string, namespace string, in *rbacv1.Role) *Role {
+	if in == nil {
+		in = &rbacv1.Role{}
+	}
+	in.SetName(name)
+	in.SetNamespace(namespace)
+	return &Role{*in}
+}
+
+// Clone returns a copy of the resource.
+func (r *Role) Clone() Resource {
+	clone := *r
+	return &clone
+}
+
+// Apply patches the resource change.
+func (r *Role) Apply(ctx context.Context, client kubernetes.Interface) error {
+	api := client.RbacV1().Roles(r.Namespace)
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, &r.Role)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	res, err := api.Patch(ctx, r.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
+		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
+	})
+	if k8serrors.IsNotFound(err) {
+		res, err = api.Create(ctx, &r.Role, metav1.CreateOptions{
+			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
+		})
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.Role = *res
+	return nil
+}
+
+// Get refreshes the resource.
+func (r *Role) Get(ctx context.Context, client kubernetes.Interface) error {
+	api := client.RbacV1().Roles(r.Namespace)
+	res, err := api.Get(ctx, r.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return errors.NewNotFound(err, "k8s")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	r.Role = *res
+	return nil
+}
+
+// Delete removes the resource.
+func (r *Role) Delete(ctx context.Context, client kubernetes.Interface) error {
+	api := client.RbacV1().Roles(r.Namespace)
+	err := api.Delete(ctx, r.Name, metav1.DeleteOptions{
+		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// Events emitted by the resource.
+func (r *Role) Events(ctx context.Context, client kubernetes.Interface) ([]corev1.Event, error) {
+	return ListEventsForObject(ctx, client, r.Namespace, r.Name, "Role")
+}
+
+// ComputeStatus returns a juju status for the resource.
+func (r *Role) ComputeStatus(ctx context.Context, client kubernetes.Interface, now time.Time) (string, status.Status, time.Time, error) {
+	if r.DeletionTimestamp != nil {
+		return "", status.Terminated, r.DeletionTimestamp.Time, nil
+	}
+	return "", status.Active, r.CreationTimestamp.Time, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/juju/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	k8sconstants "github.com/juju/juju/caas/kubernetes/provider/constants"
+	"github.com/juju/juju/core/status"
+)
+
+// RoleBinding extends the k8s role binding.
+type RoleBinding struct {
+	rbacv1.RoleBinding
+}
+
+// NewRoleBinding creates a new role binding resource.
+func NewRoleBinding(name string, namespace string, in *rbacv1.RoleBinding) *RoleBinding {
+	if in == nil {
+		in = &rbacv1.RoleBinding{}
+	}
+	in.SetName(name)
+	in.SetNamespace(namespace)
+	return &RoleBinding{*in}
+}
+
+// Clone returns a copy of the resource.
+func (rb *RoleBinding) Clone() Resource {
+	clone := *rb
+	return &clone
+}
+
+// Apply patches the resource change.
+func (rb *RoleBinding) Apply(ctx context.Context, client kubernetes.Interface) error {
+	api := client.RbacV1().RoleBindings(rb.Namespace)
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, &rb.RoleBinding)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	res, err := api.Patch(ctx, rb.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
+		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
+	})
+	if k8serrors.IsNotFound(err) {
+		res, err = api.Create(ctx, &rb.RoleBinding, metav1.CreateOptions{
+			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
+		})
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rb.RoleBinding = *res
+	return nil
+}
+
+// Get refreshes the resource.
+func (rb *RoleBinding) Get(ctx context.Context, client kubernetes.Interface) error {
+	api := client.RbacV1().RoleBindings(rb.Namespace)
+	res, err := api.Get(ctx, rb.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return errors.NewNotFound(err, "k8s")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	rb.RoleBinding = *res
+	return nil
+}
+
+// Delete removes the resource.
+func (rb *RoleBinding) Delete(ctx context.Context, client kubernetes.Interface) error {
+	api := client.RbacV1().RoleBindings(rb.Namespace)
+	err := api.Delete(ctx, rb.Name, metav1.DeleteOptions{
+		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// Events emitted by the resource.
+func (rb *RoleBinding) Events(ctx context.Context, client kubernetes.Interface) ([]corev1.Event, error) {
+	return ListEventsForObject(ctx, client, rb.Namespace, rb.Name, "RoleBinding")
+}
+
+// ComputeStatus returns a juju status for the resource.
+func (rb *RoleBinding) ComputeStatus(ctx context.Context, client kubernetes.Interface, now time.Time) (string, status.Status, time.Time, error) {
+	if rb.DeletionTimestamp != nil {
+		return "", status.Terminated, rb.DeletionTimestamp.Time, nil
+	}
+	return "", status.Active, rb.CreationTimestamp.Time, nil
+}
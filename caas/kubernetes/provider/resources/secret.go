@@ -35,6 +35,26 @@ func NewSecret(name string, namespace string, in *corev1.Secret) *Secret {
 	return &Secret{*in}
 }
 
+// ListSecrets returns a list of secrets.
+func ListSecrets(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) ([]Secret, error) {
+	api := client.CoreV1().Secrets(namespace)
+	var items []Secret
+	for {
+		res, err := api.List(ctx, opts)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, v := range res.Items {
+			items = append(items, Secret{Secret: v})
+		}
+		if res.RemainingItemCount == nil || *res.RemainingItemCount == 0 {
+			break
+		}
+		opts.Continue = res.Continue
+	}
+	return items, nil
+}
+
 // Clone returns a copy of the resource.
 func (s *Secret) Clone() Resource {
 	clone := *s
@@ -50,10 +70,12 @@ func (s *Secret) Apply(ctx context.Context, client kubernetes.Interface) error {
 	}
 	res, err := api.Patch(ctx, s.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &s.Secret, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -81,6 +103,7 @@ func (s *Secret) Delete(ctx context.Context, client kubernetes.Interface) error
 	api := client.CoreV1().Secrets(s.Namespace)
 	err := api.Delete(ctx, s.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
@@ -5,6 +5,7 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/juju/errors"
@@ -48,12 +49,20 @@ func (s *Service) Apply(ctx context.Context, client kubernetes.Interface) error
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if data, err = replaceServicePorts(data); err != nil {
+		return errors.Trace(err)
+	}
+	if data, err = clearServiceFields(data); err != nil {
+		return errors.Trace(err)
+	}
 	res, err := api.Patch(ctx, s.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &s.Service, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -63,6 +72,55 @@ func (s *Service) Apply(ctx context.Context, client kubernetes.Interface) error
 	return nil
 }
 
+// replaceServicePorts adds a directive to the service patch so that the
+// existing ports of the service are replaced by the patched ones, rather than
+// merged with them. Otherwise ports removed from the service, such as the
+// placeholder port of a newly deployed application, would be retained.
+func replaceServicePorts(data []byte) ([]byte, error) {
+	var patch map[string]interface{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, errors.Trace(err)
+	}
+	spec, _ := patch["spec"].(map[string]interface{})
+	ports, _ := spec["ports"].([]interface{})
+	if len(ports) == 0 {
+		return data, nil
+	}
+	spec["ports"] = append([]interface{}{
+		map[string]interface{}{"$patch": "replace"},
+	}, ports...)
+	return json.Marshal(patch)
+}
+
+// clearedServiceSpecFields are the fields of the service spec which are
+// left out of the encoded service when they're empty.
+var clearedServiceSpecFields = []string{
+	"loadBalancerSourceRanges",
+	"externalIPs",
+	"publishNotReadyAddresses",
+}
+
+// clearServiceFields sets the empty fields of the service spec which are
+// left out of the encoded service to null in the service patch, so that any
+// existing values are removed rather than retained by the merge.
+func clearServiceFields(data []byte) ([]byte, error) {
+	var patch map[string]interface{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, errors.Trace(err)
+	}
+	spec, _ := patch["spec"].(map[string]interface{})
+	if spec == nil {
+		spec = make(map[string]interface{})
+		patch["spec"] = spec
+	}
+	for _, field := range clearedServiceSpecFields {
+		if _, ok := spec[field]; !ok {
+			spec[field] = nil
+		}
+	}
+	return json.Marshal(patch)
+}
+
 // Get refreshes the resource.
 func (s *Service) Get(ctx context.Context, client kubernetes.Interface) error {
 	api := client.CoreV1().Services(s.Namespace)
@@ -81,6 +139,7 @@ func (s *Service) Delete(ctx context.Context, client kubernetes.Interface) error
 	api := client.CoreV1().Services(s.Namespace)
 	err := api.Delete(ctx, s.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
@@ -48,6 +48,41 @@ func (s *serviceSuite) TestApply(c *gc.C) {
 	c.Assert(result.GetAnnotations(), gc.DeepEquals, map[string]string{"a": "b"})
 }
 
+func (s *serviceSuite) TestApplyReplacesPorts(c *gc.C) {
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "placeholder", Port: 65535}},
+		},
+	}
+	c.Assert(resources.NewService("svc1", "test", svc).Apply(context.TODO(), s.client), jc.ErrorIsNil)
+
+	svc.Spec.Ports = []corev1.ServicePort{{Name: "http", Port: 80}}
+	c.Assert(resources.NewService("svc1", "test", svc).Apply(context.TODO(), s.client), jc.ErrorIsNil)
+
+	result, err := s.client.CoreV1().Services("test").Get(context.TODO(), "svc1", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Spec.Ports, jc.DeepEquals, []corev1.ServicePort{{Name: "http", Port: 80}})
+}
+
+func (s *serviceSuite) TestApplyClearsLoadBalancerSourceRanges(c *gc.C) {
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type:                     corev1.ServiceTypeLoadBalancer,
+			LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+		},
+	}
+	c.Assert(resources.NewService("svc1", "test", svc).Apply(context.TODO(), s.client), jc.ErrorIsNil)
+
+	svc.Spec.Type = corev1.ServiceTypeClusterIP
+	svc.Spec.LoadBalancerSourceRanges = nil
+	c.Assert(resources.NewService("svc1", "test", svc).Apply(context.TODO(), s.client), jc.ErrorIsNil)
+
+	result, err := s.client.CoreV1().Services("test").Get(context.TODO(), "svc1", metav1.GetOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Spec.Type, gc.Equals, corev1.ServiceTypeClusterIP)
+	c.Assert(result.Spec.LoadBalancerSourceRanges, gc.HasLen, 0)
+}
+
 func (s *serviceSuite) TestGet(c *gc.C) {
 	template := corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -51,10 +51,12 @@ func (ss *StatefulSet) Apply(ctx context.Context, client kubernetes.Interface) e
 	}
 	res, err := api.Patch(ctx, ss.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &ss.StatefulSet, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -82,6 +84,7 @@ func (ss *StatefulSet) Delete(ctx context.Context, client kubernetes.Interface)
 	api := client.AppsV1().StatefulSets(ss.Namespace)
 	err := api.Delete(ctx, ss.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
@@ -70,10 +70,12 @@ func (sc *StorageClass) Apply(ctx context.Context, client kubernetes.Interface)
 	}
 	res, err := api.Patch(ctx, sc.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
 		FieldManager: JujuFieldManager,
+		DryRun:       dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		res, err = api.Create(ctx, &sc.StorageClass, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
+			DryRun:       dryRun(ctx),
 		})
 	}
 	if err != nil {
@@ -101,6 +103,7 @@ func (sc *StorageClass) Delete(ctx context.Context, client kubernetes.Interface)
 	api := client.StorageV1().StorageClasses()
 	err := api.Delete(ctx, sc.Name, metav1.DeleteOptions{
 		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+		DryRun:            dryRun(ctx),
 	})
 	if k8serrors.IsNotFound(err) {
 		return nil
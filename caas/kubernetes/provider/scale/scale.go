@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/juju/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	apps "k8s.io/client-go/kubernetes/typed/apps/v1"
@@ -46,7 +47,9 @@ func DeploymentScalePatcher(deploy apps.DeploymentInterface) ScalePatcher {
 		s ...string,
 	) (int32, error) {
 		deployment, err := deploy.Patch(c, n, p, d, o, s...)
-		if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return 0, errors.NotFoundf("deployment %q", n)
+		} else if err != nil {
 			return 0, errors.Annotatef(err, "scale patching deployment %q", n)
 		}
 		return *deployment.Spec.Replicas, nil
@@ -66,7 +69,8 @@ func (p ScalePatcherFunc) Patch(
 }
 
 // PatchReplicasToScale patches the provided object name with the expected scale.
-// If the operation fails an error is returned
+// If the operation fails an error is returned, satisfying errors.IsNotFound
+// when the object doesn't exist.
 func PatchReplicasToScale(
 	ctx context.Context,
 	name string,
@@ -115,7 +119,9 @@ func StatefulSetScalePatcher(stateSet apps.StatefulSetInterface) ScalePatcher {
 		s ...string,
 	) (int32, error) {
 		ss, err := stateSet.Patch(c, n, p, d, o, s...)
-		if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return 0, errors.NotFoundf("statefulset %q", n)
+		} else if err != nil {
 			return 0, errors.Annotatef(err, "scale patching statefulset %q", n)
 		}
 		return *ss.Spec.Replicas, nil
@@ -140,3 +140,21 @@ func (s *ScaleSuite) TestInvalidScale(c *gc.C) {
 	)
 	c.Assert(errors.IsNotValid(err), jc.IsTrue)
 }
+
+func (s *ScaleSuite) TestScaleNotFound(c *gc.C) {
+	err := scale.PatchReplicasToScale(
+		context.TODO(),
+		"test",
+		3,
+		scale.StatefulSetScalePatcher(s.client.AppsV1().StatefulSets("test")),
+	)
+	c.Assert(errors.IsNotFound(err), jc.IsTrue)
+
+	err = scale.PatchReplicasToScale(
+		context.TODO(),
+		"test",
+		3,
+		scale.DeploymentScalePatcher(s.client.AppsV1().Deployments("test")),
+	)
+	c.Assert(errors.IsNotFound(err), jc.IsTrue)
+}
@@ -25,6 +25,21 @@ import (
 	storageprovider "github.com/juju/juju/storage/provider"
 )
 
+// MountPathStrategy determines the mount path of a filesystem which doesn't
+// specify one in its attachment.
+type MountPathStrategy string
+
+const (
+	// MountPathIndexed mounts the filesystem at a path including the
+	// application name and the filesystem's index. It is the default.
+	MountPathIndexed MountPathStrategy = "indexed"
+
+	// MountPathByName mounts the filesystem at a path derived from the
+	// storage name only, so that the path is the same for any application
+	// and filesystem ordering.
+	MountPathByName MountPathStrategy = "name"
+)
+
 // GetMountPathForFilesystem returns mount path.
 func GetMountPathForFilesystem(idx int, appName string, fs storage.KubernetesFilesystemParams) string {
 	if fs.Attachment != nil {
@@ -33,6 +48,23 @@ func GetMountPathForFilesystem(idx int, appName string, fs storage.KubernetesFil
 	return fmt.Sprintf("%s/fs/%s/%s/%d", constants.StorageBaseDir, appName, fs.StorageName, idx)
 }
 
+// MountPathForFilesystem returns the mount path of the filesystem using the
+// specified strategy. An empty strategy uses MountPathIndexed.
+func MountPathForFilesystem(
+	strategy MountPathStrategy, idx int, appName string, fs storage.KubernetesFilesystemParams,
+) (string, error) {
+	switch strategy {
+	case "", MountPathIndexed:
+		return GetMountPathForFilesystem(idx, appName, fs), nil
+	case MountPathByName:
+		if fs.Attachment != nil {
+			return fs.Attachment.Path, nil
+		}
+		return fmt.Sprintf("%s/fs/%s", constants.StorageBaseDir, fs.StorageName), nil
+	}
+	return "", errors.NotValidf("mount path strategy %q", strategy)
+}
+
 // FilesystemStatus returns filesystem status.
 func FilesystemStatus(pvcPhase corev1.PersistentVolumeClaimPhase) status.Status {
 	switch pvcPhase {
@@ -213,9 +245,16 @@ func FilesystemInfo(ctx context.Context, client kubernetes.Interface,
 		return nil, errors.Annotate(err, "unable to get persistent volume")
 	}
 
+	// Report the actual capacity of the bound volume, which may be larger
+	// than requested, falling back to the requested size if it's not known.
+	size := *pvc.Spec.Resources.Requests.Storage()
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		size = capacity
+	}
+
 	return &caas.FilesystemInfo{
 		StorageName:  storageName,
-		Size:         quantityAsMibiBytes(*pvc.Spec.Resources.Requests.Storage()),
+		Size:         quantityAsMibiBytes(size),
 		FilesystemId: string(pvc.UID),
 		MountPoint:   volumeMount.MountPath,
 		ReadOnly:     volumeMount.ReadOnly,
@@ -44,15 +44,17 @@ func ParseVolumeParams(name string, size resource.Quantity, storageAttr map[stri
 }
 
 var storageConfigFields = schema.Fields{
-	k8sconstants.StorageClass:       schema.String(),
-	k8sconstants.StorageProvisioner: schema.String(),
+	k8sconstants.StorageClass:         schema.String(),
+	k8sconstants.StorageProvisioner:   schema.String(),
+	k8sconstants.StorageReclaimPolicy: schema.String(),
 }
 
 var storageConfigChecker = schema.FieldMap(
 	storageConfigFields,
 	schema.Defaults{
-		k8sconstants.StorageClass:       schema.Omit,
-		k8sconstants.StorageProvisioner: schema.Omit,
+		k8sconstants.StorageClass:         schema.Omit,
+		k8sconstants.StorageProvisioner:   schema.Omit,
+		k8sconstants.StorageReclaimPolicy: schema.Omit,
 	},
 )
 
@@ -97,6 +99,14 @@ func ParseStorageConfig(attrs map[string]interface{}) (*StorageConfig, error) {
 	}
 	// By default, we'll retain volumes used for charm storage.
 	storageConfig.ReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	if policy, ok := coerced[k8sconstants.StorageReclaimPolicy].(string); ok {
+		switch p := corev1.PersistentVolumeReclaimPolicy(policy); p {
+		case corev1.PersistentVolumeReclaimRetain, corev1.PersistentVolumeReclaimDelete:
+			storageConfig.ReclaimPolicy = p
+		default:
+			return nil, errors.NotValidf("storage reclaim policy %q", policy)
+		}
+	}
 	storageConfig.Parameters = make(map[string]string)
 	for k, v := range attrs {
 		if !strings.HasPrefix(k, storageConfigParameterPrefix) {
@@ -144,6 +154,30 @@ func ParseStorageMode(attrs map[string]interface{}) (*corev1.PersistentVolumeAcc
 	return parseMode(coerced[k8sconstants.StorageMode].(string))
 }
 
+// singleNodeProvisioners are the provisioners of block storage which can
+// only be attached to a single node, and so can't be shared for writing
+// between units.
+var singleNodeProvisioners = map[string]bool{
+	"kubernetes.io/aws-ebs":    true,
+	"kubernetes.io/gce-pd":     true,
+	"kubernetes.io/azure-disk": true,
+	"kubernetes.io/cinder":     true,
+	"ebs.csi.aws.com":          true,
+	"pd.csi.storage.gke.io":    true,
+	"disk.csi.azure.com":       true,
+	"cinder.csi.openstack.org": true,
+}
+
+// ValidateStorageMode returns an error if the access mode is known not to be
+// supported by volumes from the provisioner. Unknown provisioners are
+// assumed to support any mode.
+func ValidateStorageMode(provisioner string, mode corev1.PersistentVolumeAccessMode) error {
+	if mode == corev1.ReadWriteMany && singleNodeProvisioners[provisioner] {
+		return errors.NotSupportedf("storage mode %q with provisioner %q", mode, provisioner)
+	}
+	return nil
+}
+
 // PushUniqueVolume ensures to only add unique volumes because k8s will not schedule pods if it has duplicated volumes.
 // The existing volume will be replaced if force sets to true.
 func PushUniqueVolume(podSpec *corev1.PodSpec, vol corev1.Volume, force bool) error {
@@ -4,11 +4,14 @@
 package storage_test
 
 import (
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	core "k8s.io/api/core/v1"
 
+	k8sconstants "github.com/juju/juju/caas/kubernetes/provider/constants"
 	"github.com/juju/juju/caas/kubernetes/provider/storage"
+	jujustorage "github.com/juju/juju/storage"
 	"github.com/juju/juju/testing"
 )
 
@@ -30,6 +33,28 @@ func (s *storageSuite) TestParseStorageConfig(c *gc.C) {
 	c.Assert(cfg.Parameters, jc.DeepEquals, map[string]string{"type": "gp2"})
 }
 
+func (s *storageSuite) TestParseStorageConfigReclaimPolicy(c *gc.C) {
+	cfg, err := storage.ParseStorageConfig(map[string]interface{}{
+		"storage-class": "juju-ebs",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.ReclaimPolicy, gc.Equals, core.PersistentVolumeReclaimRetain)
+
+	cfg, err = storage.ParseStorageConfig(map[string]interface{}{
+		"storage-class":          "juju-ebs",
+		"storage-reclaim-policy": "Delete",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.ReclaimPolicy, gc.Equals, core.PersistentVolumeReclaimDelete)
+
+	_, err = storage.ParseStorageConfig(map[string]interface{}{
+		"storage-class":          "juju-ebs",
+		"storage-reclaim-policy": "Recycle",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `storage reclaim policy "Recycle" not valid`)
+}
+
 func (s *storageSuite) TestGetStorageMode(c *gc.C) {
 	type testCase struct {
 		attrs map[string]interface{}
@@ -92,6 +117,54 @@ func (s *storageSuite) TestGetStorageMode(c *gc.C) {
 	}
 }
 
+func (s *storageSuite) TestValidateStorageMode(c *gc.C) {
+	for _, mode := range []core.PersistentVolumeAccessMode{core.ReadWriteOnce, core.ReadOnlyMany, core.ReadWriteMany} {
+		c.Check(storage.ValidateStorageMode("example.com/nfs", mode), jc.ErrorIsNil)
+	}
+	c.Check(storage.ValidateStorageMode("kubernetes.io/aws-ebs", core.ReadWriteOnce), jc.ErrorIsNil)
+	c.Check(storage.ValidateStorageMode("kubernetes.io/aws-ebs", core.ReadOnlyMany), jc.ErrorIsNil)
+
+	err := storage.ValidateStorageMode("kubernetes.io/aws-ebs", core.ReadWriteMany)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `storage mode "ReadWriteMany" with provisioner "kubernetes.io/aws-ebs" not supported`)
+}
+
+func (s *storageSuite) TestMountPathForFilesystem(c *gc.C) {
+	fs := jujustorage.KubernetesFilesystemParams{StorageName: "database"}
+	for _, t := range []struct {
+		strategy storage.MountPathStrategy
+		expected string
+	}{
+		{"", k8sconstants.StorageBaseDir + "/fs/gitlab/database/1"},
+		{storage.MountPathIndexed, k8sconstants.StorageBaseDir + "/fs/gitlab/database/1"},
+		{storage.MountPathByName, k8sconstants.StorageBaseDir + "/fs/database"},
+	} {
+		path, err := storage.MountPathForFilesystem(t.strategy, 1, "gitlab", fs)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(path, gc.Equals, t.expected)
+	}
+}
+
+func (s *storageSuite) TestMountPathForFilesystemAttachmentPath(c *gc.C) {
+	fs := jujustorage.KubernetesFilesystemParams{
+		StorageName: "database",
+		Attachment: &jujustorage.KubernetesFilesystemAttachmentParams{
+			Path: "/var/lib/database",
+		},
+	}
+	for _, strategy := range []storage.MountPathStrategy{storage.MountPathIndexed, storage.MountPathByName} {
+		path, err := storage.MountPathForFilesystem(strategy, 1, "gitlab", fs)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(path, gc.Equals, "/var/lib/database")
+	}
+}
+
+func (s *storageSuite) TestMountPathForFilesystemInvalidStrategy(c *gc.C) {
+	_, err := storage.MountPathForFilesystem("random", 0, "gitlab", jujustorage.KubernetesFilesystemParams{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `mount path strategy "random" not valid`)
+}
+
 func (s *storageSuite) TestPushUniqueVolume(c *gc.C) {
 	podSpec := &core.PodSpec{}
 
@@ -5,9 +5,11 @@
 package mocks
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	caas "github.com/juju/juju/caas"
 	watcher "github.com/juju/juju/core/watcher"
+	io "io"
 	reflect "reflect"
 )
 
@@ -35,46 +37,60 @@ func (m *MockApplication) EXPECT() *MockApplicationMockRecorder {
 }
 
 // Delete mocks base method
-func (m *MockApplication) Delete() error {
+func (m *MockApplication) Delete(arg0 context.Context, arg1 caas.DeleteOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete")
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockApplicationMockRecorder) Delete() *gomock.Call {
+func (mr *MockApplicationMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockApplication)(nil).Delete))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockApplication)(nil).Delete), arg0, arg1)
 }
 
 // Ensure mocks base method
-func (m *MockApplication) Ensure(arg0 caas.ApplicationConfig) error {
+func (m *MockApplication) Ensure(arg0 context.Context, arg1 caas.ApplicationConfig) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Ensure", arg0)
+	ret := m.ctrl.Call(m, "Ensure", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Ensure indicates an expected call of Ensure
-func (mr *MockApplicationMockRecorder) Ensure(arg0 interface{}) *gomock.Call {
+func (mr *MockApplicationMockRecorder) Ensure(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ensure", reflect.TypeOf((*MockApplication)(nil).Ensure), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ensure", reflect.TypeOf((*MockApplication)(nil).Ensure), arg0, arg1)
 }
 
 // Exists mocks base method
-func (m *MockApplication) Exists() (caas.DeploymentState, error) {
+func (m *MockApplication) Exists(arg0 context.Context) (caas.DeploymentState, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Exists")
+	ret := m.ctrl.Call(m, "Exists", arg0)
 	ret0, _ := ret[0].(caas.DeploymentState)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Exists indicates an expected call of Exists
-func (mr *MockApplicationMockRecorder) Exists() *gomock.Call {
+func (mr *MockApplicationMockRecorder) Exists(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockApplication)(nil).Exists))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockApplication)(nil).Exists), arg0)
+}
+
+// ForceDelete mocks base method
+func (m *MockApplication) ForceDelete(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceDelete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForceDelete indicates an expected call of ForceDelete
+func (mr *MockApplicationMockRecorder) ForceDelete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceDelete", reflect.TypeOf((*MockApplication)(nil).ForceDelete), arg0)
 }
 
 // Scale mocks base method
@@ -92,61 +108,76 @@ func (mr *MockApplicationMockRecorder) Scale(arg0 interface{}) *gomock.Call {
 }
 
 // State mocks base method
-func (m *MockApplication) State() (caas.ApplicationState, error) {
+func (m *MockApplication) State(arg0 context.Context) (caas.ApplicationState, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "State")
+	ret := m.ctrl.Call(m, "State", arg0)
 	ret0, _ := ret[0].(caas.ApplicationState)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // State indicates an expected call of State
-func (mr *MockApplicationMockRecorder) State() *gomock.Call {
+func (mr *MockApplicationMockRecorder) State(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockApplication)(nil).State))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockApplication)(nil).State), arg0)
 }
 
 // Units mocks base method
-func (m *MockApplication) Units() ([]caas.Unit, error) {
+func (m *MockApplication) Units(arg0 context.Context) ([]caas.Unit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Units")
+	ret := m.ctrl.Call(m, "Units", arg0)
 	ret0, _ := ret[0].([]caas.Unit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Units indicates an expected call of Units
-func (mr *MockApplicationMockRecorder) Units() *gomock.Call {
+func (mr *MockApplicationMockRecorder) Units(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Units", reflect.TypeOf((*MockApplication)(nil).Units))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Units", reflect.TypeOf((*MockApplication)(nil).Units), arg0)
+}
+
+// UnitLogs mocks base method
+func (m *MockApplication) UnitLogs(arg0 context.Context, arg1, arg2 string, arg3 caas.LogOptions) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnitLogs", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnitLogs indicates an expected call of UnitLogs
+func (mr *MockApplicationMockRecorder) UnitLogs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnitLogs", reflect.TypeOf((*MockApplication)(nil).UnitLogs), arg0, arg1, arg2, arg3)
 }
 
 // UpdatePorts mocks base method
-func (m *MockApplication) UpdatePorts(arg0 []caas.ServicePort, arg1 bool) error {
+func (m *MockApplication) UpdatePorts(arg0 context.Context, arg1 []caas.ServicePort, arg2 bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePorts", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdatePorts", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdatePorts indicates an expected call of UpdatePorts
-func (mr *MockApplicationMockRecorder) UpdatePorts(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockApplicationMockRecorder) UpdatePorts(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePorts", reflect.TypeOf((*MockApplication)(nil).UpdatePorts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePorts", reflect.TypeOf((*MockApplication)(nil).UpdatePorts), arg0, arg1, arg2)
 }
 
 // UpdateService mocks base method
-func (m *MockApplication) UpdateService(arg0 caas.ServiceParam) error {
+func (m *MockApplication) UpdateService(arg0 context.Context, arg1 caas.ServiceParam) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateService", arg0)
+	ret := m.ctrl.Call(m, "UpdateService", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateService indicates an expected call of UpdateService
-func (mr *MockApplicationMockRecorder) UpdateService(arg0 interface{}) *gomock.Call {
+func (mr *MockApplicationMockRecorder) UpdateService(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateService", reflect.TypeOf((*MockApplication)(nil).UpdateService), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateService", reflect.TypeOf((*MockApplication)(nil).UpdateService), arg0, arg1)
 }
 
 // Watch mocks base method
@@ -164,6 +195,21 @@ func (mr *MockApplicationMockRecorder) Watch() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockApplication)(nil).Watch))
 }
 
+// WatchDeletion mocks base method
+func (m *MockApplication) WatchDeletion() (watcher.NotifyWatcher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchDeletion")
+	ret0, _ := ret[0].(watcher.NotifyWatcher)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchDeletion indicates an expected call of WatchDeletion
+func (mr *MockApplicationMockRecorder) WatchDeletion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchDeletion", reflect.TypeOf((*MockApplication)(nil).WatchDeletion))
+}
+
 // WatchReplicas mocks base method
 func (m *MockApplication) WatchReplicas() (watcher.NotifyWatcher, error) {
 	m.ctrl.T.Helper()
@@ -178,3 +224,18 @@ func (mr *MockApplicationMockRecorder) WatchReplicas() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchReplicas", reflect.TypeOf((*MockApplication)(nil).WatchReplicas))
 }
+
+// WatchUnits mocks base method
+func (m *MockApplication) WatchUnits(arg0 context.Context) (watcher.StringsWatcher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchUnits", arg0)
+	ret0, _ := ret[0].(watcher.StringsWatcher)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchUnits indicates an expected call of WatchUnits
+func (mr *MockApplicationMockRecorder) WatchUnits(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchUnits", reflect.TypeOf((*MockApplication)(nil).WatchUnits), arg0)
+}
@@ -6,6 +6,7 @@ package lxd
 import (
 	"fmt"
 	"math"
+	"reflect"
 	"strings"
 	"time"
 
@@ -165,18 +166,39 @@ func (c *Container) AddDisk(name, path, source, pool string, readOnly bool) erro
 	if c.Devices == nil {
 		c.Devices = map[string]device{}
 	}
-	c.Devices[name] = map[string]string{
+	c.Devices[name] = diskDevice(path, source, pool, readOnly)
+	return nil
+}
+
+// HasDisk returns true if the container already has a disk device with the
+// input name, described exactly by the input arguments.
+func (c *Container) HasDisk(name, path, source, pool string, readOnly bool) bool {
+	existing, ok := c.Devices[name]
+	if !ok {
+		return false
+	}
+	return reflect.DeepEqual(existing, diskDevice(path, source, pool, readOnly))
+}
+
+// HasDevice returns true if the container has a device with the input name.
+func (c *Container) HasDevice(name string) bool {
+	_, ok := c.Devices[name]
+	return ok
+}
+
+func diskDevice(path, source, pool string, readOnly bool) device {
+	dev := map[string]string{
 		"path":   path,
 		"source": source,
 		"type":   "disk",
 	}
 	if pool != "" {
-		c.Devices[name]["pool"] = pool
+		dev["pool"] = pool
 	}
 	if readOnly {
-		c.Devices[name]["readonly"] = "true"
+		dev["readonly"] = "true"
 	}
-	return nil
+	return dev
 }
 
 // aliveStatuses is the list of status strings that indicate
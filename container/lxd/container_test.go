@@ -82,6 +82,19 @@ func (s *containerSuite) TestContainerAddDiskDevicePresentError(c *gc.C) {
 	c.Check(err, gc.ErrorMatches, `container "seeyounexttuesday" already has a device "root"`)
 }
 
+func (s *containerSuite) TestContainerHasDisk(c *gc.C) {
+	container := lxd.Container{}
+	err := container.AddDisk("root", "/", "source", "default", true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(container.HasDisk("root", "/", "source", "default", true), jc.IsTrue)
+	c.Check(container.HasDisk("root", "/", "source", "default", false), jc.IsFalse)
+	c.Check(container.HasDisk("root", "/mnt", "source", "default", true), jc.IsFalse)
+	c.Check(container.HasDisk("data", "/", "source", "default", true), jc.IsFalse)
+	c.Check(container.HasDevice("root"), jc.IsTrue)
+	c.Check(container.HasDevice("data"), jc.IsFalse)
+}
+
 func (s *containerSuite) TestFilterContainers(c *gc.C) {
 	ctrl := gomock.NewController(c)
 	defer ctrl.Finish()
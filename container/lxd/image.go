@@ -6,6 +6,7 @@ package lxd
 import (
 	"fmt"
 	"path"
+	"time"
 
 	"github.com/juju/errors"
 	jujuarch "github.com/juju/utils/v2/arch"
@@ -170,6 +171,62 @@ func (s *Server) CopyRemoteImage(
 	return nil
 }
 
+// ImagePrunePolicy determines which cached images are removed by PruneImages.
+type ImagePrunePolicy struct {
+	// MaxAge is how long an image may go without being
+	// uploaded or used before it is eligible for removal.
+	MaxAge time.Duration
+}
+
+// PruneImages removes images from the local cache that are not the base
+// image of any container and have been neither uploaded nor used within
+// the policy's MaxAge. Images that LXD keeps updated automatically are
+// always retained.
+// The fingerprints of the removed images are returned.
+func (s *Server) PruneImages(policy ImagePrunePolicy) ([]string, error) {
+	containers, err := s.GetContainers()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	inUse := make(map[string]bool)
+	for _, c := range containers {
+		if fingerprint := c.Config["volatile.base_image"]; fingerprint != "" {
+			inUse[fingerprint] = true
+		}
+	}
+
+	images, err := s.GetImages()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cutoff := s.Clock().Now().Add(-policy.MaxAge)
+
+	var removed []string
+	for _, image := range images {
+		if image.AutoUpdate || inUse[image.Fingerprint] {
+			continue
+		}
+		lastActive := image.UploadedAt
+		if image.LastUsedAt.After(lastActive) {
+			lastActive = image.LastUsedAt
+		}
+		if lastActive.After(cutoff) {
+			continue
+		}
+
+		op, err := s.DeleteImage(image.Fingerprint)
+		if err != nil {
+			return removed, errors.Annotatef(err, "removing image %q", image.Fingerprint)
+		}
+		if err := op.Wait(); err != nil {
+			return removed, errors.Annotatef(err, "removing image %q", image.Fingerprint)
+		}
+		logger.Debugf("removed unused image %q", image.Fingerprint)
+		removed = append(removed, image.Fingerprint)
+	}
+	return removed, nil
+}
+
 // seriesLocalAlias returns the alias to assign to images for the
 // specified series. The alias is juju-specific, to support the
 // user supplying a customised image (e.g. CentOS with cloud-init).
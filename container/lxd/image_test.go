@@ -5,6 +5,7 @@ package lxd_test
 
 import (
 	"errors"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	jc "github.com/juju/testing/checkers"
@@ -13,6 +14,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/container/lxd"
+	"github.com/juju/juju/container/lxd/mocks"
 	lxdtesting "github.com/juju/juju/container/lxd/testing"
 )
 
@@ -194,3 +196,46 @@ func (s *imageSuite) TestSeriesRemoteAliasesNotSupported(c *gc.C) {
 	_, err = lxd.SeriesRemoteAliases("opensuseleap", "s390x")
 	c.Assert(err, gc.ErrorMatches, `series "opensuseleap" not supported`)
 }
+
+func (s *imageSuite) TestPruneImages(c *gc.C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+	iSvr := s.NewMockServer(ctrl)
+
+	now := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-48 * time.Hour)
+	recent := now.Add(-time.Hour)
+
+	clock := mocks.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(now).AnyTimes()
+
+	deleteOp := lxdtesting.NewMockOperation(ctrl)
+	deleteOp.EXPECT().Wait().Return(nil)
+
+	containers := []lxdapi.Container{{
+		Name: "juju-0",
+		ContainerPut: lxdapi.ContainerPut{
+			Config: map[string]string{"volatile.base_image": "in-use"},
+		},
+	}}
+	images := []lxdapi.Image{
+		{Fingerprint: "in-use", UploadedAt: old},
+		{Fingerprint: "uploaded-recently", UploadedAt: recent},
+		{Fingerprint: "used-recently", UploadedAt: old, LastUsedAt: recent},
+		{Fingerprint: "auto-update", UploadedAt: old, ImagePut: lxdapi.ImagePut{AutoUpdate: true}},
+		{Fingerprint: "orphaned", UploadedAt: old, LastUsedAt: old},
+	}
+	exp := iSvr.EXPECT()
+	gomock.InOrder(
+		exp.GetContainers().Return(containers, nil),
+		exp.GetImages().Return(images, nil),
+		exp.DeleteImage("orphaned").Return(deleteOp, nil),
+	)
+
+	jujuSvr, err := lxd.NewTestingServer(iSvr, clock)
+	c.Assert(err, jc.ErrorIsNil)
+
+	removed, err := jujuSvr.PruneImages(lxd.ImagePrunePolicy{MaxAge: 24 * time.Hour})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(removed, gc.DeepEquals, []string{"orphaned"})
+}
@@ -148,7 +148,7 @@ func internalConfigureLXDProxies(
 		"core.proxy_http":         proxies.Http,
 		"core.proxy_https":        proxies.Https,
 		"core.proxy_ignore_hosts": proxies.NoProxy,
-	}))
+	}, ""))
 }
 
 // df returns the number of free bytes on the file system at the given path
@@ -46,13 +46,13 @@ func (s *Server) EnableHTTPSListener() error {
 	//      the loopback and LXC bridges that we are using.
 	if err := s.UpdateServerConfig(map[string]string{
 		"core.https_address": "[::]",
-	}); err != nil {
+	}, ""); err != nil {
 		cause := errors.Cause(err)
 		if strings.HasSuffix(cause.Error(), errIPV6NotSupported) {
 			// Fall back to IPv4 only.
 			return errors.Trace(s.UpdateServerConfig(map[string]string{
 				"core.https_address": "0.0.0.0",
-			}))
+			}, ""))
 		}
 		return errors.Trace(err)
 	}
@@ -4,6 +4,8 @@
 package lxd
 
 import (
+	"strings"
+
 	"github.com/juju/clock"
 	"github.com/juju/errors"
 	"github.com/juju/utils/v2/arch"
@@ -144,19 +146,51 @@ func (s *Server) ServerVersion() string {
 	return s.serverVersion
 }
 
-// UpdateServerConfig updates the server configuration with the input values.
-func (s *Server) UpdateServerConfig(cfg map[string]string) error {
+// ErrServerConfigModified is returned (as the cause of the error) by
+// UpdateServerConfig when the server configuration has been changed since
+// the given ETag was obtained.
+var ErrServerConfigModified = errors.New("server configuration has been modified since it was read")
+
+// GetServerConfig returns the server configuration along with the ETag
+// identifying this version of it. The ETag can be passed to
+// UpdateServerConfig to ensure the configuration has not been changed by
+// another client in the meantime.
+func (s *Server) GetServerConfig() (map[string]interface{}, string, error) {
 	svr, eTag, err := s.GetServer()
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	return svr.Config, eTag, nil
+}
+
+// UpdateServerConfig updates the server configuration with the input values.
+// If eTag is not empty, the update is only applied if the server
+// configuration has not been modified since the ETag was obtained from
+// GetServerConfig; otherwise an error satisfying ErrServerConfigModified
+// is returned.
+func (s *Server) UpdateServerConfig(cfg map[string]string, eTag string) error {
+	svr, currentETag, err := s.GetServer()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if eTag == "" {
+		eTag = currentETag
+	} else if eTag != currentETag {
+		return errors.Trace(ErrServerConfigModified)
+	}
 	if svr.Config == nil {
 		svr.Config = make(map[string]interface{})
 	}
 	for k, v := range cfg {
 		svr.Config[k] = v
 	}
-	return errors.Trace(s.UpdateServer(svr.Writable(), eTag))
+	// LXD checks the ETag too, so a change made since the configuration
+	// was read above is also rejected.
+	err = s.UpdateServer(svr.Writable(), eTag)
+	if isLXDETagMismatch(err) {
+		return errors.Trace(ErrServerConfigModified)
+	}
+	return errors.Trace(err)
 }
 
 // UpdateContainerConfig updates the configuration for the container with the
@@ -315,3 +349,9 @@ func (s *Server) SupportedArches() []string {
 func IsLXDNotFound(err error) bool {
 	return err != nil && err.Error() == "not found"
 }
+
+// isLXDETagMismatch checks if an error from the LXD API indicates that an
+// update was rejected because the entity's ETag has changed.
+func isLXDETagMismatch(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "ETag doesn't match")
+}
@@ -5,6 +5,7 @@ package lxd_test
 
 import (
 	"github.com/golang/mock/gomock"
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/lxc/lxd/shared/api"
 	gc "gopkg.in/check.v1"
@@ -33,10 +34,88 @@ func (s *serverSuite) TestUpdateServerConfig(c *gc.C) {
 	jujuSvr, err := lxd.NewServer(cSvr)
 	c.Assert(err, jc.ErrorIsNil)
 
-	err = jujuSvr.UpdateServerConfig(map[string]string{"key1": "val1"})
+	err = jujuSvr.UpdateServerConfig(map[string]string{"key1": "val1"}, "")
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *serverSuite) TestGetServerConfig(c *gc.C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+	cSvr := lxdtesting.NewMockContainerServer(ctrl)
+
+	svr := &api.Server{ServerPut: api.ServerPut{
+		Config: map[string]interface{}{"core.https_address": "[::]"},
+	}}
+	cSvr.EXPECT().GetServer().Return(svr, lxdtesting.ETag, nil).Times(2)
+
+	jujuSvr, err := lxd.NewServer(cSvr)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cfg, eTag, err := jujuSvr.GetServerConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cfg, gc.DeepEquals, map[string]interface{}{"core.https_address": "[::]"})
+	c.Check(eTag, gc.Equals, lxdtesting.ETag)
+}
+
+func (s *serverSuite) TestUpdateServerConfigWithETag(c *gc.C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+	cSvr := lxdtesting.NewMockContainerServer(ctrl)
+
+	updateReq := api.ServerPut{Config: map[string]interface{}{"key1": "val1"}}
+	gomock.InOrder(
+		cSvr.EXPECT().GetServer().Return(&api.Server{}, lxdtesting.ETag, nil).Times(2),
+		cSvr.EXPECT().UpdateServer(updateReq, lxdtesting.ETag).Return(nil),
+	)
+
+	jujuSvr, err := lxd.NewServer(cSvr)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = jujuSvr.UpdateServerConfig(map[string]string{"key1": "val1"}, lxdtesting.ETag)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *serverSuite) TestUpdateServerConfigConcurrentModification(c *gc.C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+	cSvr := lxdtesting.NewMockContainerServer(ctrl)
+
+	// The configuration was changed by another client after it was read,
+	// so the server reports a different ETag. No update is made.
+	gomock.InOrder(
+		cSvr.EXPECT().GetServer().Return(&api.Server{}, lxdtesting.ETag, nil),
+		cSvr.EXPECT().GetServer().Return(&api.Server{}, "modified-etag", nil),
+	)
+
+	jujuSvr, err := lxd.NewServer(cSvr)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = jujuSvr.UpdateServerConfig(map[string]string{"key1": "val1"}, lxdtesting.ETag)
+	c.Assert(errors.Cause(err), gc.Equals, lxd.ErrServerConfigModified)
+	c.Assert(err, gc.ErrorMatches, "server configuration has been modified since it was read")
+}
+
+func (s *serverSuite) TestUpdateServerConfigModifiedBeforeUpdate(c *gc.C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+	cSvr := lxdtesting.NewMockContainerServer(ctrl)
+
+	// The configuration was changed by another client after it was read
+	// for the update, so LXD rejects the caller's ETag.
+	updateReq := api.ServerPut{Config: map[string]interface{}{"key1": "val1"}}
+	gomock.InOrder(
+		cSvr.EXPECT().GetServer().Return(&api.Server{}, lxdtesting.ETag, nil).Times(2),
+		cSvr.EXPECT().UpdateServer(updateReq, lxdtesting.ETag).Return(
+			errors.New("ETag doesn't match: modified-etag vs "+lxdtesting.ETag)),
+	)
+
+	jujuSvr, err := lxd.NewServer(cSvr)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = jujuSvr.UpdateServerConfig(map[string]string{"key1": "val1"}, lxdtesting.ETag)
+	c.Assert(errors.Cause(err), gc.Equals, lxd.ErrServerConfigModified)
+}
+
 func (s *serverSuite) TestUpdateContainerConfig(c *gc.C) {
 	ctrl := gomock.NewController(c)
 	defer ctrl.Finish()
@@ -33,6 +33,10 @@ type NewAPIConnectionParams struct {
 	OpenAPI api.OpenFunc
 
 	// DialOpts contains the options used to dial the API connection.
+	// Its Timeout and MaxAttempts bound how long and how many times
+	// each controller address is dialed, so that a caller can fail
+	// fast when the controller is unreachable, and its Context allows
+	// the caller to abort the connection attempt.
 	DialOpts api.DialOpts
 
 	// AccountDetails contains the account details to use for logging
@@ -45,6 +49,79 @@ type NewAPIConnectionParams struct {
 	// will be scoped to the model with that UUID; otherwise it will be
 	// scoped to the controller.
 	ModelUUID string
+
+	// ClientCert and ClientKey optionally hold a PEM encoded client
+	// certificate and private key presented during the TLS handshake to
+	// controllers, or front ends, that require mutual TLS. They are a
+	// transport credential only: the login still uses the password or
+	// macaroons held in AccountDetails.
+	ClientCert string
+	ClientKey  string
+
+	// LogLabel optionally identifies the connection (e.g. by controller
+	// or model name) in the log messages emitted while connecting, so
+	// that messages from concurrent connections can be told apart.
+	LogLabel string
+
+	// ConnectionSource, if non-nil, is set to the source of the addresses
+	// the connection was established with, so that a client connected to
+	// an unexpected address can tell why.
+	ConnectionSource *ConnectionSource
+
+	// StickyEndpoint, if true, keeps the first of the controller's cached
+	// API endpoints (the last address successfully connected to) first
+	// when the endpoints are updated, even if another address was
+	// connected to this time. It is demoted only once the controller no
+	// longer reports it.
+	StickyEndpoint bool
+
+	// PreferredAddress optionally holds an API address to dial ahead of
+	// the controller's cached endpoints, for example to pin the
+	// connection to a known-good controller machine. If
+	// OnlyPreferredAddress is true, no other address is dialed.
+	PreferredAddress     string
+	OnlyPreferredAddress bool
+}
+
+// ConnectionSource identifies where the addresses used to establish an
+// API connection came from.
+type ConnectionSource string
+
+const (
+	// ConnectionSourceStore means the connection was established with
+	// the controller addresses cached in the client store.
+	ConnectionSourceStore ConnectionSource = "cached controller addresses"
+
+	// ConnectionSourceRedirect means the controller redirected the
+	// client to the addresses of another controller.
+	ConnectionSourceRedirect ConnectionSource = "redirected controller addresses"
+)
+
+// connectionLogger logs messages about a single API connection, prefixing
+// them with the connection's label if it has one.
+type connectionLogger struct {
+	label string
+}
+
+func (l connectionLogger) logf(level loggo.Level, format string, args ...interface{}) {
+	if l.label != "" {
+		format = "[%s] " + format
+		args = append([]interface{}{l.label}, args...)
+	}
+	// Report the caller of the level specific method.
+	logger.LogCallf(3, level, format, args...)
+}
+
+func (l connectionLogger) Infof(format string, args ...interface{}) {
+	l.logf(loggo.INFO, format, args...)
+}
+
+func (l connectionLogger) Warningf(format string, args ...interface{}) {
+	l.logf(loggo.WARNING, format, args...)
+}
+
+func (l connectionLogger) Errorf(format string, args ...interface{}) {
+	l.logf(loggo.ERROR, format, args...)
 }
 
 var errNoAddresses = errors.New("no API addresses")
@@ -63,6 +140,7 @@ func NewAPIConnection(args NewAPIConnectionParams) (_ api.Connection, err error)
 	if args.OpenAPI == nil {
 		args.OpenAPI = api.Open
 	}
+	logger := connectionLogger{label: args.LogLabel}
 	apiInfo, controller, err := connectionInfo(args)
 	if err != nil {
 		return nil, errors.Annotatef(err, "cannot work out how to connect")
@@ -76,28 +154,12 @@ func NewAPIConnection(args NewAPIConnectionParams) (_ api.Connection, err error)
 	dnsCache := dnsCacheMap(controller.DNSCache).copy()
 	args.DialOpts.DNSCache = dnsCache
 	logger.Infof("connecting to API addresses: %v", apiInfo.Addrs)
-	st, err := args.OpenAPI(apiInfo, args.DialOpts)
+	st, redirected, err := openFollowingRedirect(args.OpenAPI, apiInfo, args.DialOpts)
 	if err != nil {
-		redirErr, ok := errors.Cause(err).(*api.RedirectError)
-		if !ok || !redirErr.FollowRedirect {
-			return nil, errors.Trace(err)
-		}
-		// We've been told to connect to a different API server,
-		// so do so. Note that we don't copy the account details
-		// because the account on the redirected server may well
-		// be different - we'll use macaroon authentication
-		// directly without sending account details.
-		// Copy the API info because it's possible that the
-		// apiConfigConnect is still using it concurrently.
-		apiInfo = &api.Info{
-			ModelTag: apiInfo.ModelTag,
-			Addrs:    usableHostPorts(redirErr.Servers).Strings(),
-			CACert:   redirErr.CACert,
-		}
-		st, err = args.OpenAPI(apiInfo, args.DialOpts)
-		if err != nil {
-			return nil, errors.Annotatef(err, "cannot connect to redirected address")
-		}
+		return nil, errors.Trace(err)
+	}
+	if redirected {
+		args.reportSource(logger, st, ConnectionSourceRedirect)
 		// TODO(rog) update cached model addresses.
 		// TODO(rog) should we do something with the logged-in username?
 		return st, nil
@@ -107,6 +169,7 @@ func NewAPIConnection(args NewAPIConnectionParams) (_ api.Connection, err error)
 			_ = st.Close()
 		}
 	}()
+	args.reportSource(logger, st, ConnectionSourceStore)
 
 	// Update API addresses if they've changed. Error is non-fatal.
 	// Note that in the redirection case, we won't update the addresses
@@ -125,11 +188,12 @@ func NewAPIConnection(args NewAPIConnectionParams) (_ api.Connection, err error)
 		IPAddrConnectedTo: st.IPAddr(),
 		CurrentHostPorts:  hostPorts,
 		DNSCache:          dnsCache,
+		StickyEndpoint:    args.StickyEndpoint,
 	}
 	if host := st.PublicDNSName(); host != "" {
 		params.PublicDNSName = &host
 	}
-	err = updateControllerDetailsFromLogin(args.Store, args.ControllerName, controller, params)
+	err = updateControllerDetailsFromLogin(logger, args.Store, args.ControllerName, controller, params)
 	if err != nil {
 		logger.Errorf("cannot cache API addresses: %v", err)
 	}
@@ -166,6 +230,71 @@ func NewAPIConnection(args NewAPIConnectionParams) (_ api.Connection, err error)
 	return st, nil
 }
 
+// NewAPIConnectionFromInfo returns an api.Connection to the controller
+// described by the given info, for clients that already know the
+// controller's coordinates. Unlike NewAPIConnection it neither reads nor
+// updates a client store, but it fails and follows redirects in the same
+// way.
+func NewAPIConnectionFromInfo(info *api.Info, dialOpts api.DialOpts) (api.Connection, error) {
+	if len(info.Addrs) == 0 {
+		return nil, errNoAddresses
+	}
+	st, _, err := openFollowingRedirect(api.Open, info, dialOpts)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return st, nil
+}
+
+// openFollowingRedirect opens an API connection with the given info. If
+// the controller asks to be followed to another controller, the connection
+// is made to that controller instead and redirected is true.
+func openFollowingRedirect(openAPI api.OpenFunc, apiInfo *api.Info, dialOpts api.DialOpts) (_ api.Connection, redirected bool, _ error) {
+	st, err := openAPI(apiInfo, dialOpts)
+	if err == nil {
+		return st, false, nil
+	}
+	redirErr, ok := errors.Cause(err).(*api.RedirectError)
+	if !ok || !redirErr.FollowRedirect {
+		return nil, false, errors.Trace(err)
+	}
+	// We've been told to connect to a different API server,
+	// so do so. Note that we don't copy the account details
+	// because the account on the redirected server may well
+	// be different - we'll use macaroon authentication
+	// directly without sending account details.
+	// Copy the API info because it's possible that the
+	// apiConfigConnect is still using it concurrently.
+	apiInfo = &api.Info{
+		ModelTag: apiInfo.ModelTag,
+		Addrs:    usableHostPorts(redirErr.Servers).Strings(),
+		CACert:   redirErr.CACert,
+	}
+	st, err = openAPI(apiInfo, dialOpts)
+	if err != nil {
+		return nil, false, errors.Annotatef(err, "cannot connect to redirected address")
+	}
+	return st, true, nil
+}
+
+// dialAddrs returns the addresses to dial, given the controller's
+// endpoints and the address (if any) which is preferred over them.
+func dialAddrs(endpoints []string, preferred string, onlyPreferred bool) []string {
+	if preferred == "" {
+		return endpoints
+	}
+	if onlyPreferred {
+		return []string{preferred}
+	}
+	addrs := []string{preferred}
+	for _, addr := range endpoints {
+		if addr != preferred {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
 // connectionInfo returns connection information suitable for
 // connecting to the controller and model specified in the given
 // parameters. If there are no addresses known for the controller,
@@ -178,7 +307,7 @@ func connectionInfo(args NewAPIConnectionParams) (*api.Info, *jujuclient.Control
 	}
 
 	apiInfo := &api.Info{
-		Addrs:  controller.APIEndpoints,
+		Addrs:  dialAddrs(controller.APIEndpoints, args.PreferredAddress, args.OnlyPreferredAddress),
 		CACert: controller.CACert,
 	}
 	if controller.Proxy != nil {
@@ -190,6 +319,10 @@ func connectionInfo(args NewAPIConnectionParams) (*api.Info, *jujuclient.Control
 	if controller.PublicDNSName != "" {
 		apiInfo.SNIHostName = controller.PublicDNSName
 	}
+	if args.ClientCert != "" {
+		apiInfo.ClientCert = args.ClientCert
+		apiInfo.ClientKey = args.ClientKey
+	}
 	if args.AccountDetails == nil {
 		apiInfo.SkipLogin = true
 		return apiInfo, controller, nil
@@ -220,6 +353,30 @@ func usableHostPorts(hps []network.MachineHostPorts) network.HostPorts {
 	return collapsed.FilterUnusable().Unique()
 }
 
+// reportSource logs the address the connection was established with and
+// the source of that address, and records the source if requested.
+func (args NewAPIConnectionParams) reportSource(logger connectionLogger, st api.Connection, source ConnectionSource) {
+	logger.Infof("connected to API address %q from %s", st.Addr(), source)
+	if args.ConnectionSource != nil {
+		*args.ConnectionSource = source
+	}
+}
+
+// ConnectedHostPort returns the API endpoint that the given connection
+// was established with, so that clients can report which of the
+// controller's addresses they are connected to.
+func ConnectedHostPort(conn api.Connection) (network.HostPort, error) {
+	addr := conn.Addr()
+	if addr == "" {
+		return nil, errors.NotFoundf("connected address")
+	}
+	hp, err := network.ParseMachineHostPort(addr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return *hp, nil
+}
+
 // addrsChanged reports whether the two slices
 // are different. Order is important.
 func addrsChanged(a, b []string) bool {
@@ -265,6 +422,10 @@ type UpdateControllerParams struct {
 
 	// MachineCount (when set) is the total number of machines in the models.
 	MachineCount *int
+
+	// StickyEndpoint (when set) keeps the first cached API endpoint first,
+	// ahead of AddrConnectedTo, as long as it is still a current address.
+	StickyEndpoint bool
 }
 
 // UpdateControllerDetailsFromLogin writes any new api addresses and other relevant details
@@ -278,10 +439,11 @@ func UpdateControllerDetailsFromLogin(
 	if err != nil {
 		return errors.Trace(err)
 	}
-	return updateControllerDetailsFromLogin(store, controllerName, controllerDetails, params)
+	return updateControllerDetailsFromLogin(connectionLogger{}, store, controllerName, controllerDetails, params)
 }
 
 func updateControllerDetailsFromLogin(
+	logger connectionLogger,
 	store jujuclient.ControllerStore,
 	controllerName string, details *jujuclient.ControllerDetails,
 	params UpdateControllerParams,
@@ -292,6 +454,13 @@ func updateControllerDetailsFromLogin(
 	if err == nil {
 		moveToFront(host, hostPorts)
 	}
+	// Keep the previously cached first endpoint ahead of the address
+	// connected to if requested. The connected address is the only one
+	// added to those reported by the controller, so the cached endpoint
+	// is only found if the controller still reports it.
+	if params.StickyEndpoint && len(details.APIEndpoints) > 0 {
+		moveToFront(details.APIEndpoints[0], hostPorts)
+	}
 	// Move the IP address used to the front of the DNS cache entry
 	// (if present) so that it will be the first address dialed.
 	ipHost, _, err := net.SplitHostPort(params.IPAddrConnectedTo)
@@ -309,7 +478,13 @@ func updateControllerDetailsFromLogin(
 	}
 
 	newDetails.AgentVersion = params.AgentVersion
-	newDetails.APIEndpoints = hostPorts
+	if len(hostPorts) == 0 {
+		// A controller that temporarily reports no usable addresses
+		// shouldn't wipe out the known-good cached endpoints.
+		logger.Warningf("no usable API addresses for controller %q, keeping cached endpoints %v", controllerName, details.APIEndpoints)
+	} else {
+		newDetails.APIEndpoints = hostPorts
+	}
 	newDetails.DNSCache = params.DNSCache
 	if params.MachineCount != nil {
 		newDetails.MachineCount = params.MachineCount
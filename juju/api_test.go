@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/juju/loggo"
 	"github.com/juju/names/v4"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -153,6 +154,112 @@ func (s *NewAPIClientSuite) TestUpdatesPublicDNSName(c *gc.C) {
 	c.Assert(store.Controllers["controllername"].PublicDNSName, gc.Equals, "somewhere.invalid")
 }
 
+func (s *NewAPIClientSuite) TestNoHostPortsKeepsCachedEndpoints(c *gc.C) {
+	apiOpen := func(apiInfo *api.Info, opts api.DialOpts) (api.Connection, error) {
+		return mockedAPIState(noFlags), nil
+	}
+
+	store := newClientStore(c, "controllername")
+	_, err := newAPIConnectionFromNames(c, "controllername", "", store, apiOpen)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(store.Controllers["controllername"].APIEndpoints, jc.DeepEquals, []string{"0.1.2.3:5678"})
+	c.Assert(store.Controllers["controllername"].AgentVersion, gc.Equals, "1.2.3")
+}
+
+func (s *NewAPIClientSuite) TestLogLabel(c *gc.C) {
+	var tw loggo.TestWriter
+	c.Assert(loggo.RegisterWriter("api-log-label", &tw), jc.ErrorIsNil)
+	defer loggo.RemoveWriter("api-log-label")
+
+	apiOpen := func(apiInfo *api.Info, opts api.DialOpts) (api.Connection, error) {
+		return mockedAPIState(mockedHostPort | mockedModelTag), nil
+	}
+	store := newClientStore(c, "noconfig")
+	accountDetails, err := store.AccountDetails("noconfig")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = juju.NewAPIConnection(juju.NewAPIConnectionParams{
+		Store:          store,
+		ControllerName: "noconfig",
+		DialOpts:       api.DefaultDialOpts(),
+		OpenAPI:        apiOpen,
+		AccountDetails: accountDetails,
+		LogLabel:       "noconfig",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tw.Log(), jc.LogMatches, jc.SimpleMessages{{
+		loggo.INFO, `\[noconfig\] connecting to API addresses: .*`,
+	}, {
+		loggo.INFO, `\[noconfig\] connected to API address .* from cached controller addresses`,
+	}})
+}
+
+func (s *NewAPIClientSuite) TestLogLabelControllerDetailsUpdate(c *gc.C) {
+	var tw loggo.TestWriter
+	c.Assert(loggo.RegisterWriter("api-log-label", &tw), jc.ErrorIsNil)
+	defer loggo.RemoveWriter("api-log-label")
+
+	apiOpen := func(apiInfo *api.Info, opts api.DialOpts) (api.Connection, error) {
+		return mockedAPIState(noFlags), nil
+	}
+	store := newClientStore(c, "noconfig")
+	accountDetails, err := store.AccountDetails("noconfig")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = juju.NewAPIConnection(juju.NewAPIConnectionParams{
+		Store:          store,
+		ControllerName: "noconfig",
+		DialOpts:       api.DefaultDialOpts(),
+		OpenAPI:        apiOpen,
+		AccountDetails: accountDetails,
+		LogLabel:       "noconfig",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tw.Log(), jc.LogMatches, jc.SimpleMessages{{
+		loggo.WARNING, `\[noconfig\] no usable API addresses for controller "noconfig", keeping cached endpoints .*`,
+	}})
+}
+
+func (s *NewAPIClientSuite) TestConnectionSourceStore(c *gc.C) {
+	apiOpen := func(apiInfo *api.Info, opts api.DialOpts) (api.Connection, error) {
+		return mockedAPIState(mockedHostPort | mockedModelTag), nil
+	}
+	store := newClientStore(c, "noconfig")
+	accountDetails, err := store.AccountDetails("noconfig")
+	c.Assert(err, jc.ErrorIsNil)
+	var source juju.ConnectionSource
+	_, err = juju.NewAPIConnection(juju.NewAPIConnectionParams{
+		Store:            store,
+		ControllerName:   "noconfig",
+		DialOpts:         api.DefaultDialOpts(),
+		OpenAPI:          apiOpen,
+		AccountDetails:   accountDetails,
+		ConnectionSource: &source,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(source, gc.Equals, juju.ConnectionSourceStore)
+}
+
+func (s *NewAPIClientSuite) TestConnectedHostPort(c *gc.C) {
+	var dialed string
+	apiOpen := func(apiInfo *api.Info, opts api.DialOpts) (api.Connection, error) {
+		st := mockedAPIState(mockedHostPort | mockedModelTag)
+		dialed = apiInfo.Addrs[0]
+		st.addr = dialed
+		return st, nil
+	}
+	store := newClientStore(c, "noconfig")
+	st, err := newAPIConnectionFromNames(c, "noconfig", "", store, apiOpen)
+	c.Assert(err, jc.ErrorIsNil)
+
+	hp, err := juju.ConnectedHostPort(st)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(network.DialAddress(hp), gc.Equals, dialed)
+}
+
+func (s *NewAPIClientSuite) TestConnectedHostPortNotConnected(c *gc.C) {
+	_, err := juju.ConnectedHostPort(mockedAPIState(noFlags))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *NewAPIClientSuite) TestWithInfoNoAddresses(c *gc.C) {
 	store := newClientStore(c, "noconfig")
 	err := store.UpdateController("noconfig", jujuclient.ControllerDetails{
@@ -187,6 +294,75 @@ func (s *NewAPIClientSuite) TestWithMacaroons(c *gc.C) {
 	c.Assert(info.Macaroons, gc.DeepEquals, []macaroon.Slice{{mac}})
 }
 
+func (s *NewAPIClientSuite) TestWithClientCert(c *gc.C) {
+	store := newClientStore(c, "withcert")
+	mac, err := apitesting.NewMacaroon("id")
+	c.Assert(err, jc.ErrorIsNil)
+	err = store.UpdateAccount("withcert", jujuclient.AccountDetails{
+		User:      "admin",
+		Macaroons: []macaroon.Slice{{mac}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	ad, err := store.AccountDetails("withcert")
+	c.Assert(err, jc.ErrorIsNil)
+	info, _, err := juju.ConnectionInfo(juju.NewAPIConnectionParams{
+		ControllerName: "withcert",
+		Store:          store,
+		AccountDetails: ad,
+		ClientCert:     coretesting.ServerCert,
+		ClientKey:      coretesting.ServerKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.ClientCert, gc.Equals, coretesting.ServerCert)
+	c.Assert(info.ClientKey, gc.Equals, coretesting.ServerKey)
+	c.Assert(info.CACert, gc.Equals, "certificate")
+	// The certificate is only presented at the TLS layer, so the
+	// account's macaroons are still used to log in.
+	c.Assert(info.Tag, gc.Equals, names.NewUserTag("admin"))
+	c.Assert(info.Macaroons, gc.DeepEquals, []macaroon.Slice{{mac}})
+}
+
+func (s *NewAPIClientSuite) TestPreferredAddress(c *gc.C) {
+	store := jujuclient.NewMemStore()
+	err := store.AddController("foo", jujuclient.ControllerDetails{
+		ControllerUUID: fakeUUID,
+		APIEndpoints:   []string{"0.1.1.1:1111", "0.2.2.2:2222"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for i, test := range []struct {
+		preferred     string
+		onlyPreferred bool
+		expect        []string
+	}{{
+		expect: []string{"0.1.1.1:1111", "0.2.2.2:2222"},
+	}, {
+		preferred: "0.2.2.2:2222",
+		expect:    []string{"0.2.2.2:2222", "0.1.1.1:1111"},
+	}, {
+		preferred: "0.3.3.3:3333",
+		expect:    []string{"0.3.3.3:3333", "0.1.1.1:1111", "0.2.2.2:2222"},
+	}, {
+		preferred:     "0.2.2.2:2222",
+		onlyPreferred: true,
+		expect:        []string{"0.2.2.2:2222"},
+	}} {
+		c.Logf("test %d: %q only %v", i, test.preferred, test.onlyPreferred)
+		info, _, err := juju.ConnectionInfo(juju.NewAPIConnectionParams{
+			ControllerName:       "foo",
+			Store:                store,
+			PreferredAddress:     test.preferred,
+			OnlyPreferredAddress: test.onlyPreferred,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(info.Addrs, jc.DeepEquals, test.expect)
+	}
+	// The cached endpoints are left alone.
+	details, err := store.ControllerByName("foo")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(details.APIEndpoints, jc.DeepEquals, []string{"0.1.1.1:1111", "0.2.2.2:2222"})
+}
+
 func (s *NewAPIClientSuite) TestWithRedirect(c *gc.C) {
 	store := newClientStore(c, "ctl")
 	err := store.UpdateController("ctl", jujuclient.ControllerDetails{
@@ -243,6 +419,41 @@ func (s *NewAPIClientSuite) TestWithRedirect(c *gc.C) {
 	c.Assert(controllerBefore, gc.DeepEquals, controllerAfter)
 }
 
+func (s *NewAPIClientSuite) TestConnectionSourceRedirect(c *gc.C) {
+	store := newClientStore(c, "ctl")
+	err := store.UpdateController("ctl", jujuclient.ControllerDetails{
+		ControllerUUID: fakeUUID,
+		CACert:         "certificate",
+		APIEndpoints:   []string{"0.1.2.3:5678"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	redirected := false
+	redirOpen := func(apiInfo *api.Info, opts api.DialOpts) (api.Connection, error) {
+		if !redirected {
+			redirected = true
+			return nil, errors.Trace(&api.RedirectError{
+				Servers: []network.MachineHostPorts{{
+					network.MachineHostPort{MachineAddress: network.NewMachineAddress("0.0.9.9"), NetPort: network.NetPort(1234)},
+				}},
+				CACert:         "alternative CA cert",
+				FollowRedirect: true,
+			})
+		}
+		return mockedAPIState(noFlags), nil
+	}
+	var source juju.ConnectionSource
+	_, err = juju.NewAPIConnection(juju.NewAPIConnectionParams{
+		Store:            store,
+		ControllerName:   "ctl",
+		DialOpts:         api.DefaultDialOpts(),
+		OpenAPI:          redirOpen,
+		ConnectionSource: &source,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(source, gc.Equals, juju.ConnectionSourceRedirect)
+}
+
 func (s *NewAPIClientSuite) TestWithInfoAPIOpenError(c *gc.C) {
 	jujuClient := newClientStore(c, "noconfig")
 
@@ -450,6 +661,103 @@ func (s *NewAPIClientSuite) TestEndpointFiltering(c *gc.C) {
 	})
 }
 
+func (s *NewAPIClientSuite) TestNewAPIConnectionFromInfo(c *gc.C) {
+	conn, err := juju.NewAPIConnectionFromInfo(&api.Info{
+		Addrs: []string{"example1:1111"},
+	}, api.DialOpts{
+		DialWebsocket: func(ctx context.Context, urlStr string, tlsConfig *tls.Config, ipAddr string) (jsoncodec.JSONConn, error) {
+			c.Check(ipAddr, gc.Equals, "0.1.1.1:1111")
+			return jsoncodec.NetJSONConn(apitesting.FakeAPIServer(testRootAPI{})), nil
+		},
+		IPAddrResolver: apitesting.IPAddrResolverMap{
+			"example1": {"0.1.1.1"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+	c.Assert(conn.Addr(), gc.Equals, "example1:1111")
+}
+
+func (s *NewAPIClientSuite) TestNewAPIConnectionFromInfoNoAddresses(c *gc.C) {
+	_, err := juju.NewAPIConnectionFromInfo(&api.Info{}, api.DialOpts{})
+	c.Assert(err, jc.Satisfies, juju.IsNoAddressesError)
+}
+
+// connectStickyEndpoint connects to a controller whose cached first
+// endpoint can't be dialed and which reports the given addresses, and
+// returns the API endpoints cached afterwards.
+func connectStickyEndpoint(c *gc.C, serverHostPorts network.ProviderHostPorts, sticky bool) []string {
+	store := jujuclient.NewMemStore()
+	err := store.AddController("foo", jujuclient.ControllerDetails{
+		ControllerUUID: fakeUUID,
+		APIEndpoints: []string{
+			"example1:1111",
+			"example3:3333",
+		},
+		DNSCache: map[string][]string{
+			"example1": {"0.1.1.1"},
+			"example3": {"0.3.3.3"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	conn, err := juju.NewAPIConnection(juju.NewAPIConnectionParams{
+		Store:          store,
+		ControllerName: "foo",
+		DialOpts: api.DialOpts{
+			DialWebsocket: func(ctx context.Context, urlStr string, tlsConfig *tls.Config, ipAddr string) (jsoncodec.JSONConn, error) {
+				if ipAddr != "0.3.3.3:3333" {
+					return nil, errors.New("fail")
+				}
+				apiConn := testRootAPI{
+					serverAddrs: params.FromProviderHostsPorts([]network.ProviderHostPorts{serverHostPorts}),
+				}
+				return jsoncodec.NetJSONConn(apitesting.FakeAPIServer(apiConn)), nil
+			},
+			IPAddrResolver: ipAddrResolverFunc(func(ctx context.Context, host string) ([]net.IPAddr, error) {
+				return nil, errors.New("no DNS available")
+			}),
+		},
+		AccountDetails: new(jujuclient.AccountDetails),
+		StickyEndpoint: sticky,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+	details, err := store.ControllerByName("foo")
+	c.Assert(err, jc.ErrorIsNil)
+	return details.APIEndpoints
+}
+
+func (s *NewAPIClientSuite) TestStickyEndpoint(c *gc.C) {
+	serverHostPorts := network.ProviderHostPorts{
+		network.ProviderHostPort{ProviderAddress: network.NewProviderAddress("example3"), NetPort: 3333},
+		network.ProviderHostPort{ProviderAddress: network.NewProviderAddress("example1"), NetPort: 1111},
+	}
+	// By default the address connected to is cached first.
+	endpoints := connectStickyEndpoint(c, serverHostPorts, false)
+	c.Assert(endpoints, jc.DeepEquals, []string{
+		"example3:3333",
+		"example1:1111",
+	})
+	// The sticky endpoint stays first while the controller reports it.
+	endpoints = connectStickyEndpoint(c, serverHostPorts, true)
+	c.Assert(endpoints, jc.DeepEquals, []string{
+		"example1:1111",
+		"example3:3333",
+	})
+}
+
+func (s *NewAPIClientSuite) TestStickyEndpointRemoved(c *gc.C) {
+	endpoints := connectStickyEndpoint(c, network.ProviderHostPorts{
+		network.ProviderHostPort{ProviderAddress: network.NewProviderAddress("example3"), NetPort: 3333},
+		network.ProviderHostPort{ProviderAddress: network.NewProviderAddress("example4"), NetPort: 4444},
+	}, true)
+	c.Assert(endpoints, jc.DeepEquals, []string{
+		"example3:3333",
+		"example4:4444",
+	})
+}
+
 var moveToFrontTests = []struct {
 	item   string
 	items  []string
@@ -0,0 +1,100 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package juju
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/core/network"
+	"github.com/juju/juju/jujuclient"
+)
+
+// ConnectionProfileVersion is the version of the connection profile
+// format. It is incremented whenever the format changes incompatibly.
+const ConnectionProfileVersion = 1
+
+// ConnectionProfile holds the details needed to connect to a controller
+// in a form suitable for storing outside of the Juju client store.
+type ConnectionProfile struct {
+	// Version is the version of the profile format.
+	Version int `yaml:"version" json:"version"`
+
+	// ControllerUUID is the unique ID of the controller.
+	ControllerUUID string `yaml:"controller-uuid" json:"controller-uuid"`
+
+	// APIEndpoints holds the controller's API addresses.
+	APIEndpoints []string `yaml:"api-endpoints,flow" json:"api-endpoints"`
+
+	// PublicDNSName is the public host name of the controller, if any.
+	PublicDNSName string `yaml:"public-hostname,omitempty" json:"public-hostname,omitempty"`
+
+	// CACert is the controller's CA certificate.
+	CACert string `yaml:"ca-cert" json:"ca-cert"`
+
+	// AgentVersion is the version of the controller's agents.
+	AgentVersion string `yaml:"agent-version,omitempty" json:"agent-version,omitempty"`
+
+	// User is the name of the user to log in as.
+	User string `yaml:"user,omitempty" json:"user,omitempty"`
+
+	// Password is the user's password. It is only included when
+	// credentials are explicitly requested.
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+}
+
+// ConnectionProfileParams holds the parameters for NewConnectionProfile.
+type ConnectionProfileParams struct {
+	// Controller holds the controller details from the client store.
+	Controller jujuclient.ControllerDetails
+
+	// Conn is an optional live connection to the controller. If set, its
+	// addresses and server version are used in preference to the ones
+	// cached in Controller.
+	Conn api.Connection
+
+	// Account optionally holds the account used to log in to the
+	// controller.
+	Account *jujuclient.AccountDetails
+
+	// IncludeCredentials causes the account's password to be included
+	// in the profile. By default only the user name is included.
+	IncludeCredentials bool
+}
+
+// NewConnectionProfile returns a connection profile for the controller.
+func NewConnectionProfile(args ConnectionProfileParams) (*ConnectionProfile, error) {
+	if args.Controller.ControllerUUID == "" {
+		return nil, errors.NotValidf("missing controller UUID")
+	}
+	profile := &ConnectionProfile{
+		Version:        ConnectionProfileVersion,
+		ControllerUUID: args.Controller.ControllerUUID,
+		APIEndpoints:   args.Controller.APIEndpoints,
+		PublicDNSName:  args.Controller.PublicDNSName,
+		CACert:         args.Controller.CACert,
+		AgentVersion:   args.Controller.AgentVersion,
+	}
+	if args.Conn != nil {
+		if hps := usableHostPorts(args.Conn.APIHostPorts()); len(hps) > 0 {
+			profile.APIEndpoints = make([]string, len(hps))
+			for i, hp := range hps {
+				profile.APIEndpoints[i] = network.DialAddress(hp)
+			}
+		}
+		if dnsName := args.Conn.PublicDNSName(); dnsName != "" {
+			profile.PublicDNSName = dnsName
+		}
+		if v, ok := args.Conn.ServerVersion(); ok {
+			profile.AgentVersion = v.String()
+		}
+	}
+	if args.Account != nil {
+		profile.User = args.Account.User
+		if args.IncludeCredentials {
+			profile.Password = args.Account.Password
+		}
+	}
+	return profile, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package juju_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/jujuclient"
+	"github.com/juju/juju/testing"
+)
+
+type connectionProfileSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&connectionProfileSuite{})
+
+func (s *connectionProfileSuite) controllerDetails() jujuclient.ControllerDetails {
+	return jujuclient.ControllerDetails{
+		ControllerUUID: fakeUUID,
+		APIEndpoints:   []string{"10.0.0.1:17070"},
+		CACert:         "certificate",
+		AgentVersion:   "2.9.0",
+		Cloud:          "aws",
+	}
+}
+
+func (s *connectionProfileSuite) TestNewConnectionProfile(c *gc.C) {
+	profile, err := juju.NewConnectionProfile(juju.ConnectionProfileParams{
+		Controller: s.controllerDetails(),
+		Account: &jujuclient.AccountDetails{
+			User:     "admin",
+			Password: "hunter2",
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(profile, jc.DeepEquals, &juju.ConnectionProfile{
+		Version:        juju.ConnectionProfileVersion,
+		ControllerUUID: fakeUUID,
+		APIEndpoints:   []string{"10.0.0.1:17070"},
+		CACert:         "certificate",
+		AgentVersion:   "2.9.0",
+		User:           "admin",
+	})
+
+	data, err := yaml.Marshal(profile)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Not(jc.Contains), "hunter2")
+}
+
+func (s *connectionProfileSuite) TestNewConnectionProfileIncludeCredentials(c *gc.C) {
+	profile, err := juju.NewConnectionProfile(juju.ConnectionProfileParams{
+		Controller: s.controllerDetails(),
+		Account: &jujuclient.AccountDetails{
+			User:     "admin",
+			Password: "hunter2",
+		},
+		IncludeCredentials: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(profile.User, gc.Equals, "admin")
+	c.Assert(profile.Password, gc.Equals, "hunter2")
+}
+
+func (s *connectionProfileSuite) TestNewConnectionProfileFromConnection(c *gc.C) {
+	profile, err := juju.NewConnectionProfile(juju.ConnectionProfileParams{
+		Controller: s.controllerDetails(),
+		Conn:       mockedAPIState(mockedHostPort),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(profile.APIEndpoints, jc.DeepEquals, []string{"0.1.2.3:1234", "[2001:db8::1]:1234"})
+	c.Assert(profile.AgentVersion, gc.Equals, "1.2.3")
+	c.Assert(profile.CACert, gc.Equals, "certificate")
+	c.Assert(profile.User, gc.Equals, "")
+}
+
+func (s *connectionProfileSuite) TestNewConnectionProfileMissingUUID(c *gc.C) {
+	_, err := juju.NewConnectionProfile(juju.ConnectionProfileParams{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, "missing controller UUID not valid")
+}
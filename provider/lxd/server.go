@@ -37,7 +37,8 @@ type Server interface {
 	GetServer() (server *lxdapi.Server, ETag string, err error)
 	ServerVersion() string
 	GetConnectionInfo() (info *lxdclient.ConnectionInfo, err error)
-	UpdateServerConfig(map[string]string) error
+	GetServerConfig() (map[string]interface{}, string, error)
+	UpdateServerConfig(map[string]string, string) error
 	UpdateContainerConfig(string, map[string]string) error
 	CreateCertificate(lxdapi.CertificatesPost) error
 	GetCertificate(fingerprint string) (certificate *lxdapi.Certificate, ETag string, err error)
@@ -458,6 +458,22 @@ func (mr *MockServerMockRecorder) GetServer() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServer", reflect.TypeOf((*MockServer)(nil).GetServer))
 }
 
+// GetServerConfig mocks base method
+func (m *MockServer) GetServerConfig() (map[string]interface{}, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerConfig")
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetServerConfig indicates an expected call of GetServerConfig
+func (mr *MockServerMockRecorder) GetServerConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerConfig", reflect.TypeOf((*MockServer)(nil).GetServerConfig))
+}
+
 // GetStoragePool mocks base method
 func (m *MockServer) GetStoragePool(arg0 string) (*api.StoragePool, string, error) {
 	m.ctrl.T.Helper()
@@ -718,17 +734,17 @@ func (mr *MockServerMockRecorder) UpdateContainerProfiles(arg0, arg1 interface{}
 }
 
 // UpdateServerConfig mocks base method
-func (m *MockServer) UpdateServerConfig(arg0 map[string]string) error {
+func (m *MockServer) UpdateServerConfig(arg0 map[string]string, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateServerConfig", arg0)
+	ret := m.ctrl.Call(m, "UpdateServerConfig", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateServerConfig indicates an expected call of UpdateServerConfig
-func (mr *MockServerMockRecorder) UpdateServerConfig(arg0 interface{}) *gomock.Call {
+func (mr *MockServerMockRecorder) UpdateServerConfig(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateServerConfig", reflect.TypeOf((*MockServer)(nil).UpdateServerConfig), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateServerConfig", reflect.TypeOf((*MockServer)(nil).UpdateServerConfig), arg0, arg1)
 }
 
 // UpdateStoragePoolVolume mocks base method
@@ -471,13 +471,16 @@ func (s *lxdFilesystemSource) attachFilesystem(
 		return nil, errors.Trace(err)
 	}
 
+	// Attaching is a no-op if the filesystem is already attached as
+	// requested, so that attachments can be retried.
 	deviceName := arg.Filesystem.String()
-	if err = inst.container.AddDisk(deviceName, arg.Path, volumeName, poolName, arg.ReadOnly); err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	if err := s.env.server().WriteContainer(inst.container); err != nil {
-		return nil, errors.Trace(err)
+	if !inst.container.HasDisk(deviceName, arg.Path, volumeName, poolName, arg.ReadOnly) {
+		if err = inst.container.AddDisk(deviceName, arg.Path, volumeName, poolName, arg.ReadOnly); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := s.env.server().WriteContainer(inst.container); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 
 	filesystemAttachment := storage.FilesystemAttachment{
@@ -539,6 +542,10 @@ func (s *lxdFilesystemSource) detachFilesystem(
 	inst *environInstance,
 ) error {
 	deviceName := arg.Filesystem.String()
+	if !inst.container.HasDevice(deviceName) {
+		// Already detached.
+		return nil
+	}
 	delete(inst.container.Devices, deviceName)
 	return errors.Trace(s.env.server().WriteContainer(inst.container))
 }
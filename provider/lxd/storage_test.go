@@ -377,6 +377,50 @@ func (s *storageSuite) TestAttachFilesystems(c *gc.C) {
 	}})
 }
 
+func (s *storageSuite) TestAttachFilesystemsAlreadyAttached(c *gc.C) {
+	container := s.NewContainer(c, "inst-0")
+	container.Devices = map[string]map[string]string{
+		"filesystem-0": {
+			"type":     "disk",
+			"source":   "filesystem-0",
+			"pool":     "pool",
+			"path":     "/mnt/path",
+			"readonly": "true",
+		},
+	}
+	s.Client.Containers = []containerlxd.Container{*container}
+
+	source := s.filesystemSource(c, "pool")
+	results, err := source.AttachFilesystems(s.callCtx, []storage.FilesystemAttachmentParams{{
+		AttachmentParams: storage.AttachmentParams{
+			Provider:   "lxd",
+			Machine:    names.NewMachineTag("123"),
+			InstanceId: "inst-0",
+			ReadOnly:   true,
+		},
+		Filesystem:   names.NewFilesystemTag("0"),
+		FilesystemId: "pool:filesystem-0",
+		Path:         "/mnt/path",
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Error, jc.ErrorIsNil)
+	c.Assert(results[0].FilesystemAttachment, jc.DeepEquals, &storage.FilesystemAttachment{
+		names.NewFilesystemTag("0"),
+		names.NewMachineTag("123"),
+		storage.FilesystemAttachmentInfo{
+			Path:     "/mnt/path",
+			ReadOnly: true,
+		},
+	})
+
+	// The filesystem is already attached, so the container isn't written.
+	s.Stub.CheckCalls(c, []testing.StubCall{{
+		"AliveContainers",
+		[]interface{}{"juju-f75cba-"},
+	}})
+}
+
 func (s *storageSuite) TestAttachFilesystemsInvalidCredentialsInstanceError(c *gc.C) {
 	c.Assert(s.invalidCredential, jc.IsFalse)
 	s.Client.Stub.SetErrors(errTestUnAuth)
@@ -492,15 +536,13 @@ func (s *storageSuite) TestDetachFilesystems(c *gc.C) {
 
 	// TODO (manadart 2018-06-25) We need to check the container config to
 	// ensure it represents the removed device.
+	// Filesystem 1 isn't attached, so the container is only written once.
 	s.Stub.CheckCalls(c, []testing.StubCall{{
 		"AliveContainers",
 		[]interface{}{"juju-f75cba-"},
 	}, {
 		"WriteContainer",
 		[]interface{}{&s.Client.Containers[0]},
-	}, {
-		"WriteContainer",
-		[]interface{}{&s.Client.Containers[0]},
 	}})
 }
 
@@ -514,8 +514,16 @@ func (conn *StubClient) GetConnectionInfo() (info *lxdclient.ConnectionInfo, err
 	}, conn.NextErr()
 }
 
-func (conn *StubClient) UpdateServerConfig(cfg map[string]string) error {
-	conn.AddCall("UpdateServerConfig", cfg)
+func (conn *StubClient) GetServerConfig() (map[string]interface{}, string, error) {
+	conn.AddCall("GetServerConfig")
+	if err := conn.NextErr(); err != nil {
+		return nil, "", err
+	}
+	return map[string]interface{}{}, "etag", nil
+}
+
+func (conn *StubClient) UpdateServerConfig(cfg map[string]string, eTag string) error {
+	conn.AddCall("UpdateServerConfig", cfg, eTag)
 	return conn.NextErr()
 }
 
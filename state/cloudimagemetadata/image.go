@@ -5,6 +5,7 @@ package cloudimagemetadata
 
 import (
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/juju/collections/set"
@@ -23,8 +24,36 @@ var logger = loggo.GetLogger("juju.state.cloudimagemetadata")
 type storage struct {
 	collection string
 	store      DataStore
+	config     StorageConfig
 }
 
+// StorageConfig holds the optional settings for a Storage. The zero value
+// requires all saved metadata to have a series.
+type StorageConfig struct {
+	// DefaultSeries is used for metadata which is saved without a series.
+	DefaultSeries string
+
+	// SeriesOptionalSources are the sources whose metadata may be saved
+	// without a series (and so without a version) when there is no
+	// default series.
+	SeriesOptionalSources []string
+
+	// ImageIdPatterns holds, keyed by source, the patterns that image
+	// ids of the source's metadata must match. Sources without a pattern
+	// accept any image id.
+	ImageIdPatterns map[string]*regexp.Regexp
+
+	// RejectSuperseded, if true, stops saved metadata from replacing the
+	// image of existing metadata with the same attributes that was created
+	// later, so that an out of order import can't reinstate a stale image.
+	// Such saves fail with ErrSuperseded.
+	RejectSuperseded bool
+}
+
+// ErrSuperseded is returned (as the cause of the error) when saved
+// metadata is older than the existing metadata it would replace.
+var ErrSuperseded = errors.New("metadata superseded by newer metadata")
+
 var _ Storage = (*storage)(nil)
 
 // expiryTime is the time after which non-custom image metadata
@@ -44,7 +73,17 @@ func MongoIndexes() []mgo.Index {
 // NewStorage constructs a new Storage that stores image metadata
 // in the provided data store.
 func NewStorage(collectionName string, store DataStore) Storage {
-	return &storage{collectionName, store}
+	return NewStorageWithConfig(collectionName, store, StorageConfig{})
+}
+
+// NewStorageWithConfig constructs a new Storage that stores image metadata
+// in the provided data store, validating saved metadata as configured.
+func NewStorageWithConfig(collectionName string, store DataStore, config StorageConfig) Storage {
+	return &storage{
+		collection: collectionName,
+		store:      store,
+		config:     config,
+	}
 }
 
 var emptyMetadata = Metadata{}
@@ -52,23 +91,41 @@ var emptyMetadata = Metadata{}
 // SaveMetadata implements Storage.SaveMetadata and behaves as save-or-update.
 // Non custom records will expire after a set time.
 func (s *storage) SaveMetadata(metadata []Metadata) error {
+	_, err := s.saveMetadata(metadata)
+	return err
+}
+
+// SaveCanonicalMetadata implements Storage.SaveCanonicalMetadata.
+func (s *storage) SaveCanonicalMetadata(metadata []Metadata) ([]Metadata, error) {
+	return s.saveMetadata(metadata)
+}
+
+// saveMetadata saves the given metadata and returns it as it is stored.
+// Metadata which was already known keeps the creation date it was first
+// saved with, unless that is replaced.
+func (s *storage) saveMetadata(metadata []Metadata) ([]Metadata, error) {
 	if len(metadata) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	newDocs := make([]imagesMetadataDoc, len(metadata))
 	for i, m := range metadata {
+		if m.Series == "" {
+			m.Series = s.config.DefaultSeries
+		}
 		newDoc := s.mongoDoc(m)
-		if err := validateMetadata(&newDoc); err != nil {
-			return err
+		if err := s.validateMetadata(&newDoc); err != nil {
+			return nil, err
 		}
 		newDocs[i] = newDoc
 	}
 
+	var saved []Metadata
 	buildTxn := func(attempt int) ([]txn.Op, error) {
 		seen := set.NewStrings()
+		saved = make([]Metadata, len(newDocs))
 		var ops []txn.Op
-		for _, newDoc := range newDocs {
+		for i, newDoc := range newDocs {
 			newDocCopy := newDoc
 			if seen.Contains(newDocCopy.Id) {
 				return nil, errors.Errorf(
@@ -86,6 +143,7 @@ func (s *storage) SaveMetadata(metadata []Metadata) error {
 				op.Assert = txn.DocMissing
 				op.Insert = &newDocCopy
 				ops = append(ops, op)
+				saved[i] = newDocCopy.metadata()
 				logger.Debugf("inserting cloud image metadata for %v", newDocCopy.Id)
 			} else if err != nil {
 				return nil, errors.Trace(err)
@@ -93,9 +151,28 @@ func (s *storage) SaveMetadata(metadata []Metadata) error {
 				// need to update imageId
 				op.Assert = txn.DocExists
 				op.Update = bson.D{{"$set", bson.D{{"image_id", newDocCopy.ImageId}}}}
+				existing.ImageId = newDocCopy.ImageId
+				if s.config.RejectSuperseded {
+					if existing.DateCreated > newDocCopy.DateCreated {
+						return nil, errors.Annotatef(ErrSuperseded, "image %v for %v", newDocCopy.ImageId, newDocCopy.Id)
+					}
+					// Record when the replacement was created, so that
+					// it isn't itself replaced by older metadata.
+					op.Assert = bson.D{{"date_created", existing.DateCreated}}
+					op.Update = bson.D{{"$set", bson.D{
+						{"image_id", newDocCopy.ImageId},
+						{"date_created", newDocCopy.DateCreated},
+					}}}
+					existing.DateCreated = newDocCopy.DateCreated
+				}
 				ops = append(ops, op)
 				logger.Debugf("updating cloud image id for metadata %v", newDocCopy.Id)
 			}
+			if op.Insert == nil {
+				// The metadata is already known, and keeps its
+				// creation date unless it was replaced above.
+				saved[i] = existing
+			}
 			seen.Add(newDocCopy.Id)
 		}
 		if len(ops) == 0 {
@@ -106,9 +183,9 @@ func (s *storage) SaveMetadata(metadata []Metadata) error {
 
 	err := s.store.RunTransaction(buildTxn)
 	if err != nil {
-		return errors.Annotate(err, "cannot save cloud image metadata")
+		return nil, errors.Annotate(err, "cannot save cloud image metadata")
 	}
-	return nil
+	return saved, nil
 }
 
 // DeleteMetadata implements Storage.DeleteMetadata.
@@ -155,6 +232,51 @@ func (s *storage) DeleteMetadata(imageId string) error {
 	return nil
 }
 
+// DeleteMatchingMetadata implements Storage.DeleteMatchingMetadata.
+func (s *storage) DeleteMatchingMetadata(criteria MetadataFilter) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		docs, err := s.matchingMetadata(criteria)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(docs) == 0 {
+			if attempt == 0 {
+				return nil, errors.NotFoundf("matching cloud image metadata")
+			}
+			// The metadata has been deleted concurrently.
+			return nil, jujutxn.ErrNoOperations
+		}
+		ops := make([]txn.Op, len(docs))
+		for i, doc := range docs {
+			logger.Debugf("deleting metadata (ID=%v) for image (ID=%v)", doc.Id, doc.ImageId)
+			ops[i] = txn.Op{
+				C:      s.collection,
+				Id:     doc.Id,
+				Assert: txn.DocExists,
+				Remove: true,
+			}
+		}
+		return ops, nil
+	}
+
+	err := s.store.RunTransaction(buildTxn)
+	if err != nil {
+		return errors.Annotate(err, "cannot delete matching cloud image metadata")
+	}
+	return nil
+}
+
+func (s *storage) matchingMetadata(criteria MetadataFilter) ([]imagesMetadataDoc, error) {
+	coll, closer := s.store.GetCollection(s.collection)
+	defer closer()
+
+	var docs []imagesMetadataDoc
+	if err := coll.Find(buildSearchClauses(criteria)).All(&docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
 func (s *storage) metadataForImageId(imageId string) ([]imagesMetadataDoc, error) {
 	coll, closer := s.store.GetCollection(s.collection)
 	defer closer()
@@ -313,16 +435,19 @@ func buildKey(m Metadata) string {
 		m.Source)
 }
 
-func validateMetadata(m *imagesMetadataDoc) error {
-	// series must be supplied.
+func (s *storage) validateMetadata(m *imagesMetadataDoc) error {
+	// series must be supplied, unless the source doesn't require it.
 	if m.Series == "" {
-		return errors.NotValidf("missing series: metadata for image %v", m.ImageId)
-	}
-	v, err := series.SeriesVersion(m.Series)
-	if err != nil {
-		return err
+		if !set.NewStrings(s.config.SeriesOptionalSources...).Contains(m.Source) {
+			return errors.NotValidf("missing series: metadata for image %v", m.ImageId)
+		}
+	} else {
+		v, err := series.SeriesVersion(m.Series)
+		if err != nil {
+			return err
+		}
+		m.Version = v
 	}
-	m.Version = v
 
 	if m.Stream == "" {
 		return errors.NotValidf("missing stream: metadata for image %v", m.ImageId)
@@ -336,19 +461,29 @@ func validateMetadata(m *imagesMetadataDoc) error {
 	if m.Region == "" {
 		return errors.NotValidf("missing region: metadata for image %v", m.ImageId)
 	}
+	if pattern, ok := s.config.ImageIdPatterns[m.Source]; ok && !pattern.MatchString(m.ImageId) {
+		return errors.NotValidf("image id %q for source %q (expected to match %q)", m.ImageId, m.Source, pattern)
+	}
 	return nil
 }
 
 // FindMetadata implements Storage.FindMetadata.
-// Results are sorted by date created and grouped by source.
+// Results are grouped by source and sorted by descending priority, then
+// by the criteria's order or, if none is given, by date created.
+// If the criteria prefer a source which has matching metadata, only
+// that source's metadata is returned.
 func (s *storage) FindMetadata(criteria MetadataFilter) (map[string][]Metadata, error) {
 	coll, closer := s.store.GetCollection(s.collection)
 	defer closer()
 
 	logger.Debugf("searching for image metadata %#v", criteria)
 	searchCriteria := buildSearchClauses(criteria)
+	sortFields, err := buildSortFields(criteria)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	var docs []imagesMetadataDoc
-	if err := coll.Find(searchCriteria).Sort("date_created").All(&docs); err != nil {
+	if err := coll.Find(searchCriteria).Sort(sortFields...).All(&docs); err != nil {
 		return nil, errors.Trace(err)
 	}
 	if len(docs) == 0 {
@@ -360,14 +495,25 @@ func (s *storage) FindMetadata(criteria MetadataFilter) (map[string][]Metadata,
 		one := doc.metadata()
 		metadata[one.Source] = append(metadata[one.Source], one)
 	}
+	if preferred, ok := metadata[criteria.PreferSource]; ok {
+		return map[string][]Metadata{criteria.PreferSource: preferred}, nil
+	}
 	return metadata, nil
 }
 
 func buildSearchClauses(criteria MetadataFilter) bson.D {
 	all := bson.D{}
 
+	streams := criteria.Streams
 	if criteria.Stream != "" {
-		all = append(all, bson.DocElem{"stream", criteria.Stream})
+		streams = append([]string{criteria.Stream}, streams...)
+	}
+	switch len(streams) {
+	case 0:
+	case 1:
+		all = append(all, bson.DocElem{"stream", streams[0]})
+	default:
+		all = append(all, bson.DocElem{"stream", bson.D{{"$in", streams}}})
 	}
 
 	if criteria.Region != "" {
@@ -390,12 +536,59 @@ func buildSearchClauses(criteria MetadataFilter) bson.D {
 		all = append(all, bson.DocElem{"root_storage_type", criteria.RootStorageType})
 	}
 
+	if criteria.MinPriority > 0 {
+		all = append(all, bson.DocElem{"priority", bson.D{{"$gte", criteria.MinPriority}}})
+	}
+
 	if len(all.Map()) == 0 {
 		return nil
 	}
 	return all
 }
 
+// orderFields maps each metadata order to the field it sorts by.
+var orderFields = map[MetadataOrder]string{
+	"":                 "date_created",
+	OrderByDateCreated: "date_created",
+	OrderByVersion:     "version",
+	OrderByArch:        "arch",
+}
+
+// buildSortFields returns the fields by which the metadata matching the
+// criteria is sorted. Higher priority metadata always comes first, and
+// metadata that is equal in the requested order is ordered by the date it
+// was created.
+func buildSortFields(criteria MetadataFilter) ([]string, error) {
+	field, ok := orderFields[criteria.OrderBy]
+	if !ok {
+		return nil, errors.NotValidf("metadata order %q", criteria.OrderBy)
+	}
+	if criteria.Descending {
+		field = "-" + field
+	}
+	fields := []string{"-priority", field}
+	if field != "date_created" && field != "-date_created" {
+		fields = append(fields, "date_created")
+	}
+	return fields, nil
+}
+
+// MetadataOrder identifies the attribute by which found metadata is
+// ordered.
+type MetadataOrder string
+
+const (
+	// OrderByDateCreated orders metadata by the date it was created.
+	OrderByDateCreated MetadataOrder = "date-created"
+
+	// OrderByVersion orders metadata by its series version. Versions
+	// are compared as strings.
+	OrderByVersion MetadataOrder = "version"
+
+	// OrderByArch orders metadata by its architecture.
+	OrderByArch MetadataOrder = "arch"
+)
+
 // MetadataFilter contains all metadata attributes that alow to find a particular
 // cloud image metadata. Since size and source are not discriminating attributes
 // for cloud image metadata, they are not included in search criteria.
@@ -414,11 +607,45 @@ type MetadataFilter struct {
 	// simplestreams metadata supports.
 	Stream string `json:"stream,omitempty"`
 
+	// Streams stores further desired streams. Metadata from Stream or
+	// any of these streams is matched.
+	Streams []string `json:"streams,omitempty"`
+
 	// VirtType stores virtualisation type.
 	VirtType string `json:"virt_type,omitempty"`
 
 	// RootStorageType stores storage type.
 	RootStorageType string `json:"root-storage-type,omitempty"`
+
+	// PreferSource, if set, restricts the results to metadata from this
+	// source when it has any matching metadata. Otherwise metadata from
+	// all sources is returned.
+	PreferSource string `json:"prefer-source,omitempty"`
+
+	// MinPriority, if set, restricts the results to metadata with at
+	// least this priority.
+	MinPriority int `json:"min-priority,omitempty"`
+
+	// OrderBy, if set, is the attribute by which the metadata from each
+	// source is ordered after its priority, highest first. Otherwise it
+	// is ordered by the date it was created.
+	OrderBy MetadataOrder `json:"order-by,omitempty"`
+
+	// Descending, if true, reverses the order of the metadata from each
+	// source.
+	Descending bool `json:"descending,omitempty"`
+}
+
+// MetadataCount implements Storage.MetadataCount.
+func (s *storage) MetadataCount(criteria MetadataFilter) (int, error) {
+	coll, closer := s.store.GetCollection(s.collection)
+	defer closer()
+
+	count, err := coll.Find(buildSearchClauses(criteria)).Count()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return count, nil
 }
 
 // SupportedArchitectures implements Storage.SupportedArchitectures.
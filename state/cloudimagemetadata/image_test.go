@@ -4,6 +4,7 @@
 package cloudimagemetadata_test
 
 import (
+	"fmt"
 	"regexp"
 	"time"
 
@@ -196,6 +197,222 @@ func (s *cloudImageMetadataSuite) TestFindMetadata(c *gc.C) {
 	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{Region: "region"}, expected...)
 }
 
+func (s *cloudImageMetadataSuite) recordMetadataFromSources(c *gc.C) (custom, public cloudimagemetadata.Metadata) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Region:  "region",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "arch",
+		Source:  "custom",
+	}
+	custom = cloudimagemetadata.Metadata{attrs, 0, "1", 0}
+	attrs.Source = "public"
+	public = cloudimagemetadata.Metadata{attrs, 0, "2", 0}
+	s.assertRecordMetadata(c, custom)
+	s.assertRecordMetadata(c, public)
+	return custom, public
+}
+
+func assertMetadataMatches(c *gc.C, actual []cloudimagemetadata.Metadata, expected cloudimagemetadata.Metadata) {
+	c.Assert(actual, gc.HasLen, 1)
+	// The creation date is generated when the metadata is saved.
+	expected.DateCreated = actual[0].DateCreated
+	c.Assert(actual[0], jc.DeepEquals, expected)
+}
+
+func (s *cloudImageMetadataSuite) TestFindMetadataPreferSource(c *gc.C) {
+	_, public := s.recordMetadataFromSources(c)
+
+	metadata, err := s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{
+		Region:       "region",
+		PreferSource: "public",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadata, gc.HasLen, 1)
+	assertMetadataMatches(c, metadata["public"], public)
+}
+
+func (s *cloudImageMetadataSuite) TestFindMetadataPreferSourceFallback(c *gc.C) {
+	custom, public := s.recordMetadataFromSources(c)
+
+	metadata, err := s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{
+		Region:       "region",
+		PreferSource: "unknown",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadata, gc.HasLen, 2)
+	assertMetadataMatches(c, metadata["custom"], custom)
+	assertMetadataMatches(c, metadata["public"], public)
+}
+
+func (s *cloudImageMetadataSuite) TestFindMetadataOrderBy(c *gc.C) {
+	now := coretesting.NonZeroTime().UnixNano()
+	record := func(imageId, version, series, arch string, age int64) {
+		attrs := cloudimagemetadata.MetadataAttributes{
+			Stream:  "stream",
+			Region:  "region",
+			Version: version,
+			Series:  series,
+			Arch:    arch,
+			Source:  "test",
+		}
+		s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, imageId, now - age})
+	}
+	record("1", "16.04", "xenial", "amd64", 2)
+	record("2", "18.04", "bionic", "arm64", 1)
+	record("3", "14.04", "trusty", "s390x", 3)
+
+	for i, test := range []struct {
+		orderBy    cloudimagemetadata.MetadataOrder
+		descending bool
+		expect     []string
+	}{{
+		expect: []string{"3", "1", "2"},
+	}, {
+		orderBy:    cloudimagemetadata.OrderByDateCreated,
+		descending: true,
+		expect:     []string{"2", "1", "3"},
+	}, {
+		orderBy: cloudimagemetadata.OrderByVersion,
+		expect:  []string{"3", "1", "2"},
+	}, {
+		orderBy:    cloudimagemetadata.OrderByVersion,
+		descending: true,
+		expect:     []string{"2", "1", "3"},
+	}, {
+		orderBy: cloudimagemetadata.OrderByArch,
+		expect:  []string{"1", "2", "3"},
+	}, {
+		orderBy:    cloudimagemetadata.OrderByArch,
+		descending: true,
+		expect:     []string{"3", "2", "1"},
+	}} {
+		c.Logf("test %d: order by %q descending %v", i, test.orderBy, test.descending)
+		metadata, err := s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{
+			Region:     "region",
+			OrderBy:    test.orderBy,
+			Descending: test.descending,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		var imageIds []string
+		for _, m := range metadata["test"] {
+			imageIds = append(imageIds, m.ImageId)
+		}
+		c.Check(imageIds, jc.DeepEquals, test.expect)
+	}
+}
+
+func (s *cloudImageMetadataSuite) recordMetadataWithPriorities(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Region:  "region",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "amd64",
+		Source:  "test",
+	}
+	for i, priority := range []int{10, 50, 20} {
+		// Metadata with identical attributes is overwritten, so
+		// each image has a different virtualisation type.
+		attrs.VirtType = fmt.Sprintf("virt-type-%d", i)
+		s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, priority, fmt.Sprint(i + 1), 0})
+	}
+}
+
+func (s *cloudImageMetadataSuite) TestFindMetadataHighestPriorityFirst(c *gc.C) {
+	s.recordMetadataWithPriorities(c)
+
+	metadata, err := s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{Region: "region"})
+	c.Assert(err, jc.ErrorIsNil)
+	var imageIds []string
+	for _, m := range metadata["test"] {
+		imageIds = append(imageIds, m.ImageId)
+	}
+	c.Assert(imageIds, jc.DeepEquals, []string{"2", "3", "1"})
+}
+
+func (s *cloudImageMetadataSuite) TestFindMetadataMinPriority(c *gc.C) {
+	s.recordMetadataWithPriorities(c)
+
+	metadata, err := s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{
+		Region:      "region",
+		MinPriority: 20,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	var imageIds []string
+	for _, m := range metadata["test"] {
+		imageIds = append(imageIds, m.ImageId)
+	}
+	c.Assert(imageIds, jc.DeepEquals, []string{"2", "3"})
+
+	_, err = s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{
+		Region:      "region",
+		MinPriority: 60,
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *cloudImageMetadataSuite) TestFindMetadataStreams(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "released",
+		Region:  "region",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "arch",
+		Source:  "custom",
+	}
+	released := cloudimagemetadata.Metadata{attrs, 0, "1", 0}
+	attrs.Stream = "daily"
+	daily := cloudimagemetadata.Metadata{attrs, 0, "2", 0}
+	attrs.Source = "public"
+	publicDaily := cloudimagemetadata.Metadata{attrs, 0, "3", 0}
+	attrs.Stream = "proposed"
+	proposed := cloudimagemetadata.Metadata{attrs, 0, "4", 0}
+	s.assertRecordMetadata(c, released, daily, publicDaily, proposed)
+
+	metadata, err := s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{
+		Streams: []string{"released", "daily"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadata, gc.HasLen, 2)
+	c.Assert(metadata["custom"], gc.HasLen, 2)
+	assertMetadataMatches(c, metadata["public"], publicDaily)
+
+	// The single stream is matched as well as the list.
+	metadata, err = s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{
+		Stream:  "released",
+		Streams: []string{"proposed"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadata, gc.HasLen, 2)
+	assertMetadataMatches(c, metadata["custom"], released)
+	assertMetadataMatches(c, metadata["public"], proposed)
+}
+
+func (s *cloudImageMetadataSuite) TestFindMetadataOrderByInvalid(c *gc.C) {
+	_, err := s.storage.FindMetadata(cloudimagemetadata.MetadataFilter{
+		OrderBy: "size",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `metadata order "size" not valid`)
+}
+
+func (s *cloudImageMetadataSuite) TestMetadataCount(c *gc.C) {
+	count, err := s.storage.MetadataCount(cloudimagemetadata.MetadataFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 0)
+
+	s.recordMetadataFromSources(c)
+	count, err = s.storage.MetadataCount(cloudimagemetadata.MetadataFilter{Region: "region"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 2)
+
+	count, err = s.storage.MetadataCount(cloudimagemetadata.MetadataFilter{Region: "other-region"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 0)
+}
+
 func (s *cloudImageMetadataSuite) TestSaveMetadataUpdateSameAttrsAndImages(c *gc.C) {
 	attrs := cloudimagemetadata.MetadataAttributes{
 		Stream:  "stream",
@@ -232,6 +449,54 @@ func (s *cloudImageMetadataSuite) TestSaveMetadataUpdateSameAttrsDiffImages(c *g
 	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{}, metadata1)
 }
 
+func (s *cloudImageMetadataSuite) TestSaveMetadataRejectSuperseded(c *gc.C) {
+	s.storage = cloudimagemetadata.NewStorageWithConfig(collectionName, s.access, cloudimagemetadata.StorageConfig{
+		RejectSuperseded: true,
+	})
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "arch",
+		Source:  "test",
+		Region:  "wonder",
+	}
+	now := coretesting.NonZeroTime().UnixNano()
+	current := cloudimagemetadata.Metadata{attrs, 0, "2", now}
+	s.assertRecordMetadata(c, current)
+
+	stale := cloudimagemetadata.Metadata{attrs, 0, "1", now - 1}
+	err := s.storage.SaveMetadata([]cloudimagemetadata.Metadata{stale})
+	c.Assert(errors.Cause(err), gc.Equals, cloudimagemetadata.ErrSuperseded)
+	s.assertMetadataRecorded(c, attrs, current)
+
+	newer := cloudimagemetadata.Metadata{attrs, 0, "3", now + 1}
+	s.assertRecordMetadata(c, newer)
+	s.assertMetadataRecorded(c, attrs, newer)
+
+	// The replacement's creation date was recorded, so the previous
+	// image can't be reinstated either.
+	err = s.storage.SaveMetadata([]cloudimagemetadata.Metadata{current})
+	c.Assert(errors.Cause(err), gc.Equals, cloudimagemetadata.ErrSuperseded)
+	s.assertMetadataRecorded(c, attrs, newer)
+}
+
+func (s *cloudImageMetadataSuite) TestSaveMetadataReplacesSupersededByDefault(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "arch",
+		Source:  "test",
+		Region:  "wonder",
+	}
+	now := coretesting.NonZeroTime().UnixNano()
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "2", now})
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "1", now - 1})
+	// Only the image id is updated.
+	s.assertMetadataRecorded(c, attrs, cloudimagemetadata.Metadata{attrs, 0, "1", now})
+}
+
 func (s *cloudImageMetadataSuite) TestSaveMetadataDuplicates(c *gc.C) {
 	attrs := cloudimagemetadata.MetadataAttributes{
 		Stream:   "stream",
@@ -352,6 +617,170 @@ func (s *cloudImageMetadataSuite) TestSaveMetadataNoSeriesPassed(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, regexp.QuoteMeta(`missing series: metadata for image 1 not valid`))
 }
 
+func (s *cloudImageMetadataSuite) TestSaveMetadataNoSeriesDefaultSeries(c *gc.C) {
+	s.storage = cloudimagemetadata.NewStorageWithConfig(collectionName, s.access, cloudimagemetadata.StorageConfig{
+		DefaultSeries: "trusty",
+	})
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream: "stream",
+		Arch:   "arch",
+		Source: "test",
+		Region: "wonder",
+	}
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "1", 0})
+
+	attrs.Series = "trusty"
+	attrs.Version = "14.04"
+	s.assertMetadataRecorded(c, attrs, cloudimagemetadata.Metadata{attrs, 0, "1", 0})
+}
+
+func (s *cloudImageMetadataSuite) TestSaveCanonicalMetadata(c *gc.C) {
+	s.storage = cloudimagemetadata.NewStorageWithConfig(collectionName, s.access, cloudimagemetadata.StorageConfig{
+		DefaultSeries: "trusty",
+	})
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream: "stream",
+		Arch:   "arch",
+		Source: "test",
+		Region: "wonder",
+	}
+	noSeries := cloudimagemetadata.Metadata{attrs, 0, "1", 0}
+	attrs.Arch = "arm64"
+	attrs.Series = "xenial"
+	noVersion := cloudimagemetadata.Metadata{attrs, 0, "2", 0}
+	saved, err := s.storage.SaveCanonicalMetadata([]cloudimagemetadata.Metadata{noSeries, noVersion})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(saved, gc.HasLen, 2)
+
+	noSeries.Series = "trusty"
+	noSeries.Version = "14.04"
+	noVersion.Version = "16.04"
+	for i, expected := range []cloudimagemetadata.Metadata{noSeries, noVersion} {
+		// The creation date is generated when the metadata is saved.
+		c.Check(saved[i].DateCreated, gc.Not(gc.Equals), int64(0))
+		expected.DateCreated = saved[i].DateCreated
+		c.Check(saved[i], jc.DeepEquals, expected)
+	}
+	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{}, saved...)
+}
+
+func (s *cloudImageMetadataSuite) TestSaveCanonicalMetadataExisting(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Series:  "trusty",
+		Version: "14.04",
+		Arch:    "arch",
+		Source:  "test",
+		Region:  "wonder",
+	}
+	existing := cloudimagemetadata.Metadata{attrs, 0, "1", 1000}
+	s.assertRecordMetadata(c, existing)
+
+	// Saving the same metadata again keeps the date it was created.
+	saved, err := s.storage.SaveCanonicalMetadata([]cloudimagemetadata.Metadata{{attrs, 0, "1", 0}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(saved, jc.DeepEquals, []cloudimagemetadata.Metadata{existing})
+
+	// Updating the image id also keeps the date it was created.
+	saved, err = s.storage.SaveCanonicalMetadata([]cloudimagemetadata.Metadata{{attrs, 0, "2", 0}})
+	c.Assert(err, jc.ErrorIsNil)
+	updated := cloudimagemetadata.Metadata{attrs, 0, "2", 1000}
+	c.Assert(saved, jc.DeepEquals, []cloudimagemetadata.Metadata{updated})
+	s.assertMetadataRecorded(c, attrs, updated)
+}
+
+func (s *cloudImageMetadataSuite) TestSaveCanonicalMetadataReplacedDate(c *gc.C) {
+	s.storage = cloudimagemetadata.NewStorageWithConfig(collectionName, s.access, cloudimagemetadata.StorageConfig{
+		RejectSuperseded: true,
+	})
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Series:  "trusty",
+		Version: "14.04",
+		Arch:    "arch",
+		Source:  "test",
+		Region:  "wonder",
+	}
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "1", 1000})
+
+	// The replacement's date is returned, as it is what's stored.
+	replacement := cloudimagemetadata.Metadata{attrs, 0, "2", 2000}
+	saved, err := s.storage.SaveCanonicalMetadata([]cloudimagemetadata.Metadata{replacement})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(saved, jc.DeepEquals, []cloudimagemetadata.Metadata{replacement})
+	s.assertMetadataRecorded(c, attrs, replacement)
+}
+
+func (s *cloudImageMetadataSuite) TestSaveCanonicalMetadataInvalid(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream: "stream",
+		Arch:   "arch",
+		Source: "test",
+		Series: "trusty",
+	}
+	saved, err := s.storage.SaveCanonicalMetadata([]cloudimagemetadata.Metadata{{attrs, 0, "1", 0}})
+	c.Assert(err, gc.ErrorMatches, regexp.QuoteMeta(`missing region: metadata for image 1 not valid`))
+	c.Assert(saved, gc.IsNil)
+}
+
+func (s *cloudImageMetadataSuite) TestSaveMetadataNoSeriesOptionalSource(c *gc.C) {
+	s.storage = cloudimagemetadata.NewStorageWithConfig(collectionName, s.access, cloudimagemetadata.StorageConfig{
+		SeriesOptionalSources: []string{"custom"},
+	})
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream: "stream",
+		Arch:   "arch",
+		Source: "custom",
+		Region: "wonder",
+	}
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "1", 0})
+	s.assertMetadataRecorded(c, attrs, cloudimagemetadata.Metadata{attrs, 0, "1", 0})
+
+	// Other sources still require a series.
+	attrs.Source = "test"
+	err := s.storage.SaveMetadata([]cloudimagemetadata.Metadata{{attrs, 0, "2", 0}})
+	c.Assert(err, gc.ErrorMatches, regexp.QuoteMeta(`missing series: metadata for image 2 not valid`))
+}
+
+func (s *cloudImageMetadataSuite) TestSaveMetadataImageIdPattern(c *gc.C) {
+	s.storage = cloudimagemetadata.NewStorageWithConfig(collectionName, s.access, cloudimagemetadata.StorageConfig{
+		ImageIdPatterns: map[string]*regexp.Regexp{
+			"test": regexp.MustCompile(`^ami-[0-9a-f]+$`),
+		},
+	})
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream: "stream",
+		Series: "trusty",
+		Arch:   "arch",
+		Source: "test",
+		Region: "wonder",
+	}
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "ami-0a1b2c", 0})
+	s.assertMetadataRecorded(c, attrs, cloudimagemetadata.Metadata{attrs, 0, "ami-0a1b2c", 0})
+
+	// Other sources accept any image id.
+	attrs.Source = "custom"
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "anything", 0})
+}
+
+func (s *cloudImageMetadataSuite) TestSaveMetadataImageIdPatternMismatch(c *gc.C) {
+	s.storage = cloudimagemetadata.NewStorageWithConfig(collectionName, s.access, cloudimagemetadata.StorageConfig{
+		ImageIdPatterns: map[string]*regexp.Regexp{
+			"test": regexp.MustCompile(`^ami-[0-9a-f]+$`),
+		},
+	})
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream: "stream",
+		Series: "trusty",
+		Arch:   "arch",
+		Source: "test",
+		Region: "wonder",
+	}
+	err := s.storage.SaveMetadata([]cloudimagemetadata.Metadata{{attrs, 0, "amI-0a1b2c", 0}})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, regexp.QuoteMeta(`image id "amI-0a1b2c" for source "test" (expected to match "^ami-[0-9a-f]+$") not valid`))
+}
+
 func (s *cloudImageMetadataSuite) TestSaveMetadataUnsupportedSeriesPassed(c *gc.C) {
 	attrs := cloudimagemetadata.MetadataAttributes{
 		Stream: "stream",
@@ -594,6 +1023,56 @@ func (s *cloudImageMetadataSuite) TestDeleteSameMetadataConcurrently(c *gc.C) {
 	s.assertConcurrentDelete(c, imageId, imageId)
 }
 
+func (s *cloudImageMetadataSuite) TestDeleteMatchingMetadata(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Region:  "region",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "amd64",
+		Source:  "custom",
+	}
+	stale := cloudimagemetadata.Metadata{attrs, 0, "1", 0}
+	attrs.Arch = "arm64"
+	staleToo := cloudimagemetadata.Metadata{attrs, 0, "2", 0}
+	attrs.Region = "other-region"
+	kept := cloudimagemetadata.Metadata{attrs, 0, "3", 0}
+	s.assertRecordMetadata(c, stale, staleToo, kept)
+
+	err := s.storage.DeleteMatchingMetadata(cloudimagemetadata.MetadataFilter{Region: "region"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{}, kept)
+
+	err = s.storage.DeleteMatchingMetadata(cloudimagemetadata.MetadataFilter{Region: "region"})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, "cannot delete matching cloud image metadata: matching cloud image metadata not found")
+}
+
+func (s *cloudImageMetadataSuite) TestDeleteMatchingMetadataConcurrently(c *gc.C) {
+	s.addTestImageMetadata(c, "ok-to-delete")
+	s.addTestImageMetadata(c, "ok-to-delete-too")
+
+	deleteMetadata := func() {
+		s.assertDeleteMetadata(c, "ok-to-delete")
+	}
+	defer txntesting.SetBeforeHooks(c, s.access.runner, deleteMetadata).Check()
+	err := s.storage.DeleteMatchingMetadata(cloudimagemetadata.MetadataFilter{Region: "region-test"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertNoMetadata(c)
+}
+
+func (s *cloudImageMetadataSuite) TestDeleteMatchingMetadataDeletedConcurrently(c *gc.C) {
+	s.addTestImageMetadata(c, "ok-to-delete")
+
+	deleteMetadata := func() {
+		s.assertDeleteMetadata(c, "ok-to-delete")
+	}
+	defer txntesting.SetBeforeHooks(c, s.access.runner, deleteMetadata).Check()
+	err := s.storage.DeleteMatchingMetadata(cloudimagemetadata.MetadataFilter{Region: "region-test"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertNoMetadata(c)
+}
+
 func (s *cloudImageMetadataSuite) assertConcurrentDelete(c *gc.C, imageId0, imageId1 string) {
 	deleteMetadata := func() {
 		s.assertDeleteMetadata(c, imageId0)
@@ -64,15 +64,33 @@ type Storage interface {
 	// Non custom records will expire after a set time.
 	SaveMetadata([]Metadata) error
 
+	// SaveCanonicalMetadata behaves as SaveMetadata, and also returns the
+	// metadata in the form it is saved, with any deduced series and
+	// version and the creation date filled in.
+	SaveCanonicalMetadata([]Metadata) ([]Metadata, error)
+
 	// DeleteMetadata deletes cloud image metadata from state.
 	DeleteMetadata(imageId string) error
 
+	// DeleteMatchingMetadata deletes all cloud image metadata that
+	// matches specified criteria from state, or returns a "not found"
+	// error if none match. Empty criteria will delete all cloud image
+	// metadata.
+	DeleteMatchingMetadata(criteria MetadataFilter) error
+
 	// FindMetadata returns all Metadata that match specified
 	// criteria or a "not found" error if none match.
 	// Empty criteria will return all cloud image metadata.
-	// Returned result is grouped by source type and ordered by date created.
+	// Returned result is grouped by source type and ordered by descending
+	// priority, then by date created unless the criteria specify another
+	// order.
 	FindMetadata(criteria MetadataFilter) (map[string][]Metadata, error)
 
+	// MetadataCount returns the number of Metadata that match specified
+	// criteria, which is 0 if none match. The criteria's preferred source
+	// and order are not used.
+	MetadataCount(criteria MetadataFilter) (int, error)
+
 	// SupportedArchitectures returns collection of unique architectures
 	// that stored metadata contains.
 	SupportedArchitectures(criteria MetadataFilter) ([]string, error)
@@ -4,6 +4,7 @@
 package caasapplicationprovisioner
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -252,7 +253,7 @@ func (a *appWorker) updateState(app caas.Application, force bool, lastReportedSt
 	} else if err != nil {
 		return nil, errors.Trace(err)
 	}
-	st, err := app.State()
+	st, err := app.State(context.Background())
 	if errors.IsNotFound(err) {
 		// Do nothing
 	} else if err != nil {
@@ -268,8 +269,12 @@ func (a *appWorker) updateState(app caas.Application, force bool, lastReportedSt
 		return nil, nil
 	}
 	// TODO: consolidate GarbageCollect and UpdateApplicationUnits into a single call.
-	units, err := app.Units()
-	if err != nil {
+	units, err := app.Units(context.Background())
+	if caas.IsUnitsError(err) {
+		// The units are still updated, with any whose status
+		// couldn't be computed reported as unknown.
+		a.logger.Warningf("application %q: %v", a.name, err)
+	} else if err != nil {
 		return nil, errors.Trace(err)
 	}
 
@@ -395,7 +400,7 @@ func (a *appWorker) alive(app caas.Application) error {
 		return errors.Annotatef(err, "failed to get application charm deployment metadata for %q", a.name)
 	}
 
-	appState, err := app.Exists()
+	appState, err := app.Exists(context.Background())
 	if err != nil {
 		return errors.Annotatef(err, "failed get application state for %q", a.name)
 	}
@@ -463,7 +468,7 @@ func (a *appWorker) alive(app caas.Application) error {
 	reason := "unchanged"
 	// TODO(embedded): implement Equals method for caas.ApplicationConfig
 	if !reflect.DeepEqual(config, a.lastApplied) {
-		err = app.Ensure(config)
+		err = app.Ensure(context.Background(), config)
 		if err != nil {
 			return errors.Annotate(err, "ensuring application")
 		}
@@ -484,7 +489,11 @@ func (a *appWorker) alive(app caas.Application) error {
 
 func (a *appWorker) dying(app caas.Application) error {
 	a.logger.Debugf("application %q dying", a.name)
-	err := app.Delete()
+	// The application's storage is left in place. Storage destroyed along
+	// with the application (remove-application --destroy-storage) is
+	// removed by the storage provisioner as its storage instances are
+	// destroyed.
+	err := app.Delete(context.Background(), caas.DeleteOptions{})
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -511,7 +520,7 @@ func (a *appWorker) dead(app caas.Application) error {
 func (a *appWorker) waitForTerminated(app caas.Application) error {
 	tryAgain := errors.New("try again")
 	existsFunc := func() error {
-		appState, err := app.Exists()
+		appState, err := app.Exists(context.Background())
 		if err != nil {
 			return errors.Trace(err)
 		}
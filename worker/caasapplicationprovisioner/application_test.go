@@ -4,6 +4,7 @@
 package caasapplicationprovisioner_test
 
 import (
+	"context"
 	"time"
 
 	"github.com/golang/mock/gomock"
@@ -137,13 +138,13 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 		facade.EXPECT().CharmInfo("cs:test").DoAndReturn(func(string) (*charmscommon.CharmInfo, error) {
 			return appCharmInfo, nil
 		}),
-		brokerApp.EXPECT().Exists().DoAndReturn(func() (caas.DeploymentState, error) {
+		brokerApp.EXPECT().Exists(gomock.Any()).DoAndReturn(func(context.Context) (caas.DeploymentState, error) {
 			return caas.DeploymentState{}, nil
 		}),
 		facade.EXPECT().ApplicationOCIResources("test").DoAndReturn(func(string) (map[string]resources.DockerImageDetails, error) {
 			return ociResources, nil
 		}),
-		brokerApp.EXPECT().Ensure(gomock.Any()).DoAndReturn(func(config caas.ApplicationConfig) error {
+		brokerApp.EXPECT().Ensure(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, config caas.ApplicationConfig) error {
 			mc := jc.NewMultiChecker()
 			mc.AddExpr(`_.IntroductionSecret`, gc.HasLen, 24)
 			mc.AddExpr(`_.Charm`, gc.NotNil)
@@ -175,7 +176,7 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 				names.NewUnitTag("test/0"),
 			}, nil
 		}),
-		brokerApp.EXPECT().State().DoAndReturn(func() (caas.ApplicationState, error) {
+		brokerApp.EXPECT().State(gomock.Any()).DoAndReturn(func(context.Context) (caas.ApplicationState, error) {
 			return caas.ApplicationState{
 				DesiredReplicas: 1,
 				Replicas:        []string{"test-0"},
@@ -184,7 +185,7 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 		facade.EXPECT().GarbageCollect("test", []names.Tag{names.NewUnitTag("test/0")}, 1, []string{"test-0"}, false).DoAndReturn(func(appName string, observedUnits []names.Tag, desiredReplicas int, activePodNames []string, force bool) error {
 			return nil
 		}),
-		brokerApp.EXPECT().Units().Return([]caas.Unit{{
+		brokerApp.EXPECT().Units(gomock.Any()).Return([]caas.Unit{{
 			Id:      "test-0",
 			Address: "10.10.10.1",
 			Dying:   false,
@@ -251,7 +252,7 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 		facade.EXPECT().CharmInfo("cs:test").DoAndReturn(func(string) (*charmscommon.CharmInfo, error) {
 			return appCharmInfo, nil
 		}),
-		brokerApp.EXPECT().Exists().DoAndReturn(func() (caas.DeploymentState, error) {
+		brokerApp.EXPECT().Exists(gomock.Any()).DoAndReturn(func(context.Context) (caas.DeploymentState, error) {
 			return caas.DeploymentState{}, nil
 		}),
 		facade.EXPECT().ApplicationOCIResources("test").DoAndReturn(func(string) (map[string]resources.DockerImageDetails, error) {
@@ -267,7 +268,7 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 				names.NewUnitTag("test/0"),
 			}, nil
 		}),
-		brokerApp.EXPECT().State().DoAndReturn(func() (caas.ApplicationState, error) {
+		brokerApp.EXPECT().State(gomock.Any()).DoAndReturn(func(context.Context) (caas.ApplicationState, error) {
 			return caas.ApplicationState{
 				DesiredReplicas: 0,
 				Replicas:        []string(nil),
@@ -278,7 +279,7 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 			return nil
 		}),
 
-		brokerApp.EXPECT().Units().Return([]caas.Unit{{
+		brokerApp.EXPECT().Units(gomock.Any()).Return([]caas.Unit{{
 			Id:    "test-0",
 			Dying: true,
 			Status: status.StatusInfo{
@@ -294,7 +295,7 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 		facade.EXPECT().Life("test").DoAndReturn(func(string) (life.Value, error) {
 			return life.Dying, nil
 		}),
-		brokerApp.EXPECT().Delete().DoAndReturn(func() error {
+		brokerApp.EXPECT().Delete(gomock.Any(), caas.DeleteOptions{}).DoAndReturn(func(context.Context, caas.DeleteOptions) error {
 			notifyReady <- struct{}{}
 			return nil
 		}),
@@ -303,10 +304,10 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 		facade.EXPECT().Life("test").DoAndReturn(func(string) (life.Value, error) {
 			return life.Dead, nil
 		}),
-		brokerApp.EXPECT().Delete().DoAndReturn(func() error {
+		brokerApp.EXPECT().Delete(gomock.Any(), caas.DeleteOptions{}).DoAndReturn(func(context.Context, caas.DeleteOptions) error {
 			return nil
 		}),
-		brokerApp.EXPECT().Exists().DoAndReturn(func() (caas.DeploymentState, error) {
+		brokerApp.EXPECT().Exists(gomock.Any()).DoAndReturn(func(context.Context) (caas.DeploymentState, error) {
 			return caas.DeploymentState{
 				Exists:      false,
 				Terminating: false,
@@ -315,7 +316,7 @@ func (s *ApplicationWorkerSuite) TestWorker(c *gc.C) {
 		facade.EXPECT().Units("test").DoAndReturn(func(string) ([]names.Tag, error) {
 			return []names.Tag(nil), nil
 		}),
-		brokerApp.EXPECT().State().DoAndReturn(func() (caas.ApplicationState, error) {
+		brokerApp.EXPECT().State(gomock.Any()).DoAndReturn(func(context.Context) (caas.ApplicationState, error) {
 			return caas.ApplicationState{
 				DesiredReplicas: 0,
 				Replicas:        []string(nil),
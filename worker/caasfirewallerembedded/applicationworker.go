@@ -4,6 +4,7 @@
 package caasfirewallerembedded
 
 import (
+	"context"
 	"reflect"
 	"strings"
 
@@ -200,7 +201,7 @@ func (w *applicationWorker) onPortChanged() (err error) {
 			return nil
 		}
 		w.currentPorts = changedPortRanges
-		return w.portMutator.UpdatePorts(w.currentPorts.toServicePorts(), false)
+		return w.portMutator.UpdatePorts(context.Background(), w.currentPorts.toServicePorts(), false)
 	*/
 	return nil
 }
@@ -4,6 +4,8 @@
 package caasfirewallerembedded
 
 import (
+	"context"
+
 	"github.com/juju/juju/caas"
 )
 
@@ -16,10 +18,10 @@ type CAASBroker interface {
 
 // PortMutator exposes CAAS application functionality to a worker.
 type PortMutator interface {
-	UpdatePorts(ports []caas.ServicePort, updateContainerPorts bool) error
+	UpdatePorts(ctx context.Context, ports []caas.ServicePort, updateContainerPorts bool) error
 }
 
 // ServiceUpdater exposes CAAS application functionality to a worker.
 type ServiceUpdater interface {
-	UpdateService(caas.ServiceParam) error
+	UpdateService(ctx context.Context, param caas.ServiceParam) error
 }
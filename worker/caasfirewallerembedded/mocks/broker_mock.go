@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	caas "github.com/juju/juju/caas"
 	reflect "reflect"
@@ -71,17 +72,17 @@ func (m *MockPortMutator) EXPECT() *MockPortMutatorMockRecorder {
 }
 
 // UpdatePorts mocks base method
-func (m *MockPortMutator) UpdatePorts(arg0 []caas.ServicePort, arg1 bool) error {
+func (m *MockPortMutator) UpdatePorts(arg0 context.Context, arg1 []caas.ServicePort, arg2 bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePorts", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdatePorts", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdatePorts indicates an expected call of UpdatePorts
-func (mr *MockPortMutatorMockRecorder) UpdatePorts(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockPortMutatorMockRecorder) UpdatePorts(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePorts", reflect.TypeOf((*MockPortMutator)(nil).UpdatePorts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePorts", reflect.TypeOf((*MockPortMutator)(nil).UpdatePorts), arg0, arg1, arg2)
 }
 
 // MockServiceUpdater is a mock of ServiceUpdater interface
@@ -108,15 +109,15 @@ func (m *MockServiceUpdater) EXPECT() *MockServiceUpdaterMockRecorder {
 }
 
 // UpdateService mocks base method
-func (m *MockServiceUpdater) UpdateService(arg0 caas.ServiceParam) error {
+func (m *MockServiceUpdater) UpdateService(arg0 context.Context, arg1 caas.ServiceParam) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateService", arg0)
+	ret := m.ctrl.Call(m, "UpdateService", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateService indicates an expected call of UpdateService
-func (mr *MockServiceUpdaterMockRecorder) UpdateService(arg0 interface{}) *gomock.Call {
+func (mr *MockServiceUpdaterMockRecorder) UpdateService(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateService", reflect.TypeOf((*MockServiceUpdater)(nil).UpdateService), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateService", reflect.TypeOf((*MockServiceUpdater)(nil).UpdateService), arg0, arg1)
 }